@@ -0,0 +1,292 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// method groups of the access control
+// The method that is not in the groups is the admin method
+const (
+	GroupChain   = "chain"
+	GroupAccount = "account"
+	GroupTx      = "tx"
+	GroupAdmin   = "admin"
+)
+
+// GroupAll is used to allow every group
+const GroupAll = "*"
+
+// GroupNone is used to allow no group without the credential
+const GroupNone = "none"
+
+// DefaultPublicGroups are the groups allowed without the credential when the public groups are not given
+// SendTransaction is not public by default, so it should be given to APIPublicGroups or the credential
+var DefaultPublicGroups = []string{GroupChain, GroupAccount}
+
+// minimum lengths of the credentials
+const (
+	MinAPIKeyLength    = 16
+	MinJWTSecretLength = 32
+)
+
+var groupMap = map[string]bool{
+	GroupChain:   true,
+	GroupAccount: true,
+	GroupTx:      true,
+	GroupAdmin:   true,
+}
+
+var methodGroups = map[string]string{
+	"Version":                      GroupChain,
+	"Height":                       GroupChain,
+	"LastHash":                     GroupChain,
+	"Hash":                         GroupChain,
+	"Header":                       GroupChain,
+	"Block":                        GroupChain,
+	"LastBlock":                    GroupChain,
+	"BlockByHash":                  GroupChain,
+	"HeaderByHash":                 GroupChain,
+	"Blocks":                       GroupChain,
+	"Headers":                      GroupChain,
+	"TxFeeTable":                   GroupChain,
+	"ConsensusPolicy":              GroupChain,
+	"RewardSchedule":               GroupChain,
+	"Transaction":                  GroupChain,
+	"TransactionReceipt":           GroupChain,
+	"subscribeNewHeads":            GroupChain,
+	"subscribePendingTransactions": GroupChain,
+	"subscribeEvents":              GroupChain,
+	"Account":                      GroupAccount,
+	"Balance":                      GroupAccount,
+	"Seq":                          GroupAccount,
+	"AccountByName":                GroupAccount,
+	"IsExistAccount":               GroupAccount,
+	"AddressHistory":               GroupAccount,
+	"subscribeAccount":             GroupAccount,
+	"SendTransaction":              GroupTx,
+}
+
+// MethodGroup returns the group of the method
+func MethodGroup(Method string) string {
+	if g, has := methodGroups[Method]; has {
+		return g
+	}
+	return GroupAdmin
+}
+
+// ParseGroups returns the groups of the names, and * is every group
+func ParseGroups(names []string) (map[string]bool, error) {
+	groups := map[string]bool{}
+	for _, name := range names {
+		if name == GroupAll {
+			for g := range groupMap {
+				groups[g] = true
+			}
+		} else if !groupMap[name] {
+			return nil, ErrUnknownGroup
+		} else {
+			groups[name] = true
+		}
+	}
+	return groups, nil
+}
+
+// Grant is the groups allowed to a request and the client of the request
+// The client is the credential when it is given and the remote ip when it is not given
+type Grant struct {
+	Groups map[string]bool
+	Client string
+	IsKey  bool
+}
+
+// Allow returns true when the method is allowed
+func (gr *Grant) Allow(Method string) bool {
+	return gr.Groups[MethodGroup(Method)]
+}
+
+// Access is the access control of the api
+// The api key is kept as the hash of it and the jwt is signed by HS256 with the secret
+type Access struct {
+	public map[string]bool
+	keys   map[[sha256.Size]byte]map[string]bool
+	secret []byte
+}
+
+// NewAccess returns an Access
+// The groups of the api key are given as the space separated names
+func NewAccess(PublicGroups []string, Keys map[string]string, JWTSecret string) (*Access, error) {
+	ac := &Access{
+		keys: map[[sha256.Size]byte]map[string]bool{},
+	}
+	if len(PublicGroups) == 0 {
+		PublicGroups = DefaultPublicGroups
+	} else if len(PublicGroups) == 1 && PublicGroups[0] == GroupNone {
+		PublicGroups = nil
+	}
+	public, err := ParseGroups(PublicGroups)
+	if err != nil {
+		return nil, err
+	}
+	ac.public = public
+	for k, v := range Keys {
+		if len(k) < MinAPIKeyLength {
+			return nil, ErrShortAPIKey
+		}
+		groups, err := ParseGroups(strings.Fields(v))
+		if err != nil {
+			return nil, err
+		}
+		ac.keys[sha256.Sum256([]byte(k))] = groups
+	}
+	if len(JWTSecret) > 0 {
+		if len(JWTSecret) < MinJWTSecretLength {
+			return nil, ErrShortJWTSecret
+		}
+		ac.secret = []byte(JWTSecret)
+	}
+	return ac, nil
+}
+
+// Authorize returns the groups allowed to the request
+// The credential is given by the X-API-Key header or the Authorization bearer header,
+// and the token query parameter is only used by the websocket upgrade of the browser because the query is kept by the logs of the proxies
+func (ac *Access) Authorize(r *http.Request) (*Grant, error) {
+	gr := &Grant{
+		Groups: map[string]bool{},
+		Client: remoteIP(r),
+	}
+	for g := range ac.public {
+		gr.Groups[g] = true
+	}
+	cred := r.Header.Get("X-API-Key")
+	if len(cred) == 0 {
+		if auth := r.Header.Get("Authorization"); len(auth) > 0 {
+			if !strings.HasPrefix(auth, "Bearer ") {
+				return nil, ErrInvalidCredential
+			}
+			cred = strings.TrimSpace(auth[len("Bearer "):])
+		}
+	}
+	if len(cred) == 0 && r.URL.Path == WebsocketPath && websocket.IsWebSocketUpgrade(r) {
+		cred = r.URL.Query().Get("token")
+	}
+	if len(cred) == 0 {
+		return gr, nil
+	}
+
+	var groups map[string]bool
+	KeyHash := sha256.Sum256([]byte(cred))
+	if strings.Count(cred, ".") == 2 && ac.secret != nil {
+		g, Subject, err := ac.verifyJWT(cred, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		groups = g
+		gr.Client = "jwt:" + Subject
+	} else if g, has := ac.keys[KeyHash]; has {
+		groups = g
+		gr.Client = "key:" + hex.EncodeToString(KeyHash[:8])
+	} else {
+		return nil, ErrInvalidCredential
+	}
+	gr.IsKey = true
+	for g := range groups {
+		gr.Groups[g] = true
+	}
+	return gr, nil
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+}
+
+type jwtClaims struct {
+	Subject   string   `json:"sub"`
+	Groups    []string `json:"groups"`
+	ExpiresAt int64    `json:"exp"`
+	NotBefore int64    `json:"nbf"`
+}
+
+// verifyJWT verifies the HS256 token and returns the groups of the groups claim and the subject
+// The token without the subject is identified by the hash of it
+func (ac *Access) verifyJWT(token string, now time.Time) (map[string]bool, string, error) {
+	parts := strings.Split(token, ".")
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, "", ErrInvalidCredential
+	}
+	mac := hmac.New(sha256.New, ac.secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return nil, "", ErrInvalidCredential
+	}
+
+	var header jwtHeader
+	if err := decodeJWTPart(parts[0], &header); err != nil || header.Alg != "HS256" {
+		return nil, "", ErrInvalidCredential
+	}
+	var claims jwtClaims
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return nil, "", ErrInvalidCredential
+	}
+	if claims.ExpiresAt != 0 && now.Unix() >= claims.ExpiresAt {
+		return nil, "", ErrExpiredCredential
+	}
+	if claims.NotBefore != 0 && now.Unix() < claims.NotBefore {
+		return nil, "", ErrInvalidCredential
+	}
+	groups, err := ParseGroups(claims.Groups)
+	if err != nil {
+		return nil, "", ErrInvalidCredential
+	}
+	Subject := claims.Subject
+	if len(Subject) == 0 {
+		TokenHash := sha256.Sum256([]byte(token))
+		Subject = hex.EncodeToString(TokenHash[:8])
+	}
+	return groups, Subject, nil
+}
+
+// remoteIP returns the ip of the remote address of the request
+// The forwarded headers are not used because they are given by the client
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return "ip:" + r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+func decodeJWTPart(part string, v interface{}) error {
+	bs, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(bs, v)
+}
+
+// SetAccess sets the access control of the api
+func (rm *Manager) SetAccess(ac *Access) {
+	rm.Lock()
+	defer rm.Unlock()
+
+	rm.access = ac
+}
+
+// authorize returns the groups allowed to the request by the access control
+func (rm *Manager) authorize(r *http.Request) (*Grant, error) {
+	rm.Lock()
+	ac := rm.access
+	rm.Unlock()
+	return ac.Authorize(r)
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const testAPIKey = "0123456789abcdef"
+
+func TestAccessDefaultPublicGroups(t *testing.T) {
+	ac, err := NewAccess(nil, nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gr, err := ac.Authorize(httptest.NewRequest(http.MethodPost, "/api/endpoints/http", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !gr.Allow("Height") || !gr.Allow("Balance") {
+		t.Error("the chain and the account methods are not public by default")
+	}
+	if gr.Allow("SendTransaction") {
+		t.Error("SendTransaction is public by default")
+	}
+	if gr.Allow("SetLogLevel") {
+		t.Error("the admin method is public by default")
+	}
+}
+
+func TestAccessKey(t *testing.T) {
+	ac, err := NewAccess([]string{GroupNone}, map[string]string{testAPIKey: "tx"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/endpoints/http", nil)
+	req.Header.Set("X-API-Key", testAPIKey)
+	gr, err := ac.Authorize(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !gr.IsKey || !gr.Allow("SendTransaction") || gr.Allow("Height") {
+		t.Errorf("the groups of the key are %v", gr.Groups)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/endpoints/http", nil)
+	req.Header.Set("Authorization", "Bearer "+testAPIKey+"x")
+	if _, err := ac.Authorize(req); err != ErrInvalidCredential {
+		t.Errorf("the unknown key returns %v, expected %v", err, ErrInvalidCredential)
+	}
+
+	if _, err := NewAccess(nil, map[string]string{"short": "*"}, ""); err != ErrShortAPIKey {
+		t.Errorf("the short key returns %v, expected %v", err, ErrShortAPIKey)
+	}
+}
+
+func TestAccessTokenQuery(t *testing.T) {
+	ac, err := NewAccess([]string{GroupNone}, map[string]string{testAPIKey: "*"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, WebsocketPath+"?token="+testAPIKey, nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	gr, err := ac.Authorize(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !gr.IsKey {
+		t.Error("the token of the websocket upgrade is not used")
+	}
+
+	for _, target := range []string{
+		WebsocketPath + "?token=" + testAPIKey,
+		"/api/endpoints/http?token=" + testAPIKey,
+		RESTPrefix + "/height?token=" + testAPIKey,
+	} {
+		gr, err := ac.Authorize(httptest.NewRequest(http.MethodGet, target, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gr.IsKey || len(gr.Groups) > 0 {
+			t.Errorf("the token of %s is used", target)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"github.com/fletaio/common"
+	"github.com/fletaio/core/account"
+	"github.com/fletaio/core/amount"
+	"github.com/fletaio/core/consensus"
+	"github.com/fletaio/core/data"
+	"github.com/fletaio/extension/account_def"
+	"github.com/fletaio/solidity"
+)
+
+// AccountBase is the common fields of the account views
+type AccountBase struct {
+	Type    string         `json:"type"`
+	Address common.Address `json:"address"`
+	Name    string         `json:"name"`
+	Balance *amount.Amount `json:"balance"`
+	Seq     uint64         `json:"seq"`
+}
+
+// SingleAccount is the view of a fleta.SingleAccount
+type SingleAccount struct {
+	AccountBase
+	KeyHash common.PublicHash `json:"key_hash"`
+}
+
+// MultiSigAccount is the view of a fleta.MultiSigAccount
+type MultiSigAccount struct {
+	AccountBase
+	Required  uint8               `json:"required"`
+	KeyHashes []common.PublicHash `json:"key_hashes"`
+}
+
+// LockedAccount is the view of a fleta.LockedAccount
+type LockedAccount struct {
+	AccountBase
+	UnlockHeight uint32            `json:"unlock_height"`
+	KeyHash      common.PublicHash `json:"key_hash"`
+}
+
+// FormulationAccount is the view of a consensus.FormulationAccount
+// The policy is only given to the hyper formulator
+type FormulationAccount struct {
+	AccountBase
+	FormulationType string                 `json:"formulation_type"`
+	KeyHash         common.PublicHash      `json:"key_hash"`
+	Amount          *amount.Amount         `json:"amount"`
+	Policy          *consensus.HyperPolicy `json:"policy,omitempty"`
+	StakingAmount   *amount.Amount         `json:"staking_amount"`
+}
+
+// ContractAccount is the view of a solidity.ContractAccount
+type ContractAccount struct {
+	AccountBase
+}
+
+// formulation type names
+var formulationTypeNames = map[consensus.FormulationType]string{
+	consensus.AlphaFormulatorType: "alpha",
+	consensus.SigmaFormulatorType: "sigma",
+	consensus.OmegaFormulatorType: "omega",
+	consensus.HyperFormulatorType: "hyper",
+}
+
+// NewAccountView returns the typed view of the account
+func NewAccountView(loader data.Loader, acc account.Account) (interface{}, error) {
+	name, err := loader.Accounter().NameByType(acc.Type())
+	if err != nil {
+		return nil, err
+	}
+	base := AccountBase{
+		Type:    name,
+		Address: acc.Address(),
+		Name:    acc.Name(),
+		Balance: acc.Balance(),
+		Seq:     loader.Seq(acc.Address()),
+	}
+	switch acc := acc.(type) {
+	case *account_def.SingleAccount:
+		return &SingleAccount{
+			AccountBase: base,
+			KeyHash:     acc.KeyHash,
+		}, nil
+	case *account_def.MultiSigAccount:
+		return &MultiSigAccount{
+			AccountBase: base,
+			Required:    acc.Required,
+			KeyHashes:   acc.KeyHashes,
+		}, nil
+	case *account_def.LockedAccount:
+		return &LockedAccount{
+			AccountBase:  base,
+			UnlockHeight: acc.UnlockHeight,
+			KeyHash:      acc.KeyHash,
+		}, nil
+	case *consensus.FormulationAccount:
+		view := &FormulationAccount{
+			AccountBase:     base,
+			FormulationType: formulationTypeNames[acc.FormulationType],
+			KeyHash:         acc.KeyHash,
+			Amount:          acc.Amount,
+			StakingAmount:   acc.StakingAmount,
+		}
+		if acc.FormulationType == consensus.HyperFormulatorType {
+			view.Policy = acc.Policy
+		}
+		return view, nil
+	case *solidity.ContractAccount:
+		return &ContractAccount{
+			AccountBase: base,
+		}, nil
+	default:
+		return nil, ErrUnknownAccountType
+	}
+}
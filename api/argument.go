@@ -0,0 +1,42 @@
+package api
+
+import (
+	"github.com/fletaio/common"
+	"github.com/fletaio/common/hash"
+	"github.com/fletaio/framework/rpc"
+)
+
+// Address returns the address value of the index
+func Address(arg *rpc.Argument, index int) (common.Address, error) {
+	str, err := arg.String(index)
+	if err != nil {
+		return common.Address{}, err
+	}
+	addr, err := common.ParseAddress(str)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return addr, nil
+}
+
+// Hash returns the hash value of the index
+func Hash(arg *rpc.Argument, index int) (hash.Hash256, error) {
+	str, err := arg.String(index)
+	if err != nil {
+		return hash.Hash256{}, err
+	}
+	h, err := hash.ParseHash(str)
+	if err != nil {
+		return hash.Hash256{}, err
+	}
+	return h, nil
+}
+
+// OptionalString returns the string value of the index, and it returns the empty string when the value is null
+func OptionalString(arg *rpc.Argument, index int) (string, error) {
+	str, err := arg.String(index)
+	if err == rpc.ErrInvalidArgumentType {
+		return "", nil
+	}
+	return str, err
+}
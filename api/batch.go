@@ -0,0 +1,240 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync/atomic"
+	"time"
+
+	"github.com/fletaio/core/kernel"
+	"github.com/fletaio/framework/rpc"
+)
+
+// default limits of the requests
+const (
+	DefaultMaxBatchSize = 100
+	DefaultMaxBodySize  = 1 << 20
+	DefaultCallTimeout  = 10 * time.Second
+	// DefaultMaxRunningCalls is the maximum number of the running calls of a client including the timed out calls that are not finished yet
+	DefaultMaxRunningCalls = 8
+)
+
+// Limits are the limits of the requests
+// The zero value of the field is replaced by the default value
+type Limits struct {
+	MaxBatchSize    int
+	MaxBodySize     int64
+	CallTimeout     time.Duration
+	MaxRunningCalls int
+}
+
+// SetLimits sets the limits of the requests
+func (rm *Manager) SetLimits(l Limits) {
+	if l.MaxBatchSize <= 0 {
+		l.MaxBatchSize = DefaultMaxBatchSize
+	}
+	if l.MaxBodySize <= 0 {
+		l.MaxBodySize = DefaultMaxBodySize
+	}
+	if l.CallTimeout <= 0 {
+		l.CallTimeout = DefaultCallTimeout
+	}
+	if l.MaxRunningCalls <= 0 {
+		l.MaxRunningCalls = DefaultMaxRunningCalls
+	}
+
+	rm.Lock()
+	defer rm.Unlock()
+
+	rm.limits = l
+}
+
+func (rm *Manager) currentLimits() Limits {
+	rm.Lock()
+	defer rm.Unlock()
+
+	return rm.limits
+}
+
+// isBatch returns true when the body is a json array
+func isBatch(body []byte) bool {
+	body = bytes.TrimLeft(body, " \t\r\n")
+	return len(body) > 0 && body[0] == '['
+}
+
+// handleBody serves a request or a batch of the requests of the body
+// The websocket client is nil for the http endpoint, and nil is returned when every request is a notification
+func (rm *Manager) handleBody(kn *kernel.Kernel, cl *wsClient, grant *Grant, body []byte) (interface{}, error) {
+	if !json.Valid(body) {
+		return nil, &JRPCError{Code: CodeParseError, Message: ErrParseError.Error()}
+	}
+	if !isBatch(body) {
+		var req JRPCRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			return nil, ErrInvalidRequest
+		}
+		if res := rm.handleRequest(kn, cl, grant, &req); res != nil {
+			return res, nil
+		}
+		return nil, nil
+	}
+
+	var batch []json.RawMessage
+	if err := json.Unmarshal(body, &batch); err != nil {
+		return nil, ErrInvalidRequest
+	}
+	if len(batch) == 0 {
+		return nil, ErrEmptyBatch
+	}
+	if len(batch) > rm.currentLimits().MaxBatchSize {
+		return nil, ErrTooLargeBatch
+	}
+	list := make([]*JRPCResponse, 0, len(batch))
+	for _, v := range batch {
+		var req JRPCRequest
+		if err := json.Unmarshal(v, &req); err != nil {
+			list = append(list, &JRPCResponse{
+				JSONRPC: "2.0",
+				Error:   toJRPCError(ErrInvalidRequest),
+			})
+			continue
+		}
+		if res := rm.handleRequest(kn, cl, grant, &req); res != nil {
+			list = append(list, res)
+		}
+	}
+	if len(list) == 0 {
+		return nil, nil
+	}
+	return list, nil
+}
+
+// handleRequest serves the subscription methods of the websocket client and the registered methods
+// The method that is not allowed by the grant is denied and the method is limited by the rate limiter,
+// but unsubscribe is always allowed
+func (rm *Manager) handleRequest(kn *kernel.Kernel, cl *wsClient, grant *Grant, req *JRPCRequest) *JRPCResponse {
+	if len(req.Method) == 0 {
+		if req.ID == nil {
+			return nil
+		}
+		return &JRPCResponse{
+			JSONRPC: req.JSONRPC,
+			ID:      req.ID,
+			Error:   toJRPCError(ErrInvalidRequest),
+		}
+	}
+	if req.Method != "unsubscribe" && !grant.Allow(req.Method) {
+		if req.ID == nil {
+			return nil
+		}
+		return &JRPCResponse{
+			JSONRPC: req.JSONRPC,
+			ID:      req.ID,
+			Error:   toJRPCError(ErrPermissionDenied),
+		}
+	}
+	if req.Method != "unsubscribe" {
+		if re := rm.takeRate(grant, req.Method); re != nil {
+			if req.ID == nil {
+				return nil
+			}
+			return &JRPCResponse{
+				JSONRPC: req.JSONRPC,
+				ID:      req.ID,
+				Error:   toJRPCError(re),
+			}
+		}
+	}
+	if cl != nil {
+		if res, is := rm.handleSubscription(cl, req); is {
+			return res
+		}
+	}
+	return rm.handleJRPC(kn, grant.Client, req)
+}
+
+type callResult struct {
+	ret interface{}
+	err error
+}
+
+// states of the call
+const (
+	callWaiting int32 = iota
+	callRunning
+	callDone
+	callAbandoned
+)
+
+// call calls the handler of the method and waits for the result until the call timeout
+// The handler cannot be stopped, so the timed out call skips the handler when it is not started yet,
+// and the running calls of the client are bounded until the handlers are finished to not pile up the calls of a client behind the lock of the kernel
+func (rm *Manager) call(kn *kernel.Kernel, Client string, Method string, h *handler, ID interface{}, args []*string) (ret interface{}, err error) {
+	start := time.Now()
+	defer func() {
+		d := time.Since(start)
+		rm.observeCall(Method, d, err)
+		logCall(Method, d, err)
+	}()
+
+	l := rm.currentLimits()
+	if !rm.acquireCall(Client, l.MaxRunningCalls) {
+		return nil, ErrTooManyRunningCalls
+	}
+
+	var state int32
+	resCh := make(chan *callResult, 1)
+	go func() {
+		defer rm.releaseCall(Client)
+
+		if !h.unlocked {
+			kn.Lock()
+			defer kn.Unlock()
+		}
+		if !atomic.CompareAndSwapInt32(&state, callWaiting, callRunning) {
+			return
+		}
+		var r callResult
+		r.ret, r.err = h.fn(kn, ID, rpc.NewArgument(args))
+		if !atomic.CompareAndSwapInt32(&state, callRunning, callDone) {
+			return
+		}
+		resCh <- &r
+	}()
+	timer := time.NewTimer(l.CallTimeout)
+	defer timer.Stop()
+	select {
+	case r := <-resCh:
+		return r.ret, r.err
+	case <-timer.C:
+		if atomic.CompareAndSwapInt32(&state, callWaiting, callAbandoned) || atomic.CompareAndSwapInt32(&state, callRunning, callAbandoned) {
+			return nil, ErrCallTimeout
+		}
+		r := <-resCh
+		return r.ret, r.err
+	}
+}
+
+// acquireCall takes a slot of the running calls of the client
+func (rm *Manager) acquireCall(Client string, MaxRunningCalls int) bool {
+	rm.Lock()
+	defer rm.Unlock()
+
+	if rm.runningCalls[Client] >= MaxRunningCalls {
+		return false
+	}
+	rm.runningCalls[Client]++
+	return true
+}
+
+// releaseCall returns the slot of the running calls of the client when the handler is finished or skipped
+func (rm *Manager) releaseCall(Client string) {
+	rm.Lock()
+	defer rm.Unlock()
+
+	if rm.runningCalls[Client] <= 1 {
+		delete(rm.runningCalls, Client)
+	} else {
+		rm.runningCalls[Client]--
+	}
+}
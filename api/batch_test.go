@@ -0,0 +1,153 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/fletaio/core/kernel"
+	"github.com/fletaio/framework/rpc"
+)
+
+func newTestManager() *Manager {
+	rm := NewManager()
+	rm.AddUnlocked("Height", func(kn *kernel.Kernel, ID interface{}, arg *rpc.Argument) (interface{}, error) {
+		return 10, nil
+	})
+	rm.AddUnlocked("Balance", func(kn *kernel.Kernel, ID interface{}, arg *rpc.Argument) (interface{}, error) {
+		if _, err := Address(arg, 0); err != nil {
+			return nil, err
+		}
+		return "0", nil
+	})
+	return rm
+}
+
+func adminGrant() *Grant {
+	return &Grant{Groups: map[string]bool{GroupChain: true, GroupAccount: true, GroupTx: true, GroupAdmin: true}}
+}
+
+func TestHandleBodyErrorCode(t *testing.T) {
+	rm := newTestManager()
+	grant := &Grant{Groups: map[string]bool{GroupChain: true}}
+
+	if _, err := rm.handleBody(nil, nil, grant, []byte(`{"jsonrpc":"2.0",`)); toJRPCError(err).Code != CodeParseError {
+		t.Errorf("the invalid json returns %v, expected the parse error", err)
+	}
+	if _, err := rm.handleBody(nil, nil, grant, []byte(`[]`)); toJRPCError(err).Code != CodeInvalidRequest {
+		t.Errorf("the empty batch returns %v, expected the invalid request", err)
+	}
+
+	tests := []struct {
+		body string
+		code int
+	}{
+		{`{"jsonrpc":"2.0","id":1,"params":[]}`, CodeInvalidRequest},
+		{`{"jsonrpc":"2.0","id":1,"method":"Balance","params":["3CUsUpvEK"]}`, CodeInvalidRequest},
+		{`{"jsonrpc":"2.0","id":1,"method":"Unknown","params":[]}`, CodeInvalidRequest},
+	}
+	for _, tt := range tests {
+		res, err := rm.handleBody(nil, nil, grant, []byte(tt.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := res.(*JRPCResponse)
+		if r.Error == nil || r.Error.Code != tt.code {
+			t.Errorf("%s returns %+v, expected the code %d", tt.body, r.Error, tt.code)
+		}
+	}
+
+	tests = []struct {
+		body string
+		code int
+	}{
+		{`{"jsonrpc":"2.0","id":1,"method":"Unknown","params":[]}`, CodeMethodNotFound},
+		{`{"jsonrpc":"2.0","id":1,"method":"Balance","params":["bad"]}`, CodeInvalidParams},
+		{`{"jsonrpc":"2.0","id":1,"method":"Balance","params":[]}`, CodeInvalidParams},
+	}
+	for _, tt := range tests {
+		res, err := rm.handleBody(nil, nil, adminGrant(), []byte(tt.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := res.(*JRPCResponse)
+		if r.Error == nil || r.Error.Code != tt.code {
+			t.Errorf("%s returns %+v, expected the code %d", tt.body, r.Error, tt.code)
+		}
+	}
+}
+
+func TestHandleBodyBatch(t *testing.T) {
+	rm := newTestManager()
+	rm.SetLimits(Limits{MaxBatchSize: 2})
+
+	body := `[{"jsonrpc":"2.0","id":1,"method":"Height","params":[]},1,{"jsonrpc":"2.0","method":"Height","params":[]}]`
+	if _, err := rm.handleBody(nil, nil, adminGrant(), []byte(body)); err != ErrTooLargeBatch {
+		t.Fatalf("the batch over the limit returns %v, expected %v", err, ErrTooLargeBatch)
+	}
+
+	rm.SetLimits(Limits{})
+	res, err := rm.handleBody(nil, nil, adminGrant(), []byte(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := res.([]*JRPCResponse)
+	if len(list) != 2 {
+		t.Fatalf("%d responses, expected 2 without the notification", len(list))
+	}
+	if list[0].Error != nil || list[0].Result != 10 {
+		t.Errorf("the first response is %+v", list[0])
+	}
+	if list[1].Error == nil || list[1].Error.Code != CodeInvalidRequest {
+		t.Errorf("the invalid request of the batch returns %+v", list[1].Error)
+	}
+
+	data, err := json.Marshal(list[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"jsonrpc":"2.0","id":1,"result":10}` {
+		t.Errorf("the response is %s, expected no error member", data)
+	}
+}
+
+func TestCallTimeout(t *testing.T) {
+	rm := newTestManager()
+	rm.SetLimits(Limits{CallTimeout: 10 * time.Millisecond, MaxRunningCalls: 1})
+
+	release := make(chan struct{})
+	rm.AddUnlocked("Slow", func(kn *kernel.Kernel, ID interface{}, arg *rpc.Argument) (interface{}, error) {
+		<-release
+		return nil, nil
+	})
+	if _, err := rm.call(nil, "a", "Slow", rm.funcMap["Slow"], nil, nil); err != ErrCallTimeout {
+		t.Fatalf("the slow call returns %v, expected %v", err, ErrCallTimeout)
+	}
+	if _, err := rm.call(nil, "a", "Height", rm.funcMap["Height"], nil, nil); err != ErrTooManyRunningCalls {
+		t.Fatalf("the call while the timed out call of the client is running returns %v, expected %v", err, ErrTooManyRunningCalls)
+	}
+	if ret, err := rm.call(nil, "b", "Height", rm.funcMap["Height"], nil, nil); err != nil || ret != 10 {
+		t.Fatalf("the call of the other client returns %v, %v, expected 10", ret, err)
+	}
+
+	close(release)
+	deadline := time.Now().Add(time.Second)
+	for {
+		ret, err := rm.call(nil, "a", "Height", rm.funcMap["Height"], nil, nil)
+		if err == nil {
+			if ret != 10 {
+				t.Fatalf("the call returns %v, expected 10", ret)
+			}
+			break
+		}
+		if err != ErrTooManyRunningCalls || time.Now().After(deadline) {
+			t.Fatalf("the call after the timed out call is finished returns %v", err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	rm.Lock()
+	defer rm.Unlock()
+	if len(rm.runningCalls) != 0 {
+		t.Fatalf("%d clients have the running calls after the calls are finished", len(rm.runningCalls))
+	}
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"github.com/fletaio/core/block"
+	"github.com/fletaio/framework/chain"
+)
+
+// maximum ranges of the ranged methods
+const (
+	MaxBlockRange  = 100
+	MaxHeaderRange = 1000
+)
+
+// BlockPage is a page of the blocks of the range
+// Next is the height to request the rest of the range, and it is null when the range is finished
+type BlockPage struct {
+	Blocks []*block.Block `json:"blocks"`
+	Next   *uint32        `json:"next"`
+}
+
+// HeaderPage is a page of the headers of the range
+// Next is the height to request the rest of the range, and it is null when the range is finished
+type HeaderPage struct {
+	Headers []*block.Header `json:"headers"`
+	Next    *uint32         `json:"next"`
+}
+
+// BlockByHeight returns the block of the height
+func BlockByHeight(provider chain.Provider, height uint32) (*block.Block, error) {
+	cd, err := provider.Data(height)
+	if err != nil {
+		return nil, err
+	}
+	b := &block.Block{
+		Header: cd.Header.(*block.Header),
+		Body:   cd.Body.(*block.Body),
+	}
+	return b, nil
+}
+
+// Blocks returns the blocks from the height of from to the height of to
+// The range is limited by MaxBlockRange and the height of the chain
+func Blocks(provider chain.Provider, from uint32, to uint32) (*BlockPage, error) {
+	last, next, err := pageRange(provider, from, to, MaxBlockRange)
+	if err != nil {
+		return nil, err
+	}
+	page := &BlockPage{
+		Blocks: []*block.Block{},
+		Next:   next,
+	}
+	for h := pageFrom(from); h <= last; h++ {
+		b, err := BlockByHeight(provider, h)
+		if err != nil {
+			return nil, err
+		}
+		page.Blocks = append(page.Blocks, b)
+	}
+	return page, nil
+}
+
+// Headers returns the headers from the height of from to the height of to
+// The range is limited by MaxHeaderRange and the height of the chain
+func Headers(provider chain.Provider, from uint32, to uint32) (*HeaderPage, error) {
+	last, next, err := pageRange(provider, from, to, MaxHeaderRange)
+	if err != nil {
+		return nil, err
+	}
+	page := &HeaderPage{
+		Headers: []*block.Header{},
+		Next:    next,
+	}
+	for h := pageFrom(from); h <= last; h++ {
+		bh, err := provider.Header(h)
+		if err != nil {
+			return nil, err
+		}
+		page.Headers = append(page.Headers, bh.(*block.Header))
+	}
+	return page, nil
+}
+
+// pageFrom returns the first height of the page
+// The genesis is not a block, so the page starts from the height 1
+func pageFrom(from uint32) uint32 {
+	if from == 0 {
+		return 1
+	}
+	return from
+}
+
+// pageRange returns the last height of the page and the next height of the range
+func pageRange(provider chain.Provider, from uint32, to uint32, max uint32) (uint32, *uint32, error) {
+	if to < from {
+		return 0, nil, ErrInvalidRange
+	}
+	from = pageFrom(from)
+	last := to
+	if height := provider.Height(); last > height {
+		last = height
+	}
+	if last >= from && last-from >= max {
+		last = from + max - 1
+		next := last + 1
+		return last, &next, nil
+	}
+	return last, nil, nil
+}
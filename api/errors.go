@@ -0,0 +1,42 @@
+package api
+
+import (
+	"errors"
+)
+
+// api errors
+var (
+	ErrUnknownAccountType   = errors.New("unknown account type")
+	ErrInvalidEncoding      = errors.New("invalid encoding (hex or base64)")
+	ErrTrailingBytes        = errors.New("trailing bytes after the transaction")
+	ErrEmptySignature       = errors.New("empty signature")
+	ErrInvalidSignatureSize = errors.New("invalid signature size")
+	ErrInsufficientFee      = errors.New("insufficient balance for the fee")
+	ErrInvalidRange         = errors.New("invalid range")
+
+	ErrClosedConnection          = errors.New("closed connection")
+	ErrTooManySubscriptions      = errors.New("too many subscriptions")
+	ErrTooManyFilterAddresses    = errors.New("too many filter addresses")
+	ErrInvalidSubscriptionFilter = errors.New("invalid subscription filter")
+	ErrNotExistSubscription      = errors.New("not exist subscription")
+	ErrUnknownEventType          = errors.New("unknown event type")
+
+	ErrParseError          = errors.New("parse error")
+	ErrInvalidRequest      = errors.New("invalid request")
+	ErrEmptyBatch          = errors.New("empty batch")
+	ErrTooLargeBatch       = errors.New("too large batch")
+	ErrTooLargeBody        = errors.New("too large body")
+	ErrCallTimeout         = errors.New("call timeout")
+	ErrTooManyRunningCalls = errors.New("too many running calls")
+
+	ErrUnknownGroup      = errors.New("unknown method group")
+	ErrShortAPIKey       = errors.New("too short api key")
+	ErrShortJWTSecret    = errors.New("too short jwt secret")
+	ErrInvalidCredential = errors.New("invalid credential")
+	ErrExpiredCredential = errors.New("expired credential")
+	ErrPermissionDenied  = errors.New("permission denied")
+
+	ErrInvalidRateLimit = errors.New("invalid rate limit")
+	ErrRateLimited      = errors.New("rate limited")
+	ErrNotAllowedOrigin = errors.New("not allowed origin")
+)
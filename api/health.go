@@ -0,0 +1,49 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/fletaio/cmd/health"
+	"github.com/labstack/echo"
+)
+
+// SetHealth serves the liveness at /healthz and the readiness at /readyz
+// They are served without the credential for the orchestrator
+func (rm *Manager) SetHealth(hc *health.Health) {
+	hc.AddReady("rpc", func() (string, error) {
+		rm.Lock()
+		defer rm.Unlock()
+
+		return fmt.Sprintf("%d methods", len(rm.funcMap)), nil
+	})
+
+	rm.Lock()
+	defer rm.Unlock()
+
+	rm.health = hc
+}
+
+// runHealth registers the health endpoints
+func (rm *Manager) runHealth() {
+	rm.Lock()
+	hc := rm.health
+	rm.Unlock()
+
+	if hc == nil {
+		return
+	}
+	rm.e.GET("/healthz", func(c echo.Context) error {
+		return healthJSON(c, hc.Live())
+	})
+	rm.e.GET("/readyz", func(c echo.Context) error {
+		return healthJSON(c, hc.Ready())
+	})
+}
+
+func healthJSON(c echo.Context, rp *health.Report) error {
+	if !rp.IsOK() {
+		return c.JSON(http.StatusServiceUnavailable, rp)
+	}
+	return c.JSON(http.StatusOK, rp)
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"github.com/fletaio/cmd/index"
+	"github.com/fletaio/common"
+	"github.com/fletaio/common/hash"
+	"github.com/fletaio/core/amount"
+)
+
+// limits of the address history
+const (
+	DefaultHistoryLimit = 20
+	MaxHistoryLimit     = 100
+)
+
+// history kind names
+var historyKindNames = map[uint8]string{
+	index.HistoryTransaction: "transaction",
+	index.HistoryEvent:       "event",
+	index.HistoryReward:      "reward",
+}
+
+// AddressHistoryItem is the view of a history item of the address
+type AddressHistoryItem struct {
+	Kind       string         `json:"kind"`
+	Height     uint32         `json:"height"`
+	TxIndex    *uint16        `json:"tx_index,omitempty"`
+	TxHash     *hash.Hash256  `json:"tx_hash,omitempty"`
+	EventIndex *uint16        `json:"event_index,omitempty"`
+	Amount     *amount.Amount `json:"amount,omitempty"`
+	Cursor     string         `json:"cursor"`
+}
+
+// AddressHistory is a page of the history of the address from the newest one
+// Cursor is given to request the next page, and it is null when there is no more item
+type AddressHistory struct {
+	Items  []*AddressHistoryItem `json:"items"`
+	Cursor *string               `json:"cursor"`
+}
+
+// AddressHistoryOf returns the page of the history of the address after the cursor
+func AddressHistoryOf(idx *index.Index, addr common.Address, Cursor string, Limit int) (*AddressHistory, error) {
+	if Limit <= 0 {
+		Limit = DefaultHistoryLimit
+	} else if Limit > MaxHistoryLimit {
+		Limit = MaxHistoryLimit
+	}
+	list, err := idx.AddressHistory(addr, Cursor, Limit+1)
+	if err != nil {
+		return nil, err
+	}
+	ah := &AddressHistory{
+		Items: []*AddressHistoryItem{},
+	}
+	if len(list) > Limit {
+		list = list[:Limit]
+		next := list[Limit-1].Cursor
+		ah.Cursor = &next
+	}
+	for _, v := range list {
+		item := &AddressHistoryItem{
+			Kind:   historyKindNames[v.Kind],
+			Height: v.Height,
+			Cursor: v.Cursor,
+		}
+		switch v.Kind {
+		case index.HistoryReward:
+			item.Amount = v.Amount
+		case index.HistoryEvent:
+			TxIndex, TxHash, EventIndex := v.TxIndex, v.TxHash, v.EventIndex
+			item.TxIndex = &TxIndex
+			item.TxHash = &TxHash
+			item.EventIndex = &EventIndex
+		default:
+			TxIndex, TxHash := v.TxIndex, v.TxHash
+			item.TxIndex = &TxIndex
+			item.TxHash = &TxHash
+		}
+		ah.Items = append(ah.Items, item)
+	}
+	return ah, nil
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"time"
+
+	"github.com/fletaio/cmd/logging"
+	"github.com/fletaio/core/kernel"
+	"github.com/fletaio/framework/rpc"
+)
+
+var rpcLog = logging.Get(logging.RPC)
+
+// AddLogMethods registers the admin methods of the log levels
+// SetLogLevel(level, component) sets the level of the component, and every component when the component is not given
+func (rm *Manager) AddLogMethods() {
+	rm.AddUnlocked("LogLevels", func(kn *kernel.Kernel, ID interface{}, arg *rpc.Argument) (interface{}, error) {
+		return logging.Levels(), nil
+	})
+	rm.AddUnlocked("SetLogLevel", func(kn *kernel.Kernel, ID interface{}, arg *rpc.Argument) (interface{}, error) {
+		if arg.Len() < 1 {
+			return nil, rpc.ErrInvalidArgument
+		}
+		name, err := arg.String(0)
+		if err != nil {
+			return nil, err
+		}
+		lv, err := logging.ParseLevel(name)
+		if err != nil {
+			return nil, err
+		}
+		component := logging.AllComponents
+		if arg.Len() > 1 {
+			if component, err = arg.String(1); err != nil {
+				return nil, err
+			}
+		}
+		if err := logging.SetLevel(component, lv); err != nil {
+			return nil, err
+		}
+		rpcLog.Info("log level is changed", "component", component, "level", lv)
+		return logging.Levels(), nil
+	})
+}
+
+// logCall writes the debug entry of the call
+func logCall(Method string, d time.Duration, err error) {
+	if err != nil {
+		rpcLog.Debug("call", "method", Method, "duration", d, "error", err)
+	} else {
+		rpcLog.Debug("call", "method", Method, "duration", d)
+	}
+}
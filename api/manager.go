@@ -0,0 +1,461 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fletaio/cmd/health"
+	"github.com/fletaio/cmd/metrics"
+	"github.com/fletaio/cmd/tlsutil"
+	"github.com/fletaio/common"
+	"github.com/fletaio/common/hash"
+	"github.com/fletaio/core/block"
+	"github.com/fletaio/core/data"
+	"github.com/fletaio/core/kernel"
+	"github.com/fletaio/core/message_def"
+	"github.com/fletaio/core/transaction"
+	"github.com/fletaio/framework/rpc"
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo"
+	"github.com/labstack/echo/middleware"
+)
+
+// WebsocketPath is the path of the websocket endpoint
+const WebsocketPath = "/api/endpoints/websocket"
+
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool {
+		return true
+	},
+}
+
+// JRPCRequest is a json rpc request
+// Params are kept as raw messages to accept the string parameters that json.Number refuses
+type JRPCRequest struct {
+	JSONRPC string            `json:"jsonrpc"`
+	ID      interface{}       `json:"id"`
+	Method  string            `json:"method"`
+	Params  []json.RawMessage `json:"params"`
+}
+
+// JRPCResponse is a json rpc response
+type JRPCResponse struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      interface{} `json:"id"`
+	Result  interface{} `json:"result"`
+	Error   *JRPCError  `json:"error,omitempty"`
+}
+
+// json rpc error codes
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+)
+
+// JRPCError is the error object of the json rpc response
+// Data is the typed reason of the rejection and the rate limit
+type JRPCError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// Error returns the message of the error
+func (e *JRPCError) Error() string {
+	return e.Message
+}
+
+// toJRPCError returns the error object of the error with the code of the kind of the error
+func toJRPCError(err error) *JRPCError {
+	switch e := err.(type) {
+	case *JRPCError:
+		return e
+	case *Rejection:
+		return &JRPCError{Code: CodeInvalidParams, Message: e.Message, Data: e}
+	case *RateLimitError:
+		return &JRPCError{Code: CodeInvalidRequest, Message: e.Message, Data: e}
+	}
+	Code := CodeInternalError
+	switch err {
+	case ErrInvalidRequest, ErrEmptyBatch, ErrTooLargeBatch, ErrTooLargeBody, ErrInvalidCredential, ErrExpiredCredential, ErrPermissionDenied:
+		Code = CodeInvalidRequest
+	case rpc.ErrInvalidMethod:
+		Code = CodeMethodNotFound
+	case rpc.ErrInvalidArgument, rpc.ErrInvalidArgumentIndex, rpc.ErrInvalidArgumentType, ErrInvalidRange, ErrInvalidEncoding,
+		ErrInvalidSubscriptionFilter, ErrTooManyFilterAddresses, ErrUnknownEventType, ErrNotExistSubscription,
+		common.ErrInvalidAddressFormat, common.ErrInvalidAddressCheckSum, hash.ErrInvalidHashFormat, hash.ErrInvalidHashSize:
+		Code = CodeInvalidParams
+	}
+	return &JRPCError{Code: Code, Message: err.Error()}
+}
+
+// EventNotify is a notification of the kernel event
+type EventNotify struct {
+	Type      string                 `json:"type"`
+	Timestamp int64                  `json:"timestamp"`
+	Data      map[string]interface{} `json:"data"`
+}
+
+// Manager serves the rpc methods by the same endpoints of rpc.Manager
+// The handlers of rpc.Manager are used as they are
+type Manager struct {
+	sync.Mutex
+	e            *echo.Echo
+	funcMap      map[string]*handler
+	eventLocker  []*sync.Mutex
+	eventWatcher []*websocket.Conn
+	clients      map[*wsClient]bool
+	eventer      *data.Eventer
+	limits       Limits
+	routes       []*Route
+	access       *Access
+	limiter      *RateLimiter
+	corsOrigins  []string
+	keyPair      *tlsutil.KeyPair
+	calls        *metrics.Counter
+	callTime     *metrics.Histogram
+	health       *health.Health
+
+	subscriptionSeq uint64
+	runningCalls    map[string]int
+}
+
+// NewManager returns a Manager
+func NewManager() *Manager {
+	rm := &Manager{
+		e:            echo.New(),
+		funcMap:      map[string]*handler{},
+		eventLocker:  []*sync.Mutex{},
+		eventWatcher: []*websocket.Conn{},
+		clients:      map[*wsClient]bool{},
+		runningCalls: map[string]int{},
+	}
+	rm.e.HideBanner = true
+	rm.SetLimits(Limits{})
+	rm.access, _ = NewAccess(nil, nil, "")
+	rm.corsOrigins = []string{"*"}
+	return rm
+}
+
+// Close stops the server
+func (rm *Manager) Close() {
+	rm.e.Close()
+	rm.closeClients()
+}
+
+type handler struct {
+	fn       rpc.Handler
+	unlocked bool
+}
+
+// Add registers the handler of the method
+// The handler is called with the lock of the kernel
+func (rm *Manager) Add(Method string, fn rpc.Handler) {
+	rm.Lock()
+	defer rm.Unlock()
+
+	rm.funcMap[Method] = &handler{fn: fn}
+}
+
+// AddUnlocked registers the handler of the method that is called without the lock of the kernel
+// It is used by the handler that calls the functions of the kernel which acquire the lock by themselves
+func (rm *Manager) AddUnlocked(Method string, fn rpc.Handler) {
+	rm.Lock()
+	defer rm.Unlock()
+
+	rm.funcMap[Method] = &handler{fn: fn, unlocked: true}
+}
+
+// parseParams converts the parameters to the arguments of rpc.Argument
+// The string is unquoted and the other values are given as the json text
+func parseParams(params []json.RawMessage) ([]*string, error) {
+	args := make([]*string, 0, len(params))
+	for _, v := range params {
+		raw := strings.TrimSpace(string(v))
+		switch {
+		case len(raw) == 0 || raw == "null":
+			args = append(args, nil)
+		case raw[0] == '"':
+			var str string
+			if err := json.Unmarshal(v, &str); err != nil {
+				return nil, err
+			}
+			args = append(args, &str)
+		default:
+			args = append(args, &raw)
+		}
+	}
+	return args, nil
+}
+
+func (rm *Manager) handleJRPC(kn *kernel.Kernel, Client string, req *JRPCRequest) *JRPCResponse {
+	rm.Lock()
+	h := rm.funcMap[req.Method]
+	rm.Unlock()
+
+	var ret interface{}
+	var err error
+	if h == nil {
+		err = rpc.ErrInvalidMethod
+	} else if args, perr := parseParams(req.Params); perr != nil {
+		err = &JRPCError{Code: CodeInvalidParams, Message: fmt.Sprintf("%v: %v", rpc.ErrInvalidArgument, perr)}
+	} else {
+		ret, err = rm.call(kn, Client, req.Method, h, req.ID, args)
+	}
+	if req.ID == nil {
+		return nil
+	}
+	res := &JRPCResponse{
+		JSONRPC: req.JSONRPC,
+		ID:      req.ID,
+	}
+	if err != nil {
+		res.Error = toJRPCError(err)
+	} else {
+		res.Result = ret
+	}
+	return res
+}
+
+// readBody reads the body of the request by http.MaxBytesReader, and ErrTooLargeBody is returned when the body is larger than the max body size
+// The connection is closed after the response of the too large body
+func readBody(c echo.Context, MaxBodySize int64) ([]byte, error) {
+	body, err := ioutil.ReadAll(http.MaxBytesReader(c.Response().Writer, c.Request().Body, MaxBodySize))
+	if err != nil {
+		if int64(len(body)) >= MaxBodySize {
+			return nil, ErrTooLargeBody
+		}
+		return nil, err
+	}
+	return body, nil
+}
+
+func (rm *Manager) handleEvent(noti *EventNotify) {
+	conns := []*websocket.Conn{}
+	locks := []*sync.Mutex{}
+	rm.Lock()
+	conns = append(conns, rm.eventWatcher...)
+	locks = append(locks, rm.eventLocker...)
+	rm.Unlock()
+
+	data, err := json.Marshal(noti)
+	if err != nil {
+		return
+	}
+	for i := range conns {
+		conn := conns[i]
+		lock := locks[i]
+		errCh := make(chan error, 1)
+		go func() {
+			lock.Lock()
+			err := conn.WriteMessage(websocket.TextMessage, data)
+			lock.Unlock()
+			errCh <- err
+		}()
+		deadTimer := time.NewTimer(15 * time.Second)
+		select {
+		case <-deadTimer.C:
+			conn.Close()
+		case err := <-errCh:
+			deadTimer.Stop()
+			if err != nil {
+				conn.Close()
+			}
+		}
+	}
+}
+
+// SetTLS serves the endpoints by the tls of the key pair
+// The key pair is reloaded when the files are changed
+func (rm *Manager) SetTLS(kp *tlsutil.KeyPair) {
+	rm.Lock()
+	defer rm.Unlock()
+
+	rm.keyPair = kp
+}
+
+// Run serves the endpoints of the bind address
+func (rm *Manager) Run(kn *kernel.Kernel, Bind string) error {
+	rm.e.Use(middleware.CORSWithConfig(rm.corsConfig()))
+	rm.e.POST("/api/endpoints/http", func(c echo.Context) error {
+		defer c.Request().Body.Close()
+
+		grant, err := rm.authorize(c.Request())
+		if err != nil {
+			return c.JSON(http.StatusUnauthorized, &JRPCResponse{
+				JSONRPC: "2.0",
+				Error:   toJRPCError(err),
+			})
+		}
+		body, err := readBody(c, rm.currentLimits().MaxBodySize)
+		if err == ErrTooLargeBody {
+			return c.JSON(http.StatusRequestEntityTooLarge, &JRPCResponse{
+				JSONRPC: "2.0",
+				Error:   toJRPCError(err),
+			})
+		} else if err != nil {
+			return c.JSON(http.StatusBadRequest, &JRPCResponse{
+				JSONRPC: "2.0",
+				Error:   toJRPCError(err),
+			})
+		}
+		res, err := rm.handleBody(kn, nil, grant, body)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, &JRPCResponse{
+				JSONRPC: "2.0",
+				Error:   toJRPCError(err),
+			})
+		}
+		if res == nil {
+			return c.NoContent(http.StatusOK)
+		}
+		if sec := retryAfter(res); sec > 0 {
+			c.Response().Header().Set("Retry-After", strconv.Itoa(sec))
+			if _, is := res.(*JRPCResponse); is {
+				return c.JSON(http.StatusTooManyRequests, res)
+			}
+		}
+		return c.JSON(http.StatusOK, res)
+	})
+	rm.runRoutes(kn)
+	rm.runHealth()
+	rm.e.GET(WebsocketPath, func(c echo.Context) error {
+		grant, err := rm.authorize(c.Request())
+		if err != nil {
+			return c.JSON(http.StatusUnauthorized, &RESTError{Error: err.Error()})
+		}
+		if origin := c.Request().Header.Get(echo.HeaderOrigin); len(origin) > 0 && !rm.allowOrigin(origin) {
+			return c.JSON(http.StatusForbidden, &RESTError{Error: ErrNotAllowedOrigin.Error()})
+		}
+		isEvent := strings.ToLower(c.QueryParam("type")) == "event"
+		if isEvent && !grant.Groups[GroupChain] {
+			return c.JSON(http.StatusForbidden, &RESTError{Error: ErrPermissionDenied.Error()})
+		}
+		conn, err := upgrader.Upgrade(c.Response().Writer, c.Request(), nil)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		conn.SetReadLimit(rm.currentLimits().MaxBodySize)
+
+		switch strings.ToLower(c.QueryParam("type")) {
+		case "event":
+			rm.Lock()
+			rm.eventWatcher = append(rm.eventWatcher, conn)
+			rm.eventLocker = append(rm.eventLocker, &sync.Mutex{})
+			rm.Unlock()
+
+			defer func() {
+				rm.Lock()
+				eventWatcher := []*websocket.Conn{}
+				eventLocker := []*sync.Mutex{}
+				for i, c := range rm.eventWatcher {
+					if c != conn {
+						eventWatcher = append(eventWatcher, c)
+						eventLocker = append(eventLocker, rm.eventLocker[i])
+					}
+				}
+				rm.eventWatcher = eventWatcher
+				rm.eventLocker = eventLocker
+				rm.Unlock()
+			}()
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return nil
+				}
+			}
+		default:
+			cl := newWSClient(conn, grant)
+			rm.addClient(cl)
+			defer rm.removeClient(cl)
+			defer cl.Close()
+			go cl.writeLoop()
+
+			for {
+				_, data, err := conn.ReadMessage()
+				if err != nil {
+					return nil
+				}
+				res, err := rm.handleBody(kn, cl, cl.grant, data)
+				if err != nil {
+					res = &JRPCResponse{
+						JSONRPC: "2.0",
+						Error:   toJRPCError(err),
+					}
+				}
+				if res == nil {
+					continue
+				}
+				if err := cl.send(res); err != nil {
+					return nil
+				}
+			}
+		}
+	})
+	rm.Lock()
+	kp := rm.keyPair
+	rm.Unlock()
+	if kp != nil {
+		return rm.e.StartServer(&http.Server{
+			Addr:      Bind,
+			TLSConfig: tlsutil.ServerConfig(kp),
+		})
+	}
+	return rm.e.Start(Bind)
+}
+
+// OnProcessBlock called when processing a block to the chain (error prevent processing block)
+func (rm *Manager) OnProcessBlock(kn *kernel.Kernel, b *block.Block, s *block.ObserverSigned, ctx *data.Context) error {
+	return nil
+}
+
+// AfterProcessBlock called when processed block to the chain
+func (rm *Manager) AfterProcessBlock(kn *kernel.Kernel, b *block.Block, s *block.ObserverSigned, ctx *data.Context) {
+	rm.handleEvent(&EventNotify{
+		Type:      "AfterProcessBlock",
+		Timestamp: time.Now().UnixNano(),
+		Data: map[string]interface{}{
+			"hash":     b.Header.Hash(),
+			"header":   b.Header,
+			"tx_count": len(b.Body.Transactions),
+		},
+	})
+	rm.notifyBlock(kn, b, ctx)
+}
+
+// OnPushTransaction called when pushing a transaction to the transaction pool (error prevent push transaction)
+func (rm *Manager) OnPushTransaction(kn *kernel.Kernel, tx transaction.Transaction, sigs []common.Signature) error {
+	return nil
+}
+
+// AfterPushTransaction called when pushed a transaction to the transaction pool
+func (rm *Manager) AfterPushTransaction(kn *kernel.Kernel, tx transaction.Transaction, sigs []common.Signature) {
+	rm.notifyTransaction(kn, tx)
+}
+
+// DoTransactionBroadcast called when a transaction need to be broadcast
+func (rm *Manager) DoTransactionBroadcast(kn *kernel.Kernel, msg *message_def.TransactionMessage) {
+}
+
+// DebugLog provides internal debug logs to handlers
+func (rm *Manager) DebugLog(kn *kernel.Kernel, args ...interface{}) {
+	if len(args) > 0 {
+		str := fmt.Sprintln(args...)
+		rm.handleEvent(&EventNotify{
+			Type:      "DebugLog",
+			Timestamp: time.Now().UnixNano(),
+			Data: map[string]interface{}{
+				"log": str[:len(str)-1],
+			},
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"time"
+
+	"github.com/fletaio/cmd/metrics"
+)
+
+// metric names of the api
+const (
+	// RPCCalls is the number of the calls of the registered methods by the method and the result (ok, error, timeout)
+	RPCCalls = "fleta_rpc_calls_total"
+	// RPCCallDuration is the duration of the calls of the registered methods in seconds
+	RPCCallDuration = "fleta_rpc_call_duration_seconds"
+)
+
+// SetMetrics registers the metrics of the calls to the registry
+// The unknown methods are not counted to keep the labels bounded
+func (rm *Manager) SetMetrics(reg *metrics.Registry) {
+	calls := metrics.NewCounter(RPCCalls, "number of the calls of the method by the result", "method", "result")
+	callTime := metrics.NewHistogram(RPCCallDuration, "duration of the calls of the method in seconds", metrics.DefaultBuckets, "method")
+	reg.Register(calls)
+	reg.Register(callTime)
+
+	rm.Lock()
+	defer rm.Unlock()
+
+	rm.calls = calls
+	rm.callTime = callTime
+}
+
+// observeCall updates the metrics of the call
+func (rm *Manager) observeCall(Method string, d time.Duration, err error) {
+	rm.Lock()
+	calls := rm.calls
+	callTime := rm.callTime
+	rm.Unlock()
+
+	if calls == nil {
+		return
+	}
+	result := "ok"
+	if err == ErrCallTimeout {
+		result = "timeout"
+	} else if err == ErrTooManyRunningCalls {
+		result = "refused"
+	} else if err != nil {
+		result = "error"
+	}
+	calls.Inc(Method, result)
+	callTime.Observe(d.Seconds(), Method)
+}
@@ -0,0 +1,240 @@
+package api
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/echo/middleware"
+)
+
+// RateLimitAll is the method of the limit shared by the methods that have no limit of them
+const RateLimitAll = "*"
+
+// idle time of the bucket that is removed by the sweep
+const bucketIdleTime = 10 * time.Minute
+
+// RateLimit is the limit of the token bucket
+// Rate tokens are added every second up to Burst tokens, and a call takes a token
+type RateLimit struct {
+	Rate  float64
+	Burst float64
+}
+
+// ParseRateLimit parses the limit of the "rate burst" form, and the burst is the rate when it is not given
+func ParseRateLimit(str string) (*RateLimit, error) {
+	fields := strings.Fields(str)
+	if len(fields) == 0 || len(fields) > 2 {
+		return nil, ErrInvalidRateLimit
+	}
+	Rate, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil || Rate <= 0 || math.IsInf(Rate, 0) {
+		return nil, ErrInvalidRateLimit
+	}
+	Burst := math.Max(Rate, 1)
+	if len(fields) == 2 {
+		b, err := strconv.Atoi(fields[1])
+		if err != nil || b < 1 {
+			return nil, ErrInvalidRateLimit
+		}
+		Burst = float64(b)
+	}
+	return &RateLimit{Rate: Rate, Burst: Burst}, nil
+}
+
+// RateLimits are the limits of the methods
+type RateLimits map[string]*RateLimit
+
+// ParseRateLimits parses the limits of the methods
+func ParseRateLimits(m map[string]string) (RateLimits, error) {
+	rls := RateLimits{}
+	for Method, v := range m {
+		rl, err := ParseRateLimit(v)
+		if err != nil {
+			return nil, err
+		}
+		rls[Method] = rl
+	}
+	return rls, nil
+}
+
+// limit returns the limit of the method and the name of the bucket of it
+func (rls RateLimits) limit(Method string) (*RateLimit, string) {
+	if rl, has := rls[Method]; has {
+		return rl, Method
+	}
+	if rl, has := rls[RateLimitAll]; has {
+		return rl, RateLimitAll
+	}
+	return nil, ""
+}
+
+type bucket struct {
+	tokens   float64
+	lastTime time.Time
+}
+
+// RateLimiter limits the calls of the clients by the token buckets
+// The anonymous client is limited by the ip limits and the client of the credential is limited by the key limits
+type RateLimiter struct {
+	sync.Mutex
+	ipLimits  RateLimits
+	keyLimits RateLimits
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// NewRateLimiter returns a RateLimiter
+func NewRateLimiter(IPLimits RateLimits, KeyLimits RateLimits) *RateLimiter {
+	return &RateLimiter{
+		ipLimits:  IPLimits,
+		keyLimits: KeyLimits,
+		buckets:   map[string]*bucket{},
+		lastSweep: time.Now(),
+	}
+}
+
+// Take takes a token of the method from the bucket of the client
+// It returns the time to wait for the next token when the bucket is empty
+func (rlr *RateLimiter) Take(gr *Grant, Method string, now time.Time) (time.Duration, bool) {
+	limits := rlr.ipLimits
+	if gr.IsKey {
+		limits = rlr.keyLimits
+	}
+	rl, name := limits.limit(Method)
+	if rl == nil {
+		return 0, true
+	}
+
+	rlr.Lock()
+	defer rlr.Unlock()
+
+	if now.Sub(rlr.lastSweep) > bucketIdleTime {
+		for k, b := range rlr.buckets {
+			if now.Sub(b.lastTime) > bucketIdleTime {
+				delete(rlr.buckets, k)
+			}
+		}
+		rlr.lastSweep = now
+	}
+
+	key := gr.Client + "/" + name
+	b, has := rlr.buckets[key]
+	if !has {
+		b = &bucket{
+			tokens:   rl.Burst,
+			lastTime: now,
+		}
+		rlr.buckets[key] = b
+	}
+	b.tokens = math.Min(rl.Burst, b.tokens+now.Sub(b.lastTime).Seconds()*rl.Rate)
+	b.lastTime = now
+	if b.tokens < 1 {
+		return time.Duration((1 - b.tokens) / rl.Rate * float64(time.Second)), false
+	}
+	b.tokens--
+	return 0, true
+}
+
+// RateLimitError is the error of the call limited by the rate limit
+type RateLimitError struct {
+	Reason     string `json:"reason"`
+	Message    string `json:"message"`
+	RetryAfter int    `json:"retry_after"`
+}
+
+// Error returns the message of the error
+func (e *RateLimitError) Error() string {
+	return e.Message
+}
+
+func newRateLimitError(wait time.Duration) *RateLimitError {
+	return &RateLimitError{
+		Reason:     "rate_limited",
+		Message:    ErrRateLimited.Error(),
+		RetryAfter: int(math.Ceil(wait.Seconds())),
+	}
+}
+
+// SetRateLimiter sets the rate limiter of the calls
+func (rm *Manager) SetRateLimiter(rlr *RateLimiter) {
+	rm.Lock()
+	defer rm.Unlock()
+
+	rm.limiter = rlr
+}
+
+// takeRate takes a token of the method for the client, and it returns the error when the call is limited
+func (rm *Manager) takeRate(gr *Grant, Method string) *RateLimitError {
+	rm.Lock()
+	rlr := rm.limiter
+	rm.Unlock()
+
+	if rlr == nil {
+		return nil
+	}
+	if wait, ok := rlr.Take(gr, Method, time.Now()); !ok {
+		return newRateLimitError(wait)
+	}
+	return nil
+}
+
+// retryAfter returns the longest retry time of the limited responses
+func retryAfter(res interface{}) int {
+	var list []*JRPCResponse
+	switch res := res.(type) {
+	case *JRPCResponse:
+		list = []*JRPCResponse{res}
+	case []*JRPCResponse:
+		list = res
+	}
+	sec := 0
+	for _, r := range list {
+		if r.Error == nil {
+			continue
+		}
+		if re, is := r.Error.Data.(*RateLimitError); is && re.RetryAfter > sec {
+			sec = re.RetryAfter
+		}
+	}
+	return sec
+}
+
+// SetCORSOrigins sets the origins allowed to the browser, and * allows every origin
+func (rm *Manager) SetCORSOrigins(origins []string) {
+	rm.Lock()
+	defer rm.Unlock()
+
+	if len(origins) == 0 {
+		origins = []string{"*"}
+	}
+	rm.corsOrigins = origins
+}
+
+func (rm *Manager) allowOrigin(origin string) bool {
+	rm.Lock()
+	defer rm.Unlock()
+
+	for _, v := range rm.corsOrigins {
+		if v == "*" || v == origin {
+			return true
+		}
+	}
+	return false
+}
+
+// corsConfig returns the cors config of the origins
+// The credential headers are allowed and the retry header is exposed to the browser
+func (rm *Manager) corsConfig() middleware.CORSConfig {
+	rm.Lock()
+	defer rm.Unlock()
+
+	cfg := middleware.DefaultCORSConfig
+	cfg.AllowOrigins = append([]string{}, rm.corsOrigins...)
+	cfg.AllowHeaders = []string{echo.HeaderContentType, echo.HeaderAuthorization, "X-API-Key"}
+	cfg.ExposeHeaders = []string{"Retry-After"}
+	return cfg
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	tests := []struct {
+		str   string
+		rate  float64
+		burst float64
+	}{
+		{"10", 10, 10},
+		{"0.5", 0.5, 1},
+		{"2 5", 2, 5},
+	}
+	for _, tt := range tests {
+		rl, err := ParseRateLimit(tt.str)
+		if err != nil {
+			t.Fatalf("%q returns %v", tt.str, err)
+		}
+		if rl.Rate != tt.rate || rl.Burst != tt.burst {
+			t.Errorf("%q is parsed to %+v, expected the rate %v and the burst %v", tt.str, rl, tt.rate, tt.burst)
+		}
+	}
+	for _, str := range []string{"", "0", "-1", "rate", "1 0", "1 2 3", "+Inf"} {
+		if _, err := ParseRateLimit(str); err != ErrInvalidRateLimit {
+			t.Errorf("%q returns %v, expected %v", str, err, ErrInvalidRateLimit)
+		}
+	}
+}
+
+func TestRateLimiterTake(t *testing.T) {
+	IPLimits, err := ParseRateLimits(map[string]string{RateLimitAll: "1 2", "Height": "10"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rlr := NewRateLimiter(IPLimits, nil)
+	now := time.Now()
+	anonymous := &Grant{Client: "127.0.0.1"}
+
+	for i := 0; i < 2; i++ {
+		if _, ok := rlr.Take(anonymous, "Balance", now); !ok {
+			t.Fatalf("the call %d of the burst is limited", i)
+		}
+	}
+	wait, ok := rlr.Take(anonymous, "Balance", now)
+	if ok {
+		t.Fatal("the call over the burst is not limited")
+	}
+	if wait != time.Second {
+		t.Errorf("the wait is %v, expected 1s", wait)
+	}
+	if _, ok := rlr.Take(anonymous, "Height", now); !ok {
+		t.Error("the method of its own limit is limited by the bucket of the other methods")
+	}
+	if _, ok := rlr.Take(&Grant{Client: "127.0.0.2"}, "Balance", now); !ok {
+		t.Error("the other client is limited by the bucket of the client")
+	}
+	if _, ok := rlr.Take(anonymous, "Balance", now.Add(time.Second)); !ok {
+		t.Error("the token is not refilled by the rate")
+	}
+	if _, ok := rlr.Take(&Grant{Client: "key", IsKey: true}, "Balance", now.Add(time.Second)); !ok {
+		t.Error("the client of the key is limited without the key limits")
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	list := []*JRPCResponse{
+		{Result: 10},
+		{Error: toJRPCError(newRateLimitError(1500 * time.Millisecond))},
+		{Error: toJRPCError(newRateLimitError(500 * time.Millisecond))},
+		{Error: toJRPCError(ErrInvalidRequest)},
+	}
+	if sec := retryAfter(list); sec != 2 {
+		t.Errorf("the retry after of the batch is %d, expected 2", sec)
+	}
+	if sec := retryAfter(list[0]); sec != 0 {
+		t.Errorf("the retry after of the result is %d, expected 0", sec)
+	}
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"github.com/fletaio/cmd/index"
+	"github.com/fletaio/common"
+	"github.com/fletaio/common/hash"
+	"github.com/fletaio/core/block"
+	"github.com/fletaio/core/event"
+	"github.com/fletaio/core/kernel"
+	"github.com/fletaio/core/transaction"
+)
+
+// TxResultSuccess is the result of the transaction in the block
+// The failed transaction is not included in the block, so every transaction of the block is succeeded
+const TxResultSuccess = "success"
+
+// Transaction is the view of a transaction in the chain
+type Transaction struct {
+	TxHash     hash.Hash256            `json:"tx_hash"`
+	Height     uint32                  `json:"height"`
+	Index      uint16                  `json:"index"`
+	BlockHash  hash.Hash256            `json:"block_hash"`
+	Type       string                  `json:"type"`
+	Tx         transaction.Transaction `json:"tx"`
+	Signatures []common.Signature      `json:"signatures"`
+}
+
+// TransactionReceipt is the result and the emitted events of a transaction in the chain
+// Events is null when the block of the transaction is backfilled to the index
+type TransactionReceipt struct {
+	TxHash    hash.Hash256  `json:"tx_hash"`
+	Height    uint32        `json:"height"`
+	Index     uint16        `json:"index"`
+	BlockHash hash.Hash256  `json:"block_hash"`
+	Result    string        `json:"result"`
+	Events    []event.Event `json:"events"`
+}
+
+// TransactionByHash returns the transaction of the hash using the position of the index
+func TransactionByHash(kn *kernel.Kernel, idx *index.Index, TxHash hash.Hash256) (*Transaction, error) {
+	pos, err := idx.TxPosition(TxHash)
+	if err != nil {
+		return nil, err
+	}
+	b, BlockHash, err := blockOfPosition(kn, pos, TxHash)
+	if err != nil {
+		return nil, err
+	}
+	tx := b.Body.Transactions[pos.Index]
+	name, err := kn.Transactor().NameByType(tx.Type())
+	if err != nil {
+		return nil, err
+	}
+	return &Transaction{
+		TxHash:     TxHash,
+		Height:     pos.Height,
+		Index:      pos.Index,
+		BlockHash:  BlockHash,
+		Type:       name,
+		Tx:         tx,
+		Signatures: b.Body.TransactionSignatures[pos.Index],
+	}, nil
+}
+
+// TransactionReceiptByHash returns the receipt of the transaction of the hash using the index
+func TransactionReceiptByHash(kn *kernel.Kernel, idx *index.Index, TxHash hash.Hash256) (*TransactionReceipt, error) {
+	pos, err := idx.TxPosition(TxHash)
+	if err != nil {
+		return nil, err
+	}
+	_, BlockHash, err := blockOfPosition(kn, pos, TxHash)
+	if err != nil {
+		return nil, err
+	}
+	rc := &TransactionReceipt{
+		TxHash:    TxHash,
+		Height:    pos.Height,
+		Index:     pos.Index,
+		BlockHash: BlockHash,
+		Result:    TxResultSuccess,
+	}
+	if pos.EventsIndexed {
+		events, err := idx.TxEvents(pos)
+		if err != nil {
+			return nil, err
+		}
+		rc.Events = events
+	}
+	return rc, nil
+}
+
+// blockOfPosition returns the block of the position
+// The position is ignored when the transaction of the position is different because the chain is recovered to the lower height
+func blockOfPosition(kn *kernel.Kernel, pos *index.TxPosition, TxHash hash.Hash256) (*block.Block, hash.Hash256, error) {
+	provider := kn.Provider()
+	if pos.Height > provider.Height() {
+		return nil, hash.Hash256{}, index.ErrNotExistTransaction
+	}
+	b, err := BlockByHeight(provider, pos.Height)
+	if err != nil {
+		return nil, hash.Hash256{}, err
+	}
+	if int(pos.Index) >= len(b.Body.Transactions) || !b.Body.Transactions[pos.Index].Hash().Equal(TxHash) {
+		return nil, hash.Hash256{}, index.ErrNotExistTransaction
+	}
+	return b, b.Header.Hash(), nil
+}
@@ -0,0 +1,298 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fletaio/cmd/index"
+	"github.com/fletaio/core/data"
+	"github.com/fletaio/core/db"
+	"github.com/fletaio/core/kernel"
+	"github.com/fletaio/framework/rpc"
+	"github.com/labstack/echo"
+)
+
+// RESTPrefix is the path prefix of the rest endpoints
+const RESTPrefix = "/v1"
+
+// locations of the route parameters
+const (
+	InPath  = "path"
+	InQuery = "query"
+	InBody  = "body"
+)
+
+// types of the route parameters
+// The list parameter of the body is given as the rest arguments of the method
+const (
+	TypeString  = "string"
+	TypeInteger = "integer"
+	TypeList    = "array"
+)
+
+// Route maps a rest endpoint to the registered method
+// The parameters are given to the method as the arguments in order
+type Route struct {
+	HTTPMethod string
+	Path       string
+	Method     string
+	Summary    string
+	Params     []*RouteParam
+}
+
+// RouteParam is a parameter of the route
+type RouteParam struct {
+	Name        string
+	In          string
+	Type        string
+	Required    bool
+	Description string
+}
+
+// RESTError is the error response of the rest endpoints
+type RESTError struct {
+	Error interface{} `json:"error"`
+}
+
+// AddRoute registers the rest endpoint of the method
+// The path is relative to RESTPrefix and the path parameter is given as :name
+func (rm *Manager) AddRoute(rt *Route) {
+	rm.Lock()
+	defer rm.Unlock()
+
+	rm.routes = append(rm.routes, rt)
+}
+
+// routeArgs builds the arguments of the method from the request
+// The body is read up to the max body size
+func routeArgs(c echo.Context, rt *Route, MaxBodySize int64) ([]*string, error) {
+	var body map[string]json.RawMessage
+	for _, p := range rt.Params {
+		if p.In == InBody {
+			bs, err := readBody(c, MaxBodySize)
+			if err != nil {
+				return nil, err
+			}
+			if err := json.Unmarshal(bs, &body); err != nil {
+				return nil, ErrInvalidRequest
+			}
+			break
+		}
+	}
+
+	args := []*string{}
+	for _, p := range rt.Params {
+		var value *string
+		switch p.In {
+		case InPath:
+			str := c.Param(p.Name)
+			value = &str
+		case InQuery:
+			if str := c.QueryParam(p.Name); len(str) > 0 {
+				value = &str
+			}
+		case InBody:
+			if raw, has := body[p.Name]; has {
+				if p.Type == TypeList {
+					var items []json.RawMessage
+					if err := json.Unmarshal(raw, &items); err != nil {
+						return nil, ErrInvalidRequest
+					}
+					list, err := parseParams(items)
+					if err != nil {
+						return nil, ErrInvalidRequest
+					}
+					args = append(args, list...)
+					continue
+				}
+				list, err := parseParams([]json.RawMessage{raw})
+				if err != nil {
+					return nil, ErrInvalidRequest
+				}
+				value = list[0]
+			}
+		}
+		if value == nil {
+			if p.Required {
+				return nil, rpc.ErrInvalidArgument
+			}
+			if p.Type == TypeList {
+				continue
+			}
+		}
+		args = append(args, value)
+	}
+	// the missing optional arguments at the end are not given to keep the default of the method
+	for len(args) > 0 && args[len(args)-1] == nil {
+		args = args[:len(args)-1]
+	}
+	return args, nil
+}
+
+// restStatus returns the http status of the error of the method
+func restStatus(err error) int {
+	switch err {
+	case db.ErrNotExistKey, data.ErrNotExistAccount, index.ErrNotExistTransaction, index.ErrNotExistBlock:
+		return http.StatusNotFound
+	case ErrCallTimeout:
+		return http.StatusGatewayTimeout
+	case ErrTooManyRunningCalls:
+		return http.StatusServiceUnavailable
+	case ErrTooLargeBody:
+		return http.StatusRequestEntityTooLarge
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+func (rm *Manager) handleRoute(kn *kernel.Kernel, rt *Route) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		defer c.Request().Body.Close()
+
+		grant, err := rm.authorize(c.Request())
+		if err != nil {
+			return c.JSON(http.StatusUnauthorized, &RESTError{Error: err.Error()})
+		}
+		if !grant.Allow(rt.Method) {
+			return c.JSON(http.StatusForbidden, &RESTError{Error: ErrPermissionDenied.Error()})
+		}
+		if re := rm.takeRate(grant, rt.Method); re != nil {
+			c.Response().Header().Set("Retry-After", strconv.Itoa(re.RetryAfter))
+			return c.JSON(http.StatusTooManyRequests, &RESTError{Error: re})
+		}
+
+		rm.Lock()
+		h := rm.funcMap[rt.Method]
+		rm.Unlock()
+		if h == nil {
+			return c.JSON(http.StatusNotFound, &RESTError{Error: rpc.ErrInvalidMethod.Error()})
+		}
+		args, err := routeArgs(c, rt, rm.currentLimits().MaxBodySize)
+		if err != nil {
+			return c.JSON(restStatus(err), &RESTError{Error: err.Error()})
+		}
+		ret, err := rm.call(kn, grant.Client, rt.Method, h, nil, args)
+		if re, is := err.(*Rejection); is {
+			return c.JSON(http.StatusBadRequest, &RESTError{Error: re})
+		} else if err != nil {
+			return c.JSON(restStatus(err), &RESTError{Error: err.Error()})
+		}
+		return c.JSON(http.StatusOK, ret)
+	}
+}
+
+// runRoutes registers the rest endpoints and the openapi document
+func (rm *Manager) runRoutes(kn *kernel.Kernel) {
+	rm.Lock()
+	routes := append([]*Route{}, rm.routes...)
+	rm.Unlock()
+
+	g := rm.e.Group(RESTPrefix)
+	for _, rt := range routes {
+		g.Add(rt.HTTPMethod, rt.Path, rm.handleRoute(kn, rt))
+	}
+	g.GET("/openapi.json", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, rm.OpenAPI())
+	})
+}
+
+// OpenAPI returns the openapi document of the rest endpoints
+// The json rpc methods are listed by x-jsonrpc-methods
+func (rm *Manager) OpenAPI() map[string]interface{} {
+	rm.Lock()
+	routes := append([]*Route{}, rm.routes...)
+	methods := make([]string, 0, len(rm.funcMap))
+	for name := range rm.funcMap {
+		methods = append(methods, name)
+	}
+	rm.Unlock()
+	sort.Strings(methods)
+
+	paths := map[string]map[string]interface{}{}
+	for _, rt := range routes {
+		path := RESTPrefix + openAPIPath(rt.Path)
+		item, has := paths[path]
+		if !has {
+			item = map[string]interface{}{}
+			paths[path] = item
+		}
+		op := map[string]interface{}{
+			"operationId": rt.Method,
+			"summary":     rt.Summary,
+			"responses": map[string]interface{}{
+				"200": map[string]interface{}{"description": "the result of " + rt.Method},
+				"400": map[string]interface{}{"description": "the error of the method"},
+				"401": map[string]interface{}{"description": "the credential is invalid"},
+				"403": map[string]interface{}{"description": "the method group is not allowed"},
+				"404": map[string]interface{}{"description": "the item is not found"},
+				"429": map[string]interface{}{"description": "the call is limited by the rate limit"},
+			},
+			"x-method-group": MethodGroup(rt.Method),
+		}
+		params := []interface{}{}
+		props := map[string]interface{}{}
+		required := []string{}
+		for _, p := range rt.Params {
+			schema := map[string]interface{}{"type": p.Type}
+			if p.Type == TypeList {
+				schema["items"] = map[string]interface{}{"type": TypeString}
+			}
+			if p.In == InBody {
+				schema["description"] = p.Description
+				props[p.Name] = schema
+				if p.Required {
+					required = append(required, p.Name)
+				}
+				continue
+			}
+			params = append(params, map[string]interface{}{
+				"name":        p.Name,
+				"in":          p.In,
+				"required":    p.Required || p.In == InPath,
+				"description": p.Description,
+				"schema":      schema,
+			})
+		}
+		if len(params) > 0 {
+			op["parameters"] = params
+		}
+		if len(props) > 0 {
+			op["requestBody"] = map[string]interface{}{
+				"required": len(required) > 0,
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{
+						"schema": map[string]interface{}{
+							"type":       "object",
+							"properties": props,
+							"required":   required,
+						},
+					},
+				},
+			}
+		}
+		item[strings.ToLower(rt.HTTPMethod)] = op
+	}
+	return map[string]interface{}{
+		"openapi": "3.0.0",
+		"info": map[string]interface{}{
+			"title":   "FLETA API",
+			"version": "1",
+		},
+		"paths":             paths,
+		"x-jsonrpc-methods": methods,
+	}
+}
+
+// openAPIPath converts the path parameters from :name to {name}
+func openAPIPath(path string) string {
+	parts := strings.Split(path, "/")
+	for i, v := range parts {
+		if strings.HasPrefix(v, ":") {
+			parts[i] = "{" + v[1:] + "}"
+		}
+	}
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fletaio/core/kernel"
+	"github.com/fletaio/framework/rpc"
+	"github.com/labstack/echo"
+)
+
+func TestRouteBodySize(t *testing.T) {
+	rm := NewManager()
+	rm.SetLimits(Limits{MaxBodySize: 64})
+	ac, err := NewAccess([]string{GroupTx}, nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rm.SetAccess(ac)
+	rm.AddUnlocked("SendTransaction", func(kn *kernel.Kernel, ID interface{}, arg *rpc.Argument) (interface{}, error) {
+		return arg.String(0)
+	})
+	h := rm.handleRoute(nil, &Route{
+		HTTPMethod: http.MethodPost,
+		Path:       "/transactions",
+		Method:     "SendTransaction",
+		Params: []*RouteParam{
+			{Name: "tx", In: InBody, Type: TypeString, Required: true},
+		},
+	})
+
+	tests := []struct {
+		body   string
+		status int
+	}{
+		{`{"tx":"0a0b"}`, http.StatusOK},
+		{`{"tx":"` + strings.Repeat("0", 64) + `"}`, http.StatusRequestEntityTooLarge},
+		{`{"tx":`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, RESTPrefix+"/transactions", strings.NewReader(tt.body))
+		rec := httptest.NewRecorder()
+		if err := h(rm.e.NewContext(req, rec)); err != nil {
+			t.Fatal(err)
+		}
+		if rec.Code != tt.status {
+			t.Errorf("%s returns %d, expected %d", tt.body, rec.Code, tt.status)
+		}
+	}
+}
+
+func TestReadBody(t *testing.T) {
+	e := echo.New()
+	tests := []struct {
+		body string
+		err  error
+	}{
+		{strings.Repeat("0", 63), nil},
+		{strings.Repeat("0", 64), nil},
+		{strings.Repeat("0", 65), ErrTooLargeBody},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+		body, err := readBody(e.NewContext(req, httptest.NewRecorder()), 64)
+		if err != tt.err {
+			t.Errorf("the body of %d bytes returns %v, expected %v", len(tt.body), err, tt.err)
+		} else if err == nil && string(body) != tt.body {
+			t.Errorf("the body of %d bytes is read as %d bytes", len(tt.body), len(body))
+		}
+	}
+}
@@ -0,0 +1,514 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fletaio/cmd/index"
+	"github.com/fletaio/common"
+	"github.com/fletaio/common/hash"
+	"github.com/fletaio/core/block"
+	"github.com/fletaio/core/data"
+	"github.com/fletaio/core/event"
+	"github.com/fletaio/core/kernel"
+	"github.com/fletaio/core/transaction"
+	"github.com/gorilla/websocket"
+)
+
+// limits of the subscriptions of a connection
+// The connection is closed when the notifications are not read and the queue is overflowed
+const (
+	MaxSubscriptions      = 32
+	MaxFilterAddresses    = 100
+	NotificationQueueSize = 256
+)
+
+// subscription kinds
+const (
+	SubscriptionNewHeads            = "newHeads"
+	SubscriptionPendingTransactions = "pendingTransactions"
+	SubscriptionEvents              = "events"
+	SubscriptionAccount             = "account"
+)
+
+var subscribeMethods = map[string]string{
+	"subscribeNewHeads":            SubscriptionNewHeads,
+	"subscribePendingTransactions": SubscriptionPendingTransactions,
+	"subscribeEvents":              SubscriptionEvents,
+	"subscribeAccount":             SubscriptionAccount,
+}
+
+// SubscriptionNotify is a notification of the subscription
+type SubscriptionNotify struct {
+	JSONRPC string              `json:"jsonrpc"`
+	Method  string              `json:"method"`
+	Params  *SubscriptionResult `json:"params"`
+}
+
+// SubscriptionResult is the result of the subscription notification
+type SubscriptionResult struct {
+	Subscription string      `json:"subscription"`
+	Result       interface{} `json:"result"`
+}
+
+// HeadNotify is the notification of the newHeads subscription
+type HeadNotify struct {
+	Hash    hash.Hash256  `json:"hash"`
+	Height  uint32        `json:"height"`
+	Header  *block.Header `json:"header"`
+	TxCount int           `json:"tx_count"`
+}
+
+// PendingTransactionNotify is the notification of the pendingTransactions subscription
+type PendingTransactionNotify struct {
+	TxHash hash.Hash256            `json:"tx_hash"`
+	Type   string                  `json:"type"`
+	Tx     transaction.Transaction `json:"tx"`
+}
+
+// EventNotifyItem is the notification of the events subscription
+type EventNotifyItem struct {
+	Height uint32      `json:"height"`
+	Type   string      `json:"type"`
+	Event  event.Event `json:"event"`
+}
+
+// AccountNotify is the notification of the account subscription
+// Account is null when the account is deleted
+type AccountNotify struct {
+	Height  uint32      `json:"height"`
+	Address string      `json:"address"`
+	Account interface{} `json:"account"`
+}
+
+type subscription struct {
+	ID        string
+	Kind      string
+	Addresses map[common.Address]bool
+	Types     map[event.Type]bool
+	last      []byte
+}
+
+func (sub *subscription) matchEvent(e event.Event) bool {
+	if len(sub.Types) > 0 && !sub.Types[e.Type()] {
+		return false
+	}
+	if len(sub.Addresses) > 0 {
+		for _, addr := range index.EventAddresses(e) {
+			if sub.Addresses[addr] {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// isChanged returns true when the account is changed since the last notification
+// The account is loaded by the reward of every block, so the loaded account is compared with the last one
+func (sub *subscription) isChanged(noti *AccountNotify) bool {
+	bs, err := json.Marshal(noti.Account)
+	if err != nil {
+		return false
+	}
+	if sub.last != nil && bytes.Equal(sub.last, bs) {
+		return false
+	}
+	sub.last = bs
+	return true
+}
+
+// wsClient is a websocket connection of the jrpc endpoint
+// Every message is written by the write loop through the queue
+type wsClient struct {
+	sync.Mutex
+	conn    *websocket.Conn
+	queue   chan []byte
+	closeCh chan struct{}
+	subs    map[string]*subscription
+	grant   *Grant
+	isClose bool
+}
+
+func newWSClient(conn *websocket.Conn, grant *Grant) *wsClient {
+	return &wsClient{
+		conn:    conn,
+		grant:   grant,
+		queue:   make(chan []byte, NotificationQueueSize),
+		closeCh: make(chan struct{}),
+		subs:    map[string]*subscription{},
+	}
+}
+
+// Close closes the connection and stops the write loop
+func (cl *wsClient) Close() {
+	cl.Lock()
+	defer cl.Unlock()
+
+	if cl.isClose {
+		return
+	}
+	cl.isClose = true
+	close(cl.closeCh)
+	cl.conn.Close()
+}
+
+func (cl *wsClient) writeLoop() {
+	for {
+		select {
+		case <-cl.closeCh:
+			return
+		case data := <-cl.queue:
+			cl.conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+			if err := cl.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				cl.Close()
+				return
+			}
+		}
+	}
+}
+
+// send queues the response and waits for the space of the queue
+func (cl *wsClient) send(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	select {
+	case cl.queue <- data:
+		return nil
+	case <-cl.closeCh:
+		return ErrClosedConnection
+	}
+}
+
+// notify queues the notification without waiting and closes the connection of the slow client
+func (cl *wsClient) notify(data []byte) {
+	select {
+	case cl.queue <- data:
+	default:
+		cl.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "notification queue overflowed"), time.Now().Add(time.Second))
+		cl.Close()
+	}
+}
+
+func (cl *wsClient) subscriptions(Kind string) []*subscription {
+	cl.Lock()
+	defer cl.Unlock()
+
+	subs := []*subscription{}
+	for _, sub := range cl.subs {
+		if sub.Kind == Kind {
+			subs = append(subs, sub)
+		}
+	}
+	return subs
+}
+
+// SetEventer sets the eventer that gives the names of the event types to the events subscription
+func (rm *Manager) SetEventer(evt *data.Eventer) {
+	rm.Lock()
+	defer rm.Unlock()
+
+	rm.eventer = evt
+}
+
+func (rm *Manager) addClient(cl *wsClient) {
+	rm.Lock()
+	defer rm.Unlock()
+
+	rm.clients[cl] = true
+}
+
+func (rm *Manager) removeClient(cl *wsClient) {
+	rm.Lock()
+	defer rm.Unlock()
+
+	delete(rm.clients, cl)
+}
+
+// handleSubscription serves the subscription methods of the connection
+// It returns false when the method is not a subscription method
+func (rm *Manager) handleSubscription(cl *wsClient, req *JRPCRequest) (*JRPCResponse, bool) {
+	var ret interface{}
+	var err error
+	if req.Method == "unsubscribe" {
+		ret, err = rm.unsubscribe(cl, req.Params)
+	} else if Kind, has := subscribeMethods[req.Method]; has {
+		ret, err = rm.subscribe(cl, Kind, req.Params)
+	} else {
+		return nil, false
+	}
+	res := &JRPCResponse{
+		JSONRPC: req.JSONRPC,
+		ID:      req.ID,
+	}
+	if err != nil {
+		res.Error = toJRPCError(err)
+	} else {
+		res.Result = ret
+	}
+	return res, true
+}
+
+func (rm *Manager) subscribe(cl *wsClient, Kind string, params []json.RawMessage) (string, error) {
+	sub := &subscription{
+		Kind: Kind,
+	}
+	switch Kind {
+	case SubscriptionEvents:
+		addrs, err := parseFilterAddresses(params, 0)
+		if err != nil {
+			return "", err
+		}
+		if len(addrs) > MaxFilterAddresses {
+			return "", ErrTooManyFilterAddresses
+		}
+		sub.Addresses = addrs
+		types, err := rm.parseFilterTypes(params, 1)
+		if err != nil {
+			return "", err
+		}
+		sub.Types = types
+	case SubscriptionAccount:
+		addrs, err := parseFilterAddresses(params, 0)
+		if err != nil {
+			return "", err
+		}
+		if len(addrs) != 1 {
+			return "", ErrInvalidSubscriptionFilter
+		}
+		sub.Addresses = addrs
+	}
+
+	cl.Lock()
+	defer cl.Unlock()
+
+	if len(cl.subs) >= MaxSubscriptions {
+		return "", ErrTooManySubscriptions
+	}
+	rm.Lock()
+	rm.subscriptionSeq++
+	sub.ID = "0x" + strconv.FormatUint(rm.subscriptionSeq, 16)
+	rm.Unlock()
+	cl.subs[sub.ID] = sub
+	return sub.ID, nil
+}
+
+func (rm *Manager) unsubscribe(cl *wsClient, params []json.RawMessage) (bool, error) {
+	if len(params) < 1 {
+		return false, ErrNotExistSubscription
+	}
+	var ID string
+	if err := json.Unmarshal(params[0], &ID); err != nil {
+		return false, ErrNotExistSubscription
+	}
+
+	cl.Lock()
+	defer cl.Unlock()
+
+	if _, has := cl.subs[ID]; !has {
+		return false, ErrNotExistSubscription
+	}
+	delete(cl.subs, ID)
+	return true, nil
+}
+
+// parseFilterAddresses parses an address or a list of addresses of the index, and null is an empty filter
+func parseFilterAddresses(params []json.RawMessage, index int) (map[common.Address]bool, error) {
+	addrs := map[common.Address]bool{}
+	if index >= len(params) || strings.TrimSpace(string(params[index])) == "null" {
+		return addrs, nil
+	}
+	var list []string
+	if err := json.Unmarshal(params[index], &list); err != nil {
+		var str string
+		if err := json.Unmarshal(params[index], &str); err != nil {
+			return nil, ErrInvalidSubscriptionFilter
+		}
+		list = []string{str}
+	}
+	for _, v := range list {
+		addr, err := common.ParseAddress(v)
+		if err != nil {
+			return nil, err
+		}
+		addrs[addr] = true
+	}
+	return addrs, nil
+}
+
+// parseFilterTypes parses a list of the event type names of the index, and null is an empty filter
+func (rm *Manager) parseFilterTypes(params []json.RawMessage, index int) (map[event.Type]bool, error) {
+	types := map[event.Type]bool{}
+	if index >= len(params) || strings.TrimSpace(string(params[index])) == "null" {
+		return types, nil
+	}
+	var list []string
+	if err := json.Unmarshal(params[index], &list); err != nil {
+		return nil, ErrInvalidSubscriptionFilter
+	}
+	rm.Lock()
+	evt := rm.eventer
+	rm.Unlock()
+	if evt == nil && len(list) > 0 {
+		return nil, ErrUnknownEventType
+	}
+	for _, name := range list {
+		t, err := evt.TypeByName(name)
+		if err != nil {
+			return nil, ErrUnknownEventType
+		}
+		types[t] = true
+	}
+	return types, nil
+}
+
+func (rm *Manager) clientList() []*wsClient {
+	rm.Lock()
+	defer rm.Unlock()
+
+	clients := make([]*wsClient, 0, len(rm.clients))
+	for cl := range rm.clients {
+		clients = append(clients, cl)
+	}
+	return clients
+}
+
+func (rm *Manager) closeClients() {
+	for _, cl := range rm.clientList() {
+		cl.Close()
+	}
+}
+
+func notifyData(ID string, v interface{}) ([]byte, error) {
+	return json.Marshal(&SubscriptionNotify{
+		JSONRPC: "2.0",
+		Method:  "subscription",
+		Params: &SubscriptionResult{
+			Subscription: ID,
+			Result:       v,
+		},
+	})
+}
+
+func (rm *Manager) notifyBlock(kn *kernel.Kernel, b *block.Block, ctx *data.Context) {
+	clients := rm.clientList()
+	if len(clients) == 0 {
+		return
+	}
+	rm.Lock()
+	evt := rm.eventer
+	rm.Unlock()
+
+	height := b.Header.Height()
+	top := ctx.Top()
+	head := &HeadNotify{
+		Hash:    b.Header.Hash(),
+		Height:  height,
+		Header:  b.Header,
+		TxCount: len(b.Body.Transactions),
+	}
+	events := make([]*EventNotifyItem, 0, len(top.Events))
+	for _, e := range top.Events {
+		item := &EventNotifyItem{
+			Height: height,
+			Event:  e,
+		}
+		if evt != nil {
+			if name, err := evt.NameByType(e.Type()); err == nil {
+				item.Type = name
+			}
+		}
+		events = append(events, item)
+	}
+
+	for _, cl := range clients {
+		for _, sub := range cl.subscriptions(SubscriptionNewHeads) {
+			if data, err := notifyData(sub.ID, head); err == nil {
+				cl.notify(data)
+			}
+		}
+		for _, sub := range cl.subscriptions(SubscriptionEvents) {
+			for _, item := range events {
+				if !sub.matchEvent(item.Event) {
+					continue
+				}
+				if data, err := notifyData(sub.ID, item); err == nil {
+					cl.notify(data)
+				}
+			}
+		}
+		for _, sub := range cl.subscriptions(SubscriptionAccount) {
+			for addr := range sub.Addresses {
+				noti, touched := accountNotify(kn.Loader(), top, height, addr)
+				if !touched || !sub.isChanged(noti) {
+					continue
+				}
+				if data, err := notifyData(sub.ID, noti); err == nil {
+					cl.notify(data)
+				}
+			}
+		}
+	}
+}
+
+// accountNotify returns the notification of the account when the account is touched by the block
+func accountNotify(loader data.Loader, top *data.ContextData, height uint32, addr common.Address) (*AccountNotify, bool) {
+	noti := &AccountNotify{
+		Height:  height,
+		Address: addr.String(),
+	}
+	if _, has := top.DeletedAccountMap[addr]; has {
+		return noti, true
+	}
+	acc, has := top.CreatedAccountMap[addr]
+	if !has {
+		acc, has = top.AccountMap[addr]
+	}
+	if !has {
+		if _, has := top.SeqMap[addr]; !has {
+			return nil, false
+		}
+		var err error
+		if acc, err = loader.Account(addr); err != nil {
+			return nil, false
+		}
+	}
+	view, err := NewAccountView(loader, acc)
+	if err != nil {
+		return nil, false
+	}
+	noti.Account = view
+	return noti, true
+}
+
+func (rm *Manager) notifyTransaction(kn *kernel.Kernel, tx transaction.Transaction) {
+	clients := rm.clientList()
+	if len(clients) == 0 {
+		return
+	}
+	var noti *PendingTransactionNotify
+	for _, cl := range clients {
+		for _, sub := range cl.subscriptions(SubscriptionPendingTransactions) {
+			if noti == nil {
+				name, err := kn.Transactor().NameByType(tx.Type())
+				if err != nil {
+					return
+				}
+				noti = &PendingTransactionNotify{
+					TxHash: tx.Hash(),
+					Type:   name,
+					Tx:     tx,
+				}
+			}
+			if data, err := notifyData(sub.ID, noti); err == nil {
+				cl.notify(data)
+			}
+		}
+	}
+}
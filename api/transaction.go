@@ -0,0 +1,201 @@
+package api
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+
+	"github.com/fletaio/cmd/chain"
+	"github.com/fletaio/common"
+	"github.com/fletaio/common/hash"
+	"github.com/fletaio/common/util"
+	"github.com/fletaio/core/account"
+	"github.com/fletaio/core/amount"
+	"github.com/fletaio/core/consensus"
+	"github.com/fletaio/core/data"
+	"github.com/fletaio/core/kernel"
+	"github.com/fletaio/core/transaction"
+	"github.com/fletaio/core/txpool"
+	"github.com/fletaio/extension/account_def"
+	"github.com/fletaio/extension/account_tx"
+	"github.com/fletaio/extension/utxo_tx"
+)
+
+// rejection reasons of the transaction
+const (
+	RejectInvalidEncoding    = "invalid_encoding"
+	RejectUnknownType        = "unknown_type"
+	RejectBadSignature       = "bad_signature"
+	RejectBadSeq             = "bad_seq"
+	RejectInsufficientFee    = "insufficient_fee"
+	RejectExistTransaction   = "exist_transaction"
+	RejectPoolOverflowed     = "pool_overflowed"
+	RejectInvalidTransaction = "invalid_transaction"
+)
+
+// Rejection is the typed reason of the rejected request
+// It is given as the error object of the response
+type Rejection struct {
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+// Error returns the reason and the message of the rejection
+func (re *Rejection) Error() string {
+	return re.Reason + ": " + re.Message
+}
+
+func reject(Reason string, err error) *Rejection {
+	return &Rejection{
+		Reason:  Reason,
+		Message: err.Error(),
+	}
+}
+
+// Committer adds the transaction to the transaction pool and broadcasts it
+type Committer interface {
+	CommitTransaction(tx transaction.Transaction, sigs []common.Signature) error
+}
+
+// TxSender validates the transactions of the clients and commits them
+type TxSender struct {
+	kn     *kernel.Kernel
+	cm     Committer
+	feeMap map[transaction.Type]*amount.Amount
+}
+
+// NewTxSender returns a TxSender that commits the transactions to the committer
+func NewTxSender(kn *kernel.Kernel, cm Committer, TxFeeTable []*chain.TxFee) *TxSender {
+	feeMap := map[transaction.Type]*amount.Amount{}
+	for _, v := range TxFeeTable {
+		feeMap[v.Type] = v.Fee
+	}
+	return &TxSender{
+		kn:     kn,
+		cm:     cm,
+		feeMap: feeMap,
+	}
+}
+
+// Send decodes the transaction and the signatures, validates them and commits the transaction
+// The transaction is the type byte followed by the serialized transaction, and each value is given as hex or base64
+// It should be called without the lock of the kernel because the kernel acquires it when adding the transaction
+func (ts *TxSender) Send(TxData string, SigData []string) (hash.Hash256, error) {
+	bs, err := DecodeBytes(TxData)
+	if err != nil {
+		return hash.Hash256{}, reject(RejectInvalidEncoding, err)
+	}
+	tx, err := ts.decodeTransaction(bs)
+	if err != nil {
+		return hash.Hash256{}, err
+	}
+	if len(SigData) == 0 {
+		return hash.Hash256{}, reject(RejectBadSignature, ErrEmptySignature)
+	}
+	sigs := make([]common.Signature, 0, len(SigData))
+	for _, v := range SigData {
+		bs, err := DecodeBytes(v)
+		if err != nil {
+			return hash.Hash256{}, reject(RejectInvalidEncoding, err)
+		}
+		var sig common.Signature
+		if len(bs) != len(sig) {
+			return hash.Hash256{}, reject(RejectBadSignature, ErrInvalidSignatureSize)
+		}
+		copy(sig[:], bs)
+		sigs = append(sigs, sig)
+	}
+
+	TxHash := tx.Hash()
+	if ts.kn.HasTransaction(TxHash) {
+		return hash.Hash256{}, reject(RejectExistTransaction, txpool.ErrExistTransaction)
+	}
+	loader := ts.kn.Loader()
+	signers := make([]common.PublicHash, 0, len(sigs))
+	for _, sig := range sigs {
+		pubkey, err := common.RecoverPubkey(TxHash, sig)
+		if err != nil {
+			return hash.Hash256{}, reject(RejectBadSignature, err)
+		}
+		signers = append(signers, common.NewPublicHash(pubkey))
+	}
+	if atx, is := tx.(txpool.AccountTransaction); is {
+		seq := loader.Seq(atx.From())
+		if atx.Seq() <= seq {
+			return hash.Hash256{}, reject(RejectBadSeq, kernel.ErrPastSeq)
+		} else if atx.Seq() > seq+100 {
+			return hash.Hash256{}, reject(RejectBadSeq, kernel.ErrTooFarSeq)
+		}
+		if Fee, has := ts.feeMap[tx.Type()]; has {
+			acc, err := loader.Account(atx.From())
+			if err != nil {
+				return hash.Hash256{}, reject(RejectInvalidTransaction, err)
+			}
+			if acc.Balance().Less(Fee) {
+				return hash.Hash256{}, reject(RejectInsufficientFee, ErrInsufficientFee)
+			}
+		}
+	}
+	if err := loader.Transactor().Validate(loader, tx, signers); err != nil {
+		return hash.Hash256{}, reject(rejectReason(err), err)
+	}
+	if err := ts.cm.CommitTransaction(tx, sigs); err != nil {
+		return hash.Hash256{}, reject(rejectReason(err), err)
+	}
+	return TxHash, nil
+}
+
+func (ts *TxSender) decodeTransaction(bs []byte) (transaction.Transaction, error) {
+	r := bytes.NewReader(bs)
+	t, _, err := util.ReadUint8(r)
+	if err != nil {
+		return nil, reject(RejectInvalidEncoding, err)
+	}
+	tx, err := ts.kn.Transactor().NewByType(transaction.Type(t))
+	if err != nil {
+		return nil, reject(RejectUnknownType, err)
+	}
+	if _, err := tx.ReadFrom(r); err != nil {
+		return nil, reject(RejectInvalidEncoding, err)
+	}
+	if r.Len() > 0 {
+		return nil, reject(RejectInvalidEncoding, ErrTrailingBytes)
+	}
+	return tx, nil
+}
+
+func rejectReason(err error) string {
+	switch err {
+	case account_def.ErrInvalidSignerCount, account_def.ErrInvalidAccountSigner,
+		consensus.ErrInvalidSignerCount, consensus.ErrInvalidAccountSigner,
+		account_tx.ErrInvalidTransactionSignature,
+		utxo_tx.ErrInvalidSignerCount, utxo_tx.ErrInvalidTransactionSignature:
+		return RejectBadSignature
+	case account_tx.ErrInvalidSequence, kernel.ErrPastSeq, kernel.ErrTooFarSeq:
+		return RejectBadSeq
+	case account.ErrInsufficientBalance, account_tx.ErrInsuffcientBalance:
+		return RejectInsufficientFee
+	case txpool.ErrExistTransaction, kernel.ErrProcessingTransaction:
+		return RejectExistTransaction
+	case kernel.ErrTxQueueOverflowed:
+		return RejectPoolOverflowed
+	case data.ErrNotExistHandler:
+		return RejectUnknownType
+	default:
+		return RejectInvalidTransaction
+	}
+}
+
+// DecodeBytes decodes the hex or base64 string
+// The string is decoded as hex when it is a valid hex string with or without 0x
+func DecodeBytes(str string) ([]byte, error) {
+	if bs, err := hex.DecodeString(strings.TrimPrefix(str, "0x")); err == nil {
+		return bs, nil
+	}
+	bs, err := base64.StdEncoding.DecodeString(str)
+	if err != nil {
+		return nil, ErrInvalidEncoding
+	}
+	return bs, nil
+}
@@ -0,0 +1,76 @@
+package chain
+
+import (
+	"github.com/fletaio/common"
+	"github.com/fletaio/core/data"
+	"github.com/fletaio/core/kernel"
+	"github.com/fletaio/core/reward"
+)
+
+// kernel limits of the FLETA chain
+const (
+	MaxBlocksPerFormulator  = 8
+	MaxTransactionsPerBlock = 5000
+)
+
+// Bootstrap is the chain components and the genesis context data that every daemon builds from the genesis
+// The daemons of the same genesis and the same reward policy make the same genesis hash by it
+type Bootstrap struct {
+	ChainCoord         *common.Coordinate
+	TxFeeTable         []*TxFee
+	RewardSchedule     *RewardSchedule
+	Accounter          *data.Accounter
+	Transactor         *data.Transactor
+	Eventer            *data.Eventer
+	GenesisContextData *data.ContextData
+}
+
+// NewBootstrap registers the chain components and builds the genesis context data of the genesis and the reward policy
+func NewBootstrap(gen *Genesis, rp *RewardPolicy) (*Bootstrap, error) {
+	TxFeeTable, err := gen.TxFeeTable()
+	if err != nil {
+		return nil, err
+	}
+	RewardSchedule, err := gen.RewardSchedule(rp)
+	if err != nil {
+		return nil, err
+	}
+
+	GenCoord := common.NewCoordinate(0, 0)
+	act := data.NewAccounter(GenCoord)
+	tran := data.NewTransactor(GenCoord)
+	evt := data.NewEventer(GenCoord)
+	if err := InitChainComponent(act, tran, evt, TxFeeTable); err != nil {
+		return nil, err
+	}
+	GenesisContextData, err := InitGenesisContextData(act, tran, evt, gen)
+	if err != nil {
+		return nil, err
+	}
+	CommitRewardSchedule(GenesisContextData, RewardSchedule)
+
+	return &Bootstrap{
+		ChainCoord:         GenCoord,
+		TxFeeTable:         TxFeeTable,
+		RewardSchedule:     RewardSchedule,
+		Accounter:          act,
+		Transactor:         tran,
+		Eventer:            evt,
+		GenesisContextData: GenesisContextData,
+	}, nil
+}
+
+// OpenStore opens the kernel store of the chain components
+func (bs *Bootstrap) OpenStore(sc *StoreConfig) (*kernel.Store, error) {
+	return OpenStore(sc, bs.Accounter, bs.Transactor, bs.Eventer)
+}
+
+// NewKernel returns the kernel of the store that stores the genesis or checks the genesis hash of it
+func (bs *Bootstrap) NewKernel(ks *kernel.Store, rd reward.Rewarder, ObserverKeyMap map[common.PublicHash]bool) (*kernel.Kernel, error) {
+	return kernel.NewKernel(&kernel.Config{
+		ChainCoord:              bs.ChainCoord,
+		ObserverKeyMap:          ObserverKeyMap,
+		MaxBlocksPerFormulator:  MaxBlocksPerFormulator,
+		MaxTransactionsPerBlock: MaxTransactionsPerBlock,
+	}, ks, rd, bs.GenesisContextData)
+}
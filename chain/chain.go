@@ -0,0 +1,110 @@
+package chain
+
+import (
+	"github.com/fletaio/cmd/logging"
+	"github.com/fletaio/core/account"
+	"github.com/fletaio/core/amount"
+	"github.com/fletaio/core/data"
+	"github.com/fletaio/core/transaction"
+
+	_ "github.com/fletaio/extension/account_tx"
+	_ "github.com/fletaio/extension/utxo_tx"
+	_ "github.com/fletaio/solidity"
+)
+
+var chainLog = logging.Get(logging.Main)
+
+// consts
+const (
+	BlockchainVersion = 1
+)
+
+// TxFee is the type number and the fee of a transaction
+type TxFee struct {
+	Name string           `json:"name"`
+	Type transaction.Type `json:"type"`
+	Fee  *amount.Amount   `json:"fee"`
+}
+
+// DefaultTxFeeTable returns the transaction fee table of the FLETA Beta Testnet
+func DefaultTxFeeTable() []*TxFee {
+	// transaction_type transaction types
+	const (
+		// FLETA Transactions
+		TransferTransctionType              = transaction.Type(10)
+		WithdrawTransctionType              = transaction.Type(18)
+		BurnTransctionType                  = transaction.Type(19)
+		CreateAccountTransctionType         = transaction.Type(20)
+		CreateMultiSigAccountTransctionType = transaction.Type(21)
+		// UTXO Transactions
+		AssignTransctionType      = transaction.Type(30)
+		DepositTransctionType     = transaction.Type(38)
+		OpenAccountTransctionType = transaction.Type(41)
+		// Formulation Transactions
+		CreateFormulationTransctionType = transaction.Type(60)
+		SigmaFormulationTransctionType  = transaction.Type(61)
+		OmegaFormulationTransctionType  = transaction.Type(62)
+		RevokeFormulationTransctionType = transaction.Type(64)
+		StakingTransctionType           = transaction.Type(68)
+		UnstakingTransctionType         = transaction.Type(69)
+		// Solidity Transactions
+		SolidityCreateContractType = transaction.Type(70)
+		SolidityCallContractType   = transaction.Type(71)
+	)
+
+	return []*TxFee{
+		&TxFee{"fleta.CreateAccount", CreateAccountTransctionType, amount.COIN.MulC(10)},
+		&TxFee{"fleta.CreateMultiSigAccount", CreateMultiSigAccountTransctionType, amount.COIN.MulC(10)},
+		&TxFee{"fleta.Transfer", TransferTransctionType, amount.COIN.DivC(10)},
+		&TxFee{"fleta.Withdraw", WithdrawTransctionType, amount.COIN.DivC(10)},
+		&TxFee{"fleta.Burn", BurnTransctionType, amount.COIN.DivC(10)},
+		&TxFee{"fleta.Assign", AssignTransctionType, amount.COIN.DivC(2)},
+		&TxFee{"fleta.Deposit", DepositTransctionType, amount.COIN.DivC(2)},
+		&TxFee{"fleta.OpenAccount", OpenAccountTransctionType, amount.COIN.MulC(10)},
+		&TxFee{"consensus.CreateFormulation", CreateFormulationTransctionType, amount.COIN.DivC(10)},
+		&TxFee{"consensus.SigmaFormulation", SigmaFormulationTransctionType, amount.COIN.DivC(10)},
+		&TxFee{"consensus.OmegaFormulation", OmegaFormulationTransctionType, amount.COIN.DivC(10)},
+		&TxFee{"consensus.RevokeFormulation", RevokeFormulationTransctionType, amount.COIN.DivC(10)},
+		&TxFee{"consensus.Staking", StakingTransctionType, amount.COIN.DivC(10)},
+		&TxFee{"consensus.Unstaking", UnstakingTransctionType, amount.COIN.DivC(10)},
+		&TxFee{"solidity.CreateContract", SolidityCreateContractType, amount.COIN.MulC(10)},
+		&TxFee{"solidity.CallContract", SolidityCallContractType, amount.COIN.DivC(10)},
+	}
+}
+
+// InitChainComponent registers transaction and account types of the FLETA chain
+func InitChainComponent(act *data.Accounter, tran *data.Transactor, evt *data.Eventer, TxFeeTable []*TxFee) error {
+	// account_type account types
+	const (
+		// FLTEA Accounts
+		SingleAccountType   = account.Type(10)
+		MultiSigAccountType = account.Type(11)
+		LockedAccountType   = account.Type(19)
+		// Formulation Accounts
+		FormulationAccountType = account.Type(60)
+		// Solidity Accounts
+		SolidityAccount = account.Type(70)
+	)
+
+	for _, item := range TxFeeTable {
+		if err := tran.RegisterType(item.Name, item.Type, item.Fee); err != nil {
+			chainLog.Error("failed to register the transaction type", "name", item.Name, "type", item.Type, "error", err)
+			return err
+		}
+	}
+
+	AccTable := map[string]account.Type{
+		"fleta.SingleAccount":          SingleAccountType,
+		"fleta.MultiSigAccount":        MultiSigAccountType,
+		"fleta.LockedAccount":          LockedAccountType,
+		"consensus.FormulationAccount": FormulationAccountType,
+		"solidity.ContractAccount":     SolidityAccount,
+	}
+	for name, t := range AccTable {
+		if err := act.RegisterType(name, t); err != nil {
+			chainLog.Error("failed to register the account type", "name", name, "type", t, "error", err)
+			return err
+		}
+	}
+	return nil
+}
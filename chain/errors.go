@@ -0,0 +1,40 @@
+package chain
+
+import (
+	"errors"
+)
+
+// genesis errors
+var (
+	ErrEmptyGenesis              = errors.New("empty genesis")
+	ErrNotExistGenesisFormulator = errors.New("not exist genesis formulator")
+	ErrInvalidGenesisAccountType = errors.New("invalid genesis account type")
+	ErrInvalidGenesisName        = errors.New("invalid genesis name")
+	ErrDuplicateGenesisAddress   = errors.New("duplicate genesis address")
+	ErrDuplicateGenesisName      = errors.New("duplicate genesis name")
+	ErrNotExistHyperPolicy       = errors.New("not exist hyper policy")
+	ErrNotAllowedHyperPolicy     = errors.New("not allowed hyper policy")
+	ErrInvalidCommissionRatio    = errors.New("invalid commission ratio")
+	ErrInvalidTxFeeName          = errors.New("invalid tx fee name")
+	ErrDuplicateTxFeeName        = errors.New("duplicate tx fee name")
+	ErrDuplicateTxFeeType        = errors.New("duplicate tx fee type")
+	ErrNotExistTxFeeType         = errors.New("not exist tx fee type")
+	ErrInvalidConsensusPolicy    = errors.New("invalid consensus policy")
+)
+
+// store errors
+var (
+	ErrInvalidRecoveryPolicy = errors.New("invalid recovery policy")
+	ErrNotExistSnapshotPath  = errors.New("not exist snapshot path")
+	ErrNotTerminal           = errors.New("stdin is not a terminal")
+	ErrCanceledRecovery      = errors.New("canceled recovery")
+)
+
+// reward errors
+var (
+	ErrUnknownRewardMode   = errors.New("unknown reward mode")
+	ErrUnknownRewardCurve  = errors.New("unknown reward curve")
+	ErrNotCustomReward     = errors.New("only given by the custom reward mode")
+	ErrInvalidRewardAmount = errors.New("invalid reward amount")
+	ErrInvalidRewardBlocks = errors.New("invalid reward blocks")
+)
@@ -0,0 +1,73 @@
+package chain
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/fletaio/core/amount"
+	"github.com/fletaio/core/transaction"
+)
+
+// GenesisTxFee defines the fee of a transaction of the genesis
+// Type can be omitted for the transaction of the default fee table
+type GenesisTxFee struct {
+	Name string
+	Type uint8
+	Fee  string
+}
+
+// TxFeeTable returns the transaction fee table of the genesis or the default one when it is not defined
+func (gen *Genesis) TxFeeTable() ([]*TxFee, error) {
+	if len(gen.TxFees) == 0 {
+		return DefaultTxFeeTable(), nil
+	}
+	DefaultTypeMap := map[string]transaction.Type{}
+	for _, item := range DefaultTxFeeTable() {
+		DefaultTypeMap[item.Name] = item.Type
+	}
+	NameMap := map[string]bool{}
+	TypeMap := map[transaction.Type]string{}
+	TxFeeTable := make([]*TxFee, 0, len(gen.TxFees))
+	for i, gf := range gen.TxFees {
+		if len(gf.Name) == 0 {
+			return nil, fmt.Errorf("genesis tx fee %d: %v", i, ErrInvalidTxFeeName)
+		}
+		if NameMap[gf.Name] {
+			return nil, fmt.Errorf("genesis tx fee %d (%s): %v", i, gf.Name, ErrDuplicateTxFeeName)
+		}
+		NameMap[gf.Name] = true
+
+		t := transaction.Type(gf.Type)
+		if t == 0 {
+			dt, has := DefaultTypeMap[gf.Name]
+			if !has {
+				return nil, fmt.Errorf("genesis tx fee %d (%s): %v", i, gf.Name, ErrNotExistTxFeeType)
+			}
+			t = dt
+		}
+		if Name, has := TypeMap[t]; has {
+			return nil, fmt.Errorf("genesis tx fee %d (%s): %v %d of %s", i, gf.Name, ErrDuplicateTxFeeType, t, Name)
+		}
+		TypeMap[t] = gf.Name
+
+		Fee, err := amount.ParseAmount(gf.Fee)
+		if err != nil {
+			return nil, fmt.Errorf("genesis tx fee %d (%s): invalid fee %q: %v", i, gf.Name, gf.Fee, err)
+		}
+		TxFeeTable = append(TxFeeTable, &TxFee{
+			Name: gf.Name,
+			Type: t,
+			Fee:  Fee,
+		})
+	}
+	return TxFeeTable, nil
+}
+
+func (gf *GenesisTxFee) writeTo(buffer *bytes.Buffer) {
+	buffer.WriteString("[[TxFees]]\n")
+	fmt.Fprintf(buffer, "Name = %q\n", gf.Name)
+	if gf.Type != 0 {
+		fmt.Fprintf(buffer, "Type = %d\n", gf.Type)
+	}
+	fmt.Fprintf(buffer, "Fee = %q\n", gf.Fee)
+}
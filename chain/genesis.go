@@ -0,0 +1,373 @@
+package chain
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/fletaio/common"
+	"github.com/fletaio/core/amount"
+	"github.com/fletaio/core/consensus"
+	"github.com/fletaio/core/data"
+	"github.com/fletaio/extension/account_def"
+	"github.com/fletaio/framework/config"
+)
+
+// genesis account types
+const (
+	GenesisSingleAccount   = "SingleAccount"
+	GenesisAlphaFormulator = "AlphaFormulator"
+	GenesisHyperFormulator = "HyperFormulator"
+)
+
+// Genesis defines the initial accounts and formulators of the chain
+// The accounts are parsed once when it is used, so the genesis should not be changed after that
+type Genesis struct {
+	ConsensusPolicy *GenesisConsensusPolicy
+	TxFees          []*GenesisTxFee
+	Accounts        []*GenesisAccount
+	accs            []*genesisAccount
+}
+
+// GenesisAccount defines an account of the genesis
+type GenesisAccount struct {
+	Type        string
+	Address     string
+	KeyHash     string
+	Name        string
+	Balance     string
+	HyperPolicy *GenesisHyperPolicy
+}
+
+// GenesisHyperPolicy defines the policy of a hyper formulator of the genesis
+type GenesisHyperPolicy struct {
+	CommissionRatio1000 uint32
+	MinimumStaking      string
+	MaximumStaking      string
+}
+
+// LoadGenesis parse the genesis from the file of the path
+// It returns the default genesis when the path is empty
+func LoadGenesis(path string) (*Genesis, error) {
+	if len(path) == 0 {
+		return DefaultGenesis(), nil
+	}
+	gen := &Genesis{}
+	if err := config.LoadFile(path, gen); err != nil {
+		return nil, err
+	}
+	if err := gen.Validate(); err != nil {
+		return nil, err
+	}
+	return gen, nil
+}
+
+// SaveGenesis writes the genesis to the file of the path
+func SaveGenesis(path string, gen *Genesis) error {
+	if err := gen.Validate(); err != nil {
+		return err
+	}
+	var buffer bytes.Buffer
+	if gen.ConsensusPolicy != nil {
+		gen.ConsensusPolicy.writeTo(&buffer)
+	}
+	for _, gf := range gen.TxFees {
+		if buffer.Len() > 0 {
+			buffer.WriteString("\n")
+		}
+		gf.writeTo(&buffer)
+	}
+	for _, ga := range gen.Accounts {
+		if buffer.Len() > 0 {
+			buffer.WriteString("\n")
+		}
+		buffer.WriteString("[[Accounts]]\n")
+		fmt.Fprintf(&buffer, "Type = %q\n", ga.Type)
+		fmt.Fprintf(&buffer, "Address = %q\n", ga.Address)
+		fmt.Fprintf(&buffer, "KeyHash = %q\n", ga.KeyHash)
+		fmt.Fprintf(&buffer, "Name = %q\n", ga.Name)
+		if len(ga.Balance) > 0 {
+			fmt.Fprintf(&buffer, "Balance = %q\n", ga.Balance)
+		}
+		if ga.HyperPolicy != nil {
+			buffer.WriteString("[Accounts.HyperPolicy]\n")
+			fmt.Fprintf(&buffer, "CommissionRatio1000 = %d\n", ga.HyperPolicy.CommissionRatio1000)
+			fmt.Fprintf(&buffer, "MinimumStaking = %q\n", ga.HyperPolicy.MinimumStaking)
+			fmt.Fprintf(&buffer, "MaximumStaking = %q\n", ga.HyperPolicy.MaximumStaking)
+		}
+	}
+	return ioutil.WriteFile(path, buffer.Bytes(), 0644)
+}
+
+// Validate checks that the genesis can be applied to the chain or not
+func (gen *Genesis) Validate() error {
+	if _, err := gen.consensusPolicy(); err != nil {
+		return err
+	}
+	if _, err := gen.TxFeeTable(); err != nil {
+		return err
+	}
+	if _, err := gen.parse(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// consensusPolicy returns the consensus policy of the genesis or the default one when it is not defined
+func (gen *Genesis) consensusPolicy() (*consensus.ConsensusPolicy, error) {
+	gp := gen.ConsensusPolicy
+	if gp == nil {
+		gp = DefaultConsensusPolicy()
+	}
+	policy, err := gp.parse()
+	if err != nil {
+		return nil, fmt.Errorf("genesis consensus policy: %v", err)
+	}
+	return policy, nil
+}
+
+type genesisAccount struct {
+	Type        string
+	Address     common.Address
+	KeyHash     common.PublicHash
+	Name        string
+	Balance     *amount.Amount
+	HyperPolicy *consensus.HyperPolicy
+}
+
+func (gen *Genesis) parse() ([]*genesisAccount, error) {
+	if gen.accs != nil {
+		return gen.accs, nil
+	}
+	if len(gen.Accounts) == 0 {
+		return nil, ErrEmptyGenesis
+	}
+	accs := make([]*genesisAccount, 0, len(gen.Accounts))
+	addrMap := map[common.Address]bool{}
+	nameMap := map[string]bool{}
+	hasFormulator := false
+	for i, ga := range gen.Accounts {
+		acc, err := ga.parse()
+		if err != nil {
+			return nil, fmt.Errorf("genesis account %d (%s): %v", i, ga.Name, err)
+		}
+		if addrMap[acc.Address] {
+			return nil, fmt.Errorf("genesis account %d (%s): %v %s", i, ga.Name, ErrDuplicateGenesisAddress, ga.Address)
+		}
+		addrMap[acc.Address] = true
+		if nameMap[acc.Name] {
+			return nil, fmt.Errorf("genesis account %d (%s): %v", i, ga.Name, ErrDuplicateGenesisName)
+		}
+		nameMap[acc.Name] = true
+		if acc.Type != GenesisSingleAccount {
+			hasFormulator = true
+		}
+		accs = append(accs, acc)
+	}
+	if !hasFormulator {
+		return nil, ErrNotExistGenesisFormulator
+	}
+	gen.accs = accs
+	return accs, nil
+}
+
+func (ga *GenesisAccount) parse() (*genesisAccount, error) {
+	switch ga.Type {
+	case GenesisSingleAccount, GenesisAlphaFormulator, GenesisHyperFormulator:
+	default:
+		return nil, fmt.Errorf("%v %q", ErrInvalidGenesisAccountType, ga.Type)
+	}
+	if len(ga.Name) == 0 {
+		return nil, ErrInvalidGenesisName
+	}
+	addr, err := common.ParseAddress(ga.Address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %q: %v", ga.Address, err)
+	}
+	KeyHash, err := ParsePublicHash(ga.KeyHash)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key hash %q: %v", ga.KeyHash, err)
+	}
+	Balance := amount.NewCoinAmount(0, 0)
+	if len(ga.Balance) > 0 {
+		am, err := amount.ParseAmount(ga.Balance)
+		if err != nil {
+			return nil, fmt.Errorf("invalid balance %q: %v", ga.Balance, err)
+		}
+		Balance = am
+	}
+	acc := &genesisAccount{
+		Type:    ga.Type,
+		Address: addr,
+		KeyHash: KeyHash,
+		Name:    ga.Name,
+		Balance: Balance,
+	}
+	if ga.Type == GenesisHyperFormulator {
+		if ga.HyperPolicy == nil {
+			return nil, ErrNotExistHyperPolicy
+		}
+		policy, err := ga.HyperPolicy.parse()
+		if err != nil {
+			return nil, err
+		}
+		acc.HyperPolicy = policy
+	} else if ga.HyperPolicy != nil {
+		return nil, ErrNotAllowedHyperPolicy
+	}
+	return acc, nil
+}
+
+func (gp *GenesisHyperPolicy) parse() (*consensus.HyperPolicy, error) {
+	if gp.CommissionRatio1000 > 1000 {
+		return nil, ErrInvalidCommissionRatio
+	}
+	policy := &consensus.HyperPolicy{
+		CommissionRatio1000: gp.CommissionRatio1000,
+		MinimumStaking:      amount.NewCoinAmount(0, 0),
+		MaximumStaking:      amount.NewCoinAmount(0, 0),
+	}
+	if len(gp.MinimumStaking) > 0 {
+		am, err := amount.ParseAmount(gp.MinimumStaking)
+		if err != nil {
+			return nil, fmt.Errorf("invalid minimum staking %q: %v", gp.MinimumStaking, err)
+		}
+		policy.MinimumStaking = am
+	}
+	if len(gp.MaximumStaking) > 0 {
+		am, err := amount.ParseAmount(gp.MaximumStaking)
+		if err != nil {
+			return nil, fmt.Errorf("invalid maximum staking %q: %v", gp.MaximumStaking, err)
+		}
+		policy.MaximumStaking = am
+	}
+	return policy, nil
+}
+
+// FormulatorKeyHash returns the key hash of the genesis formulator of the address
+// It returns ErrNotExistGenesisFormulator when the address is not a formulator of the genesis
+func (gen *Genesis) FormulatorKeyHash(addr common.Address) (common.PublicHash, error) {
+	accs, err := gen.parse()
+	if err != nil {
+		return common.PublicHash{}, err
+	}
+	for _, acc := range accs {
+		if acc.Address == addr && acc.Type != GenesisSingleAccount {
+			return acc.KeyHash, nil
+		}
+	}
+	return common.PublicHash{}, ErrNotExistGenesisFormulator
+}
+
+// NameMap returns the addresses of the genesis accounts by the name
+// The store of the core does not index the names of the genesis accounts, so it is used to find them by the name
+func (gen *Genesis) NameMap() (map[string]common.Address, error) {
+	accs, err := gen.parse()
+	if err != nil {
+		return nil, err
+	}
+	NameMap := map[string]common.Address{}
+	for _, acc := range accs {
+		NameMap[acc.Name] = acc.Address
+	}
+	return NameMap, nil
+}
+
+// ParsePublicHash parses the public hash and rejects the truncated or overflowed base58 string that common.ParsePublicHash accepts
+func ParsePublicHash(str string) (common.PublicHash, error) {
+	pubhash, err := common.ParsePublicHash(str)
+	if err != nil {
+		return common.PublicHash{}, err
+	}
+	if pubhash.String() != str || len(bytes.TrimRight(pubhash[:], "\x00")) < common.PublicHashSize-4 {
+		return common.PublicHash{}, common.ErrInvalidPublicHashFormat
+	}
+	return pubhash, nil
+}
+
+// InitGenesisContextData returns the genesis context data of the FLETA chain
+func InitGenesisContextData(act *data.Accounter, tran *data.Transactor, evt *data.Eventer, gen *Genesis) (*data.ContextData, error) {
+	policy, err := gen.consensusPolicy()
+	if err != nil {
+		return nil, err
+	}
+	consensus.SetConsensusPolicy(act.ChainCoord(), policy)
+
+	accs, err := gen.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	loader := data.NewEmptyLoader(act.ChainCoord(), act, tran, evt)
+	ctd := data.NewContextData(loader, nil)
+	for _, acc := range accs {
+		switch acc.Type {
+		case GenesisSingleAccount:
+			err = addSingleAccount(loader, ctd, acc)
+		case GenesisAlphaFormulator:
+			err = addFormulator(loader, ctd, acc)
+		case GenesisHyperFormulator:
+			err = addHyperFormulator(loader, ctd, acc)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ctd, nil
+}
+
+func addSingleAccount(loader data.Loader, ctd *data.ContextData, ga *genesisAccount) error {
+	a, err := loader.Accounter().NewByTypeName("fleta.SingleAccount")
+	if err != nil {
+		return err
+	}
+	acc := a.(*account_def.SingleAccount)
+	acc.Address_ = ga.Address
+	acc.Name_ = ga.Name
+	acc.Balance_ = ga.Balance
+	acc.KeyHash = ga.KeyHash
+	ctd.CreatedAccountMap[acc.Address_] = acc
+	return nil
+}
+
+func addFormulator(loader data.Loader, ctd *data.ContextData, ga *genesisAccount) error {
+	policy, err := consensus.GetConsensusPolicy(loader.ChainCoord())
+	if err != nil {
+		return err
+	}
+	a, err := loader.Accounter().NewByTypeName("consensus.FormulationAccount")
+	if err != nil {
+		return err
+	}
+	acc := a.(*consensus.FormulationAccount)
+	acc.Address_ = ga.Address
+	acc.Name_ = ga.Name
+	acc.Balance_ = ga.Balance
+	acc.FormulationType = consensus.AlphaFormulatorType
+	acc.Amount = policy.AlphaFormulationAmount
+	acc.KeyHash = ga.KeyHash
+	ctd.CreatedAccountMap[acc.Address_] = acc
+	return nil
+}
+
+func addHyperFormulator(loader data.Loader, ctd *data.ContextData, ga *genesisAccount) error {
+	policy, err := consensus.GetConsensusPolicy(loader.ChainCoord())
+	if err != nil {
+		return err
+	}
+	a, err := loader.Accounter().NewByTypeName("consensus.FormulationAccount")
+	if err != nil {
+		return err
+	}
+	acc := a.(*consensus.FormulationAccount)
+	acc.Address_ = ga.Address
+	acc.Name_ = ga.Name
+	acc.Balance_ = ga.Balance
+	acc.FormulationType = consensus.HyperFormulatorType
+	acc.Amount = policy.HyperFormulationAmount
+	acc.KeyHash = ga.KeyHash
+	acc.Policy = ga.HyperPolicy
+	acc.StakingAmount = amount.NewCoinAmount(0, 0)
+	ctd.CreatedAccountMap[acc.Address_] = acc
+	return nil
+}
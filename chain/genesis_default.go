@@ -0,0 +1,94 @@
+package chain
+
+import (
+	"strconv"
+
+	"github.com/fletaio/common"
+)
+
+// DefaultGenesis returns the genesis of the FLETA Beta Testnet
+func DefaultGenesis() *Genesis {
+	gen := &Genesis{
+		ConsensusPolicy: DefaultConsensusPolicy(),
+	}
+
+	acg := &AccCoordGenerator{}
+	gen.addSingleAccount("3Zmc4bGPP7TuMYxZZdUhA9kVjukdsE2S8Xpbj4Laovv", common.NewAddress(acg.Generate(), 0).String(), "fleta.io")
+	gen.addFormulator("gDGAcf9V9i8oWLTeayoKC8bdAooNVaFnAeQKy4CsUB", "3CUsUpvEK", "fleta.io.fr00001")
+	gen.addFormulator("4m6XsJbq6EFb5bqhZuKFc99SmF86ymcLcRPwrWyToHQ", "5PxjxeqTd", "fleta.io.fr00002")
+	gen.addFormulator("o1rVoXHFuz5EtwLwCLcrmHpqPdugAnWHEVVMtnCb32", "7bScSUkgw", "fleta.io.fr00003")
+	gen.addFormulator("47NZ8oadY4dCAM3ZrGFrENPn99L1SLSqzpR4DFPUpk5", "9nvUvJfvF", "fleta.io.fr00004")
+	gen.addFormulator("4TaHVFSzcrNPktRiNdpPitoUgLXtZzrVmkxE3GmcYjG", "BzQMQ8b9Z", "fleta.io.fr00005")
+	gen.addFormulator("2wqsb4J47T4JkNUp1Bma1HkjpCyei7sZinLmNprpdtY", "EBtDsxWNs", "fleta.io.fr00006")
+	gen.addFormulator("2a1CirwCHSYYpLqpbi1b7Rpr4BAJZvydbDA1bGjJ7FG", "GPN6MnRcB", "fleta.io.fr00007")
+	gen.addFormulator("2KnMHH973ZLicENxcsJbARdeTUiYZmN3WnBzbZqvvEx", "JaqxqcLqV", "fleta.io.fr00008")
+	gen.addFormulator("4fyTmraz8x3NKWnj4nWgPWKy8qCBF1hyqVJQeyupHAe", "LnKqKSG4o", "fleta.io.fr00009")
+	gen.addFormulator("2V1zboMnJbJdeLvRBRFVPvVqs8CCmjxToBpGJSNScu2", "NyohoGBJ7", "fleta.io.fr00010")
+	gen.addFormulator("3pEYkEgXoPUm4vdcGBXP46q1BpMj215uVQdAg6P4g74", "RBHaH66XR", "fleta.io.fr00011")
+	gen.addFormulator("rsUoPRfVgXJFuV6wYcy4M4kntvr3tooeXzcRhrjBq6", "TNmSkv1kj", "fleta.io.fr00012")
+	gen.addFormulator("4UMYzaBeXEKcm6hnDDEMqYRR5NLwGndCLksryVj98Fw", "VaFKEjvz3", "fleta.io.fr00013")
+	gen.addFormulator("3h2Lt2uYFMqVQKFgKszLJzwaLhQ5kt1nMcg8M758aLh", "XmjBiZrDM", "fleta.io.fr00014")
+	gen.addFormulator("4NkvvfPdHHvpo9YTkAQBrGxpnnML2pVRXHdLgzB2EYe", "ZyD4CPmSf", "fleta.io.fr00015")
+	gen.addFormulator("3ae9sCuM75vAheVLNp3DjQqDiD3TaxY5HYduHvsgzYZ", "cAgvgDgfy", "fleta.io.fr00016")
+	gen.addFormulator("2bR5L2ZSqKLUFQzdhzWV6e4BUupHPGDFtnZUNrZBZbZ", "eNAoA3buH", "fleta.io.fr00017")
+	gen.addFormulator("BPqzvcrYi364mm6GyraHHqJHrvEfqjwo1jEC8crTxZ", "gZefdsX8b", "fleta.io.fr00018")
+	gen.addFormulator("2vtYXNUAtBtt4fF6DEbVKNc7bGhA7yBbatTA6Ye9kMT", "im8Y7hSMu", "fleta.io.fr00019")
+	gen.addFormulator("42TUBLNb1natk7s7qsHNqxHwn7Pb3pNmTfTnd1sDQnb", "kxcQbXMbD", "fleta.io.fr00020")
+	gen.addFormulator("2yng1DwwBqMixjCnjx6Pdf9o5AkgEzkumxJySr8Qe6C", "oA6H5MGpX", "fleta.io.fr00021")
+	gen.addFormulator("3PNrAwb7FrvKeB1hCxYADwNxqWuYmaqoc8E8VjdBC", "qMa9ZBC3q", "fleta.io.fr00022")
+	gen.addFormulator("2eZAofvjk5AHUpaUyC7EDx3K8KAHUQNXMynHG7ZYFfn", "sZ42317H9", "fleta.io.fr00023")
+	gen.addFormulator("4QT4FGpoaFkPiRaZQCKDfrANWJ6EAqavqkQfGr6g4oG", "ukXtWq2WT", "fleta.io.fr00024")
+	gen.addFormulator("2nPZHDpFavW2VjnZGs7ZeQyFM19y517ZTQaTgqe3G69", "wx1kzewjm", "fleta.io.fr00025")
+	gen.addFormulator("bB88uMhpM4vjUHpV5WZqfQBh4kyi6wnnKCtVF4AE2D", "z9VdUUry5", "fleta.io.fr00026")
+	gen.addFormulator("2ZLEXwQ9pqvaATFttkkNWY2CGDHdJFa5V3GNapKeqtx", "22LyVxJnCP", "fleta.io.fr00027")
+	gen.addFormulator("4M2KFgmWSKu8JyjhkmVJ8U4hjtn9MX4rsch4ZoE1i32", "24YTNS8hRh", "fleta.io.fr00028")
+	gen.addFormulator("XG9nFJsdMpo6D6wYxYSyH5zAtnvsMjySFHp1XjCouY", "26jwEuxcf1", "fleta.io.fr00029")
+	gen.addFormulator("3uW4bb1kAx35ndj4ZVLMF8xWYercS2RfP7moxZvUm8Y", "28wR7PnXtK", "fleta.io.fr00030")
+	gen.addFormulator("4mY5G1BZuZaeHR5cH1K4sUNmccPa11JkHtjv5ctde3K", "2B8tyscT7d", "fleta.io.fr00031")
+	gen.addFormulator("3oocpeXtqUZeaut1A71fbCMBQefMFMCBt2BpamNZfA9", "2DLNrMSNLw", "fleta.io.fr00032")
+	gen.addFormulator("4wknRQ86rTcN1cQbXZfbCMkqXcS1FsYG8ihAYFhmxF", "2FXriqGHaF", "fleta.io.fr00033")
+	gen.addFormulator("3mT9SNvGscpwmDjHnojnVysd9pXUvg1fenVyiBFYTDs", "2HjLbK6CoZ", "fleta.io.fr00034")
+	gen.addFormulator("24zn1BgQBmMD8dWap9XbBHdZAivDppVhnYxzZ4ftZw4", "2KvpTnv82s", "fleta.io.fr00035")
+	gen.addFormulator("4TKCbNqM68vKmmXiMsjdb7qND8Qy1DCJKvFge7Dhw16", "2N8JLGk3GB", "fleta.io.fr00036")
+	for i := 0; i < 36; i++ {
+		acg.Generate()
+	}
+	tempAddr := common.NewAddress(acg.Generate(), 0) //2QKnCkZxVV
+	gen.addSingleAccount("41Xu1uAED3a28bo5VkbgrATCVmLXvxVwi6jQ7NkuA2p", tempAddr.String(), "testcreatoraccount")
+	for i := 0; i < 20000; i++ {
+		addr := common.NewAddress(acg.Generate(), 0)
+		gen.addSingleAccount("3Zmc4bGPP7TuMYxZZdUhA9kVjukdsE2S8Xpbj4Laovv", addr.String(), "testaccount"+strconv.Itoa(i))
+	}
+	return gen
+}
+
+func (gen *Genesis) addSingleAccount(KeyHash string, addr string, name string) {
+	gen.Accounts = append(gen.Accounts, &GenesisAccount{
+		Type:    GenesisSingleAccount,
+		Address: addr,
+		KeyHash: KeyHash,
+		Name:    name,
+		Balance: "2000000000",
+	})
+}
+
+func (gen *Genesis) addFormulator(KeyHash string, addr string, name string) {
+	gen.Accounts = append(gen.Accounts, &GenesisAccount{
+		Type:    GenesisAlphaFormulator,
+		Address: addr,
+		KeyHash: KeyHash,
+		Name:    name,
+	})
+}
+
+// AccCoordGenerator allocates the coordinates of the genesis accounts in order
+type AccCoordGenerator struct {
+	idx uint16
+}
+
+// Generate returns the next coordinate
+func (acg *AccCoordGenerator) Generate() *common.Coordinate {
+	coord := common.NewCoordinate(0, acg.idx)
+	acg.idx++
+	return coord
+}
@@ -0,0 +1,135 @@
+package chain_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fletaio/cmd/chain"
+	"github.com/fletaio/cmd/command"
+	"github.com/fletaio/common"
+	"github.com/fletaio/common/hash"
+)
+
+// baselineGenesisHash is the genesis hash of the FLETA Beta Testnet made by the init.go of the daemons before the chain package
+const baselineGenesisHash = "653a2b99a5acba4db7e9d6f9b751493d6dd3ec1a82160a4dd67918252679d8c1"
+
+// testnetObserverKeys are the observers of the FLETA Beta Testnet
+var testnetObserverKeys = []string{
+	"3e5PNobd577YEdjeb59zG6N7BBZbyRKMja2s55QQMQE",
+	"4ry8UmsCbo1BPTmUhqjMWgy9UtLDFcebEsc4Lgjo9ba",
+	"4KT4crmdp5GDihPXufUonmAujjC1YH4viej8C1udjc4",
+	"3suqtMQWdUFUwDGMzH53KRJPFzqaP6YYRqRGMPLhhp5",
+	"3HLUjZYeUDc7nGKqCaRyqB8yJHwj3BgFMWUYNmMSYfE",
+}
+
+// daemonConfig is the part of the config of the daemons that makes the genesis hash
+type daemonConfig struct {
+	GenesisFile    string
+	ObserverKeys   []string
+	ObserverKeyMap map[string]string
+}
+
+func (cfg *daemonConfig) observerKeys() []string {
+	keys := append([]string{}, cfg.ObserverKeys...)
+	for k := range cfg.ObserverKeyMap {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// genesisHash stores the genesis to the empty store by the kernel and returns the hash of the height 0
+func genesisHash(t *testing.T, gen *chain.Genesis, rp *chain.RewardPolicy, ObserverKeys []string) hash.Hash256 {
+	t.Helper()
+
+	ObserverKeyMap := map[common.PublicHash]bool{}
+	for _, k := range ObserverKeys {
+		pubhash, err := common.ParsePublicHash(k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ObserverKeyMap[pubhash] = true
+	}
+	bs, err := chain.NewBootstrap(gen, rp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "genesis")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ks, err := bs.OpenStore(&chain.StoreConfig{
+		Path:           filepath.Join(dir, "kernel"),
+		RecoveryPolicy: chain.RecoveryFail,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	kn, err := bs.NewKernel(ks, chain.NewRewarder(bs.RewardSchedule), ObserverKeyMap)
+	if err != nil {
+		ks.Close()
+		t.Fatal(err)
+	}
+	defer kn.Close()
+
+	h, err := kn.Provider().Hash(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func TestDefaultGenesisHash(t *testing.T) {
+	h := genesisHash(t, chain.DefaultGenesis(), &chain.RewardPolicy{}, testnetObserverKeys)
+	if h.String() != baselineGenesisHash {
+		t.Fatalf("genesis hash is %s, expected %s", h, baselineGenesisHash)
+	}
+}
+
+func TestDaemonGenesisHash(t *testing.T) {
+	for _, name := range []string{"node", "formulator", "observer"} {
+		t.Run(name, func(t *testing.T) {
+			var cfg daemonConfig
+			if err := command.LoadConfig([]string{"--config", filepath.Join("..", name, "config.toml")}, &cfg); err != nil {
+				t.Fatal(err)
+			}
+			gen, err := chain.LoadGenesis(cfg.GenesisFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if h := genesisHash(t, gen, &chain.RewardPolicy{}, cfg.observerKeys()); h.String() != baselineGenesisHash {
+				t.Fatalf("genesis hash of %s is %s, expected %s", name, h, baselineGenesisHash)
+			}
+		})
+	}
+}
+
+func TestSavedGenesisHash(t *testing.T) {
+	dir, err := ioutil.TempDir("", "genesis")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "genesis.toml")
+	if err := chain.SaveGenesis(path, chain.DefaultGenesis()); err != nil {
+		t.Fatal(err)
+	}
+	gen, err := chain.LoadGenesis(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h := genesisHash(t, gen, &chain.RewardPolicy{}, testnetObserverKeys); h.String() != baselineGenesisHash {
+		t.Fatalf("genesis hash of the saved genesis is %s, expected %s", h, baselineGenesisHash)
+	}
+}
+
+func TestGenesisHashByRewardPolicy(t *testing.T) {
+	h := genesisHash(t, chain.DefaultGenesis(), &chain.RewardPolicy{Mode: chain.RewardMainNet}, testnetObserverKeys)
+	if h.String() == baselineGenesisHash {
+		t.Fatal("genesis hash of the mainnet reward policy is the same as the testnet")
+	}
+}
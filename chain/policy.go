@@ -0,0 +1,157 @@
+package chain
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/fletaio/core/amount"
+	"github.com/fletaio/core/consensus"
+)
+
+// GenesisConsensusPolicy defines the consensus policy of the genesis
+type GenesisConsensusPolicy struct {
+	RewardPerBlock                string
+	PayRewardEveryBlocks          uint32
+	FormulatorCreationLimitHeight uint32
+	AlphaFormulationAmount        string
+	AlphaEfficiency1000           uint32
+	AlphaUnlockRequiredBlocks     uint32
+	SigmaRequiredAlphaBlocks      uint32
+	SigmaRequiredAlphaCount       uint32
+	SigmaEfficiency1000           uint32
+	SigmaUnlockRequiredBlocks     uint32
+	OmegaRequiredSigmaBlocks      uint32
+	OmegaRequiredSigmaCount       uint32
+	OmegaEfficiency1000           uint32
+	OmegaUnlockRequiredBlocks     uint32
+	HyperFormulationAmount        string
+	HyperEfficiency1000           uint32
+	HyperUnlockRequiredBlocks     uint32
+	StakingEfficiency1000         uint32
+	StakingUnlockRequiredBlocks   uint32
+}
+
+// DefaultConsensusPolicy returns the consensus policy of the FLETA Beta Testnet
+func DefaultConsensusPolicy() *GenesisConsensusPolicy {
+	return &GenesisConsensusPolicy{
+		RewardPerBlock:                "0.5",
+		PayRewardEveryBlocks:          500,
+		FormulatorCreationLimitHeight: 1000,
+		AlphaFormulationAmount:        "1000",
+		AlphaEfficiency1000:           1000,
+		AlphaUnlockRequiredBlocks:     1000,
+		SigmaRequiredAlphaBlocks:      1000,
+		SigmaRequiredAlphaCount:       4,
+		SigmaEfficiency1000:           1000,
+		SigmaUnlockRequiredBlocks:     1000,
+		OmegaRequiredSigmaBlocks:      1000,
+		OmegaRequiredSigmaCount:       2,
+		OmegaEfficiency1000:           1000,
+		OmegaUnlockRequiredBlocks:     1000,
+		HyperFormulationAmount:        "1000",
+		HyperEfficiency1000:           1000,
+		HyperUnlockRequiredBlocks:     1000,
+		StakingEfficiency1000:         1000,
+	}
+}
+
+func (gp *GenesisConsensusPolicy) parse() (*consensus.ConsensusPolicy, error) {
+	RewardPerBlock, err := amount.ParseAmount(gp.RewardPerBlock)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RewardPerBlock %q: %v", gp.RewardPerBlock, err)
+	}
+	AlphaFormulationAmount, err := amount.ParseAmount(gp.AlphaFormulationAmount)
+	if err != nil {
+		return nil, fmt.Errorf("invalid AlphaFormulationAmount %q: %v", gp.AlphaFormulationAmount, err)
+	}
+	HyperFormulationAmount, err := amount.ParseAmount(gp.HyperFormulationAmount)
+	if err != nil {
+		return nil, fmt.Errorf("invalid HyperFormulationAmount %q: %v", gp.HyperFormulationAmount, err)
+	}
+	if gp.PayRewardEveryBlocks == 0 {
+		return nil, fmt.Errorf("%v: PayRewardEveryBlocks should be positive", ErrInvalidConsensusPolicy)
+	}
+	if AlphaFormulationAmount.IsZero() {
+		return nil, fmt.Errorf("%v: AlphaFormulationAmount should be positive", ErrInvalidConsensusPolicy)
+	}
+	if HyperFormulationAmount.IsZero() {
+		return nil, fmt.Errorf("%v: HyperFormulationAmount should be positive", ErrInvalidConsensusPolicy)
+	}
+	if gp.SigmaRequiredAlphaCount == 0 {
+		return nil, fmt.Errorf("%v: SigmaRequiredAlphaCount should be positive", ErrInvalidConsensusPolicy)
+	}
+	if gp.OmegaRequiredSigmaCount == 0 {
+		return nil, fmt.Errorf("%v: OmegaRequiredSigmaCount should be positive", ErrInvalidConsensusPolicy)
+	}
+	if gp.SigmaRequiredAlphaBlocks == 0 {
+		return nil, fmt.Errorf("%v: SigmaRequiredAlphaBlocks should be positive", ErrInvalidConsensusPolicy)
+	}
+	if gp.OmegaRequiredSigmaBlocks == 0 {
+		return nil, fmt.Errorf("%v: OmegaRequiredSigmaBlocks should be positive", ErrInvalidConsensusPolicy)
+	}
+	Efficiencies := []struct {
+		Name  string
+		Value uint32
+	}{
+		{"AlphaEfficiency1000", gp.AlphaEfficiency1000},
+		{"SigmaEfficiency1000", gp.SigmaEfficiency1000},
+		{"OmegaEfficiency1000", gp.OmegaEfficiency1000},
+		{"HyperEfficiency1000", gp.HyperEfficiency1000},
+		{"StakingEfficiency1000", gp.StakingEfficiency1000},
+	}
+	for _, v := range Efficiencies {
+		if v.Value == 0 {
+			return nil, fmt.Errorf("%v: %s should be positive", ErrInvalidConsensusPolicy, v.Name)
+		}
+	}
+	if gp.SigmaEfficiency1000 < gp.AlphaEfficiency1000 {
+		return nil, fmt.Errorf("%v: SigmaEfficiency1000 should not be less than AlphaEfficiency1000", ErrInvalidConsensusPolicy)
+	}
+	if gp.OmegaEfficiency1000 < gp.SigmaEfficiency1000 {
+		return nil, fmt.Errorf("%v: OmegaEfficiency1000 should not be less than SigmaEfficiency1000", ErrInvalidConsensusPolicy)
+	}
+	return &consensus.ConsensusPolicy{
+		RewardPerBlock:                RewardPerBlock,
+		PayRewardEveryBlocks:          gp.PayRewardEveryBlocks,
+		FormulatorCreationLimitHeight: gp.FormulatorCreationLimitHeight,
+		AlphaFormulationAmount:        AlphaFormulationAmount,
+		AlphaEfficiency1000:           gp.AlphaEfficiency1000,
+		AlphaUnlockRequiredBlocks:     gp.AlphaUnlockRequiredBlocks,
+		SigmaRequiredAlphaBlocks:      gp.SigmaRequiredAlphaBlocks,
+		SigmaRequiredAlphaCount:       gp.SigmaRequiredAlphaCount,
+		SigmaEfficiency1000:           gp.SigmaEfficiency1000,
+		SigmaUnlockRequiredBlocks:     gp.SigmaUnlockRequiredBlocks,
+		OmegaRequiredSigmaBlocks:      gp.OmegaRequiredSigmaBlocks,
+		OmegaRequiredSigmaCount:       gp.OmegaRequiredSigmaCount,
+		OmegaEfficiency1000:           gp.OmegaEfficiency1000,
+		OmegaUnlockRequiredBlocks:     gp.OmegaUnlockRequiredBlocks,
+		HyperFormulationAmount:        HyperFormulationAmount,
+		HyperEfficiency1000:           gp.HyperEfficiency1000,
+		HyperUnlockRequiredBlocks:     gp.HyperUnlockRequiredBlocks,
+		StakingEfficiency1000:         gp.StakingEfficiency1000,
+		StakingUnlockRequiredBlocks:   gp.StakingUnlockRequiredBlocks,
+	}, nil
+}
+
+func (gp *GenesisConsensusPolicy) writeTo(buffer *bytes.Buffer) {
+	buffer.WriteString("[ConsensusPolicy]\n")
+	fmt.Fprintf(buffer, "RewardPerBlock = %q\n", gp.RewardPerBlock)
+	fmt.Fprintf(buffer, "PayRewardEveryBlocks = %d\n", gp.PayRewardEveryBlocks)
+	fmt.Fprintf(buffer, "FormulatorCreationLimitHeight = %d\n", gp.FormulatorCreationLimitHeight)
+	fmt.Fprintf(buffer, "AlphaFormulationAmount = %q\n", gp.AlphaFormulationAmount)
+	fmt.Fprintf(buffer, "AlphaEfficiency1000 = %d\n", gp.AlphaEfficiency1000)
+	fmt.Fprintf(buffer, "AlphaUnlockRequiredBlocks = %d\n", gp.AlphaUnlockRequiredBlocks)
+	fmt.Fprintf(buffer, "SigmaRequiredAlphaBlocks = %d\n", gp.SigmaRequiredAlphaBlocks)
+	fmt.Fprintf(buffer, "SigmaRequiredAlphaCount = %d\n", gp.SigmaRequiredAlphaCount)
+	fmt.Fprintf(buffer, "SigmaEfficiency1000 = %d\n", gp.SigmaEfficiency1000)
+	fmt.Fprintf(buffer, "SigmaUnlockRequiredBlocks = %d\n", gp.SigmaUnlockRequiredBlocks)
+	fmt.Fprintf(buffer, "OmegaRequiredSigmaBlocks = %d\n", gp.OmegaRequiredSigmaBlocks)
+	fmt.Fprintf(buffer, "OmegaRequiredSigmaCount = %d\n", gp.OmegaRequiredSigmaCount)
+	fmt.Fprintf(buffer, "OmegaEfficiency1000 = %d\n", gp.OmegaEfficiency1000)
+	fmt.Fprintf(buffer, "OmegaUnlockRequiredBlocks = %d\n", gp.OmegaUnlockRequiredBlocks)
+	fmt.Fprintf(buffer, "HyperFormulationAmount = %q\n", gp.HyperFormulationAmount)
+	fmt.Fprintf(buffer, "HyperEfficiency1000 = %d\n", gp.HyperEfficiency1000)
+	fmt.Fprintf(buffer, "HyperUnlockRequiredBlocks = %d\n", gp.HyperUnlockRequiredBlocks)
+	fmt.Fprintf(buffer, "StakingEfficiency1000 = %d\n", gp.StakingEfficiency1000)
+	fmt.Fprintf(buffer, "StakingUnlockRequiredBlocks = %d\n", gp.StakingUnlockRequiredBlocks)
+}
@@ -0,0 +1,106 @@
+package chain
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+
+	"github.com/fletaio/common"
+	"github.com/fletaio/core/account"
+	"github.com/fletaio/core/amount"
+	"github.com/fletaio/core/data"
+	"github.com/fletaio/core/reward"
+)
+
+// Payout is a reward paid to the address
+type Payout struct {
+	Address common.Address
+	Amount  *amount.Amount
+}
+
+// PayoutRecorder wraps the rewarder and records the rewards paid while processing the last block
+// The rewarder does not emit events, so the payouts are found by the balances of the accounts before and after processing the reward
+type PayoutRecorder struct {
+	sync.Mutex
+	reward.Rewarder
+	loader  data.Loader
+	height  uint32
+	payouts []*Payout
+}
+
+// NewPayoutRecorder returns a PayoutRecorder of the rewarder
+// The loader should be the store of the kernel because the balances of the untouched accounts are loaded from it
+func NewPayoutRecorder(rd reward.Rewarder, loader data.Loader) *PayoutRecorder {
+	return &PayoutRecorder{
+		Rewarder: rd,
+		loader:   loader,
+	}
+}
+
+// ProcessReward processes the reward by the rewarder and records the payouts
+func (pr *PayoutRecorder) ProcessReward(addr common.Address, ctx *data.Context) ([]byte, error) {
+	top := ctx.Top()
+	before := map[common.Address]*amount.Amount{}
+	for k, acc := range top.AccountMap {
+		before[k] = acc.Balance()
+	}
+	for k, acc := range top.CreatedAccountMap {
+		before[k] = acc.Balance()
+	}
+
+	SaveData, err := pr.Rewarder.ProcessReward(addr, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	payouts := []*Payout{}
+	for k, acc := range top.AccountMap {
+		prev, has := before[k]
+		if !has {
+			prev, err = pr.balance(top, k)
+			if err != nil {
+				continue
+			}
+		}
+		if Balance := acc.Balance(); prev.Less(Balance) {
+			payouts = append(payouts, &Payout{
+				Address: k,
+				Amount:  Balance.Sub(prev),
+			})
+		}
+	}
+	sort.Slice(payouts, func(i, j int) bool {
+		return bytes.Compare(payouts[i].Address[:], payouts[j].Address[:]) < 0
+	})
+
+	pr.Lock()
+	pr.height = ctx.TargetHeight()
+	pr.payouts = payouts
+	pr.Unlock()
+	return SaveData, nil
+}
+
+// Payouts returns the payouts of the height when the height is the last processed height
+func (pr *PayoutRecorder) Payouts(height uint32) []*Payout {
+	pr.Lock()
+	defer pr.Unlock()
+
+	if pr.height != height {
+		return nil
+	}
+	return pr.payouts
+}
+
+func (pr *PayoutRecorder) balance(top *data.ContextData, addr common.Address) (*amount.Amount, error) {
+	var acc account.Account
+	var err error
+	if top.Parent != nil {
+		acc, err = top.Parent.Account(addr)
+	} else {
+		acc, err = pr.loader.Account(addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return acc.Balance(), nil
+}
@@ -0,0 +1,254 @@
+package chain
+
+import (
+	"bytes"
+	"math/big"
+
+	"github.com/fletaio/common"
+	"github.com/fletaio/common/hash"
+	"github.com/fletaio/common/util"
+	"github.com/fletaio/core/amount"
+	"github.com/fletaio/core/data"
+	"github.com/fletaio/core/reward"
+)
+
+// reward modes
+const (
+	RewardTestNet = "testnet"
+	RewardMainNet = "mainnet"
+	RewardNone    = "none"
+	RewardCustom  = "custom"
+)
+
+// reward curves
+// The flat curve is the testnet reward and the none curve is no reward
+const (
+	CurveFlat        = "flat"
+	CurveNone        = "none"
+	CurveHalving     = "halving"
+	CurveFixedSupply = "fixed-supply"
+)
+
+// MainNetHalvingBlocks is the halving interval of the mainnet reward, about 4 years of the 0.5 second blocks
+const MainNetHalvingBlocks = 4 * 365 * 24 * 60 * 60 * 2
+
+// tagRewardPolicy is the name of the genesis data of the hash of the reward policy
+var tagRewardPolicy = []byte("fleta.reward.policy")
+
+// RewardPolicy is the reward policy of the config
+// The curve and its parameters are only given by the custom mode
+type RewardPolicy struct {
+	Mode          string
+	Curve         string
+	Initial       string
+	HalvingBlocks uint32
+	Supply        string
+	Blocks        uint32
+}
+
+// RewardPolicyError is the error of the field of the reward policy
+type RewardPolicyError struct {
+	Field string
+	Err   error
+}
+
+// Error returns the message of the error
+func (e *RewardPolicyError) Error() string {
+	return e.Field + ": " + e.Err.Error()
+}
+
+// RewardSchedule is the reward of each block by the curve
+// The halving curve halves Initial every HalvingBlocks, and the fixed-supply curve divides Supply by the first Blocks blocks
+type RewardSchedule struct {
+	Mode          string         `json:"mode"`
+	Curve         string         `json:"curve"`
+	Initial       *amount.Amount `json:"initial,omitempty"`
+	HalvingBlocks uint32         `json:"halving_blocks,omitempty"`
+	Supply        *amount.Amount `json:"supply,omitempty"`
+	Blocks        uint32         `json:"blocks,omitempty"`
+}
+
+// RewardSchedule returns the reward schedule of the policy
+// The initial reward of the testnet and the mainnet is RewardPerBlock of the consensus policy
+func (gen *Genesis) RewardSchedule(rp *RewardPolicy) (*RewardSchedule, error) {
+	policy, err := gen.consensusPolicy()
+	if err != nil {
+		return nil, err
+	}
+	Mode := rp.Mode
+	if len(Mode) == 0 {
+		Mode = RewardTestNet
+	}
+	if Mode != RewardCustom {
+		switch {
+		case len(rp.Curve) > 0:
+			return nil, &RewardPolicyError{Field: "Curve", Err: ErrNotCustomReward}
+		case len(rp.Initial) > 0:
+			return nil, &RewardPolicyError{Field: "Initial", Err: ErrNotCustomReward}
+		case rp.HalvingBlocks > 0:
+			return nil, &RewardPolicyError{Field: "HalvingBlocks", Err: ErrNotCustomReward}
+		case len(rp.Supply) > 0:
+			return nil, &RewardPolicyError{Field: "Supply", Err: ErrNotCustomReward}
+		case rp.Blocks > 0:
+			return nil, &RewardPolicyError{Field: "Blocks", Err: ErrNotCustomReward}
+		}
+	}
+
+	switch Mode {
+	case RewardTestNet:
+		return &RewardSchedule{Mode: Mode, Curve: CurveFlat, Initial: policy.RewardPerBlock}, nil
+	case RewardMainNet:
+		return &RewardSchedule{Mode: Mode, Curve: CurveHalving, Initial: policy.RewardPerBlock, HalvingBlocks: MainNetHalvingBlocks}, nil
+	case RewardNone:
+		return &RewardSchedule{Mode: Mode, Curve: CurveNone}, nil
+	case RewardCustom:
+	default:
+		return nil, &RewardPolicyError{Field: "Mode", Err: ErrUnknownRewardMode}
+	}
+
+	switch rp.Curve {
+	case CurveHalving:
+		Initial, err := parseRewardAmount("Initial", rp.Initial)
+		if err != nil {
+			return nil, err
+		}
+		if rp.HalvingBlocks == 0 {
+			return nil, &RewardPolicyError{Field: "HalvingBlocks", Err: ErrInvalidRewardBlocks}
+		}
+		return &RewardSchedule{Mode: Mode, Curve: rp.Curve, Initial: Initial, HalvingBlocks: rp.HalvingBlocks}, nil
+	case CurveFixedSupply:
+		Supply, err := parseRewardAmount("Supply", rp.Supply)
+		if err != nil {
+			return nil, err
+		}
+		if rp.Blocks == 0 {
+			return nil, &RewardPolicyError{Field: "Blocks", Err: ErrInvalidRewardBlocks}
+		}
+		return &RewardSchedule{Mode: Mode, Curve: rp.Curve, Supply: Supply, Blocks: rp.Blocks}, nil
+	default:
+		return nil, &RewardPolicyError{Field: "Curve", Err: ErrUnknownRewardCurve}
+	}
+}
+
+func parseRewardAmount(Field string, str string) (*amount.Amount, error) {
+	am, err := amount.ParseAmount(str)
+	if err != nil || am.IsZero() || am.Sign() < 0 {
+		return nil, &RewardPolicyError{Field: Field, Err: ErrInvalidRewardAmount}
+	}
+	return am, nil
+}
+
+// Reward returns the reward of the block of the height
+func (rs *RewardSchedule) Reward(height uint32) *amount.Amount {
+	if height == 0 {
+		return amount.NewCoinAmount(0, 0)
+	}
+	switch rs.Curve {
+	case CurveFlat:
+		return rs.Initial.Clone()
+	case CurveHalving:
+		return halve(rs.Initial, (height-1)/rs.HalvingBlocks)
+	case CurveFixedSupply:
+		if height > rs.Blocks {
+			return amount.NewCoinAmount(0, 0)
+		}
+		per := rs.Supply.DivC(int64(rs.Blocks))
+		if height == rs.Blocks {
+			return rs.Supply.Sub(per.MulC(int64(rs.Blocks - 1)))
+		}
+		return per
+	default:
+		return amount.NewCoinAmount(0, 0)
+	}
+}
+
+// Issued returns the sum of the rewards of the blocks until the height
+func (rs *RewardSchedule) Issued(height uint32) *amount.Amount {
+	switch rs.Curve {
+	case CurveFlat:
+		return rs.Initial.MulC(int64(height))
+	case CurveHalving:
+		Issued := amount.NewCoinAmount(0, 0)
+		eras := height / rs.HalvingBlocks
+		for e := uint32(0); e < eras; e++ {
+			r := halve(rs.Initial, e)
+			if r.IsZero() {
+				return Issued
+			}
+			Issued = Issued.Add(r.MulC(int64(rs.HalvingBlocks)))
+		}
+		return Issued.Add(halve(rs.Initial, eras).MulC(int64(height % rs.HalvingBlocks)))
+	case CurveFixedSupply:
+		if height >= rs.Blocks {
+			return rs.Supply.Clone()
+		}
+		return rs.Supply.DivC(int64(rs.Blocks)).MulC(int64(height))
+	default:
+		return amount.NewCoinAmount(0, 0)
+	}
+}
+
+func halve(am *amount.Amount, era uint32) *amount.Amount {
+	return &amount.Amount{Int: new(big.Int).Rsh(am.Int, uint(era))}
+}
+
+// Hash returns the hash of the schedule that is compared between the daemons of the chain
+func (rs *RewardSchedule) Hash() hash.Hash256 {
+	var buffer bytes.Buffer
+	buffer.WriteString("RewardSchedule")
+	buffer.WriteString(rs.Mode + ":" + rs.Curve + ":")
+	for _, am := range []*amount.Amount{rs.Initial, rs.Supply} {
+		if am == nil {
+			am = amount.NewCoinAmount(0, 0)
+		}
+		if _, err := am.WriteTo(&buffer); err != nil {
+			panic(err)
+		}
+	}
+	if _, err := util.WriteUint32(&buffer, rs.HalvingBlocks); err != nil {
+		panic(err)
+	}
+	if _, err := util.WriteUint32(&buffer, rs.Blocks); err != nil {
+		panic(err)
+	}
+	return hash.DoubleHash(buffer.Bytes())
+}
+
+// CommitRewardSchedule adds the hash of the schedule to the genesis data, so the genesis hash differs by the reward policy
+// The testnet schedule is not added to keep the genesis hash of the chains made before the reward policy
+func CommitRewardSchedule(ctd *data.ContextData, rs *RewardSchedule) {
+	if rs.Mode == RewardTestNet {
+		return
+	}
+	h := rs.Hash()
+	ctd.SetAccountData(common.Address{}, tagRewardPolicy, h[:])
+}
+
+// NewRewarder returns the rewarder of the schedule
+// The testnet schedule uses the testnet rewarder of the core
+func NewRewarder(rs *RewardSchedule) reward.Rewarder {
+	if rs.Mode == RewardTestNet {
+		return reward.NewTestNetRewarder()
+	}
+	return NewScheduleRewarder(rs)
+}
+
+// ExpectedReward is the reward of the height by the schedule
+type ExpectedReward struct {
+	Height     uint32          `json:"height"`
+	Reward     *amount.Amount  `json:"reward"`
+	Issued     *amount.Amount  `json:"issued"`
+	Schedule   *RewardSchedule `json:"schedule"`
+	PolicyHash string          `json:"policy_hash"`
+}
+
+// Expected returns the reward of the block of the height and the sum of the rewards until the height
+func (rs *RewardSchedule) Expected(height uint32) *ExpectedReward {
+	return &ExpectedReward{
+		Height:     height,
+		Reward:     rs.Reward(height),
+		Issued:     rs.Issued(height),
+		Schedule:   rs,
+		PolicyHash: rs.Hash().String(),
+	}
+}
@@ -0,0 +1,126 @@
+package chain_test
+
+import (
+	"testing"
+
+	"github.com/fletaio/cmd/chain"
+	"github.com/fletaio/core/amount"
+)
+
+func rewardSchedule(t *testing.T, rp *chain.RewardPolicy) *chain.RewardSchedule {
+	t.Helper()
+	rs, err := chain.DefaultGenesis().RewardSchedule(rp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rs
+}
+
+// checkIssued checks that the issued amount is the sum of the rewards until the height
+func checkIssued(t *testing.T, rs *chain.RewardSchedule, to uint32) {
+	t.Helper()
+	sum := amount.NewCoinAmount(0, 0)
+	for h := uint32(1); h <= to; h++ {
+		sum = sum.Add(rs.Reward(h))
+		if Issued := rs.Issued(h); !Issued.Equal(sum) {
+			t.Fatalf("the issued amount of the %s curve at %d is %v, expected %v", rs.Curve, h, Issued, sum)
+		}
+	}
+}
+
+func TestRewardScheduleModes(t *testing.T) {
+	RewardPerBlock := amount.NewCoinAmount(0, 500000000000000000)
+
+	rs := rewardSchedule(t, &chain.RewardPolicy{})
+	if rs.Mode != chain.RewardTestNet || rs.Curve != chain.CurveFlat || !rs.Initial.Equal(RewardPerBlock) {
+		t.Errorf("the default schedule is %+v, expected the flat testnet reward", rs)
+	}
+	if !rs.Reward(1000).Equal(RewardPerBlock) || !rs.Reward(0).IsZero() {
+		t.Errorf("the flat reward is %v at 1000 and %v at 0", rs.Reward(1000), rs.Reward(0))
+	}
+
+	rs = rewardSchedule(t, &chain.RewardPolicy{Mode: chain.RewardMainNet})
+	if rs.Curve != chain.CurveHalving || rs.HalvingBlocks != chain.MainNetHalvingBlocks || !rs.Initial.Equal(RewardPerBlock) {
+		t.Errorf("the mainnet schedule is %+v", rs)
+	}
+
+	rs = rewardSchedule(t, &chain.RewardPolicy{Mode: chain.RewardNone})
+	if !rs.Reward(1).IsZero() || !rs.Issued(1000).IsZero() {
+		t.Errorf("the none schedule rewards %v", rs.Reward(1))
+	}
+}
+
+func TestRewardScheduleHalving(t *testing.T) {
+	rs := rewardSchedule(t, &chain.RewardPolicy{
+		Mode:          chain.RewardCustom,
+		Curve:         chain.CurveHalving,
+		Initial:       "0.000000000000000008",
+		HalvingBlocks: 3,
+	})
+	expected := []int64{0, 8, 8, 8, 4, 4, 4, 2, 2, 2, 1, 1, 1, 0}
+	for h, v := range expected {
+		if r := rs.Reward(uint32(h)); r.Int.Int64() != v {
+			t.Errorf("the reward at %d is %v, expected %d", h, r.Int, v)
+		}
+	}
+	checkIssued(t, rs, 20)
+	if Issued := rs.Issued(1000); Issued.Int.Int64() != 45 {
+		t.Errorf("the issued amount after the last halving is %v, expected 45", Issued.Int)
+	}
+}
+
+func TestRewardScheduleFixedSupply(t *testing.T) {
+	Supply := amount.NewCoinAmount(10, 0)
+	rs := rewardSchedule(t, &chain.RewardPolicy{
+		Mode:   chain.RewardCustom,
+		Curve:  chain.CurveFixedSupply,
+		Supply: "10",
+		Blocks: 3,
+	})
+	checkIssued(t, rs, 10)
+	if !rs.Issued(3).Equal(Supply) || !rs.Issued(10).Equal(Supply) {
+		t.Errorf("the issued amount is %v, expected the supply %v", rs.Issued(3), Supply)
+	}
+	if !rs.Reward(4).IsZero() {
+		t.Errorf("the reward after the last block is %v", rs.Reward(4))
+	}
+}
+
+func TestRewardPolicyError(t *testing.T) {
+	tests := []struct {
+		rp    *chain.RewardPolicy
+		field string
+		err   error
+	}{
+		{&chain.RewardPolicy{Mode: "bonus"}, "Mode", chain.ErrUnknownRewardMode},
+		{&chain.RewardPolicy{Curve: chain.CurveHalving}, "Curve", chain.ErrNotCustomReward},
+		{&chain.RewardPolicy{Mode: chain.RewardMainNet, Blocks: 10}, "Blocks", chain.ErrNotCustomReward},
+		{&chain.RewardPolicy{Mode: chain.RewardCustom, Curve: "linear"}, "Curve", chain.ErrUnknownRewardCurve},
+		{&chain.RewardPolicy{Mode: chain.RewardCustom, Curve: chain.CurveHalving, Initial: "0", HalvingBlocks: 10}, "Initial", chain.ErrInvalidRewardAmount},
+		{&chain.RewardPolicy{Mode: chain.RewardCustom, Curve: chain.CurveHalving, Initial: "1"}, "HalvingBlocks", chain.ErrInvalidRewardBlocks},
+		{&chain.RewardPolicy{Mode: chain.RewardCustom, Curve: chain.CurveFixedSupply, Supply: "many", Blocks: 10}, "Supply", chain.ErrInvalidRewardAmount},
+		{&chain.RewardPolicy{Mode: chain.RewardCustom, Curve: chain.CurveFixedSupply, Supply: "10"}, "Blocks", chain.ErrInvalidRewardBlocks},
+	}
+	for _, tt := range tests {
+		_, err := chain.DefaultGenesis().RewardSchedule(tt.rp)
+		pe, is := err.(*chain.RewardPolicyError)
+		if !is || pe.Field != tt.field || pe.Err != tt.err {
+			t.Errorf("%+v returns %v, expected %s: %v", tt.rp, err, tt.field, tt.err)
+		}
+	}
+}
+
+func TestRewardScheduleHash(t *testing.T) {
+	rp := &chain.RewardPolicy{Mode: chain.RewardCustom, Curve: chain.CurveFixedSupply, Supply: "10", Blocks: 3}
+	h := rewardSchedule(t, rp).Hash()
+	if rewardSchedule(t, rp).Hash() != h {
+		t.Error("the hash of the same schedule differs")
+	}
+	rp.Blocks = 4
+	if rewardSchedule(t, rp).Hash() == h {
+		t.Error("the hash of the different blocks is same")
+	}
+	if rewardSchedule(t, &chain.RewardPolicy{}).Hash() == rewardSchedule(t, &chain.RewardPolicy{Mode: chain.RewardMainNet}).Hash() {
+		t.Error("the hash of the testnet and the mainnet is same")
+	}
+}
@@ -0,0 +1,184 @@
+package chain
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/fletaio/common"
+	"github.com/fletaio/common/util"
+	"github.com/fletaio/core/amount"
+	"github.com/fletaio/core/consensus"
+	"github.com/fletaio/core/data"
+)
+
+// ScheduleRewarder pays the rewards of the schedule by the power of the formulators
+// The power is gathered in the same way as the testnet rewarder, and the rewards of the blocks
+// from the last paid height are divided by the power every PayRewardEveryBlocks
+type ScheduleRewarder struct {
+	schedule       *RewardSchedule
+	LastPaidHeight uint32
+	PowerMap       map[common.Address]*amount.Amount
+}
+
+// NewScheduleRewarder returns a ScheduleRewarder
+func NewScheduleRewarder(rs *RewardSchedule) *ScheduleRewarder {
+	return &ScheduleRewarder{
+		schedule: rs,
+		PowerMap: map[common.Address]*amount.Amount{},
+	}
+}
+
+// ApplyGenesis init genesis data
+func (rd *ScheduleRewarder) ApplyGenesis(ctx *data.ContextData) ([]byte, error) {
+	return rd.buildSaveData()
+}
+
+// ProcessReward gathers the power of the block generator and pays the rewards when it is the paying height
+func (rd *ScheduleRewarder) ProcessReward(addr common.Address, ctx *data.Context) ([]byte, error) {
+	policy, err := consensus.GetConsensusPolicy(ctx.ChainCoord())
+	if err != nil {
+		return nil, err
+	}
+	if err := rd.addFormulatorPower(addr, ctx, policy); err != nil {
+		return nil, err
+	}
+
+	if ctx.TargetHeight() >= rd.LastPaidHeight+policy.PayRewardEveryBlocks {
+		TotalPower := amount.NewCoinAmount(0, 0)
+		for _, PowerSum := range rd.PowerMap {
+			TotalPower = TotalPower.Add(PowerSum)
+		}
+		// the rewards are kept to the next paying height when there is no power
+		if !TotalPower.IsZero() {
+			TotalReward := rd.schedule.Issued(ctx.TargetHeight()).Sub(rd.schedule.Issued(rd.LastPaidHeight))
+			Ratio := TotalReward.Mul(amount.COIN).Div(TotalPower)
+			for addr, PowerSum := range rd.PowerMap {
+				acc, err := ctx.Account(addr)
+				if err != nil {
+					if err != data.ErrNotExistAccount {
+						return nil, err
+					}
+				} else if frAcc, is := acc.(*consensus.FormulationAccount); is {
+					frAcc.AddBalance(PowerSum.Mul(Ratio).Div(amount.COIN))
+				}
+				delete(rd.PowerMap, addr)
+			}
+			rd.LastPaidHeight = ctx.TargetHeight()
+		}
+	}
+	return rd.buildSaveData()
+}
+
+// addFormulatorPower adds the power of the formulator and the stakers of the hyper formulator
+func (rd *ScheduleRewarder) addFormulatorPower(addr common.Address, ctx *data.Context, policy *consensus.ConsensusPolicy) error {
+	acc, err := ctx.Account(addr)
+	if err != nil {
+		return err
+	}
+	frAcc, is := acc.(*consensus.FormulationAccount)
+	if !is {
+		return consensus.ErrInvalidAccountType
+	}
+	switch frAcc.FormulationType {
+	case consensus.AlphaFormulatorType:
+		rd.addPower(addr, frAcc.Amount.MulC(int64(policy.AlphaEfficiency1000)).DivC(1000))
+	case consensus.SigmaFormulatorType:
+		rd.addPower(addr, frAcc.Amount.MulC(int64(policy.SigmaEfficiency1000)).DivC(1000))
+	case consensus.OmegaFormulatorType:
+		rd.addPower(addr, frAcc.Amount.MulC(int64(policy.OmegaEfficiency1000)).DivC(1000))
+	case consensus.HyperFormulatorType:
+		PowerSum := frAcc.Amount.MulC(int64(policy.HyperEfficiency1000)).DivC(1000)
+		keys, err := ctx.AccountDataKeys(addr, consensus.TagStaking)
+		if err != nil {
+			return err
+		}
+		for _, k := range keys {
+			StakingAddress, is := consensus.FromStakingKey(k)
+			if !is {
+				continue
+			}
+			bs := ctx.AccountData(addr, k)
+			if len(bs) == 0 {
+				return consensus.ErrInvalidStakingAddress
+			}
+			if _, err := ctx.Account(StakingAddress); err != nil {
+				if err != data.ErrNotExistAccount {
+					return err
+				}
+				delete(rd.PowerMap, StakingAddress)
+				continue
+			}
+			StakingPower := amount.NewAmountFromBytes(bs).MulC(int64(policy.StakingEfficiency1000)).DivC(1000)
+			ComissionPower := StakingPower.MulC(int64(frAcc.Policy.CommissionRatio1000)).DivC(1000)
+			rd.addPower(StakingAddress, StakingPower.Sub(ComissionPower))
+			PowerSum = PowerSum.Add(ComissionPower)
+		}
+		rd.addPower(addr, PowerSum)
+	default:
+		return consensus.ErrInvalidAccountType
+	}
+	return nil
+}
+
+func (rd *ScheduleRewarder) addPower(addr common.Address, Power *amount.Amount) {
+	if PowerSum, has := rd.PowerMap[addr]; has {
+		rd.PowerMap[addr] = PowerSum.Add(Power)
+	} else {
+		rd.PowerMap[addr] = Power
+	}
+}
+
+// buildSaveData writes the powers in the order of the address to make the same save data in every daemon
+func (rd *ScheduleRewarder) buildSaveData() ([]byte, error) {
+	var buffer bytes.Buffer
+	if _, err := util.WriteUint32(&buffer, rd.LastPaidHeight); err != nil {
+		return nil, err
+	}
+	if _, err := util.WriteUint32(&buffer, uint32(len(rd.PowerMap))); err != nil {
+		return nil, err
+	}
+	addrs := make([]common.Address, 0, len(rd.PowerMap))
+	for addr := range rd.PowerMap {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		return bytes.Compare(addrs[i][:], addrs[j][:]) < 0
+	})
+	for _, addr := range addrs {
+		if _, err := addr.WriteTo(&buffer); err != nil {
+			return nil, err
+		}
+		if _, err := rd.PowerMap[addr].WriteTo(&buffer); err != nil {
+			return nil, err
+		}
+	}
+	return buffer.Bytes(), nil
+}
+
+// LoadFromSaveData recover the status using the save data
+func (rd *ScheduleRewarder) LoadFromSaveData(SaveData []byte) error {
+	r := bytes.NewReader(SaveData)
+	LastPaidHeight, _, err := util.ReadUint32(r)
+	if err != nil {
+		return err
+	}
+	Len, _, err := util.ReadUint32(r)
+	if err != nil {
+		return err
+	}
+	PowerMap := map[common.Address]*amount.Amount{}
+	for i := uint32(0); i < Len; i++ {
+		var addr common.Address
+		if _, err := addr.ReadFrom(r); err != nil {
+			return err
+		}
+		PowerSum := amount.NewCoinAmount(0, 0)
+		if _, err := PowerSum.ReadFrom(r); err != nil {
+			return err
+		}
+		PowerMap[addr] = PowerSum
+	}
+	rd.LastPaidHeight = LastPaidHeight
+	rd.PowerMap = PowerMap
+	return nil
+}
@@ -0,0 +1,223 @@
+package chain
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dgraph-io/badger"
+	"github.com/fletaio/cmd/logging"
+	"github.com/fletaio/core/data"
+	"github.com/fletaio/core/kernel"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+var storeLog = logging.Get(logging.Store)
+
+// recovery policies of the kernel store
+const (
+	RecoveryPrompt   = "prompt"
+	RecoveryTruncate = "truncate"
+	RecoveryFail     = "fail"
+	RecoverySnapshot = "snapshot"
+)
+
+// StoreConfig is the configuration of opening the kernel store
+type StoreConfig struct {
+	Path           string
+	RecoveryPolicy string
+	SnapshotPath   string
+}
+
+// CheckRecoveryPolicy checks that the recovery policy is supported or not
+// The empty policy is the same as the prompt policy
+func CheckRecoveryPolicy(policy string, SnapshotPath string) error {
+	switch policy {
+	case "", RecoveryPrompt, RecoveryTruncate, RecoveryFail:
+	case RecoverySnapshot:
+		if len(SnapshotPath) == 0 {
+			return ErrNotExistSnapshotPath
+		}
+	default:
+		return fmt.Errorf("%v %q (%s, %s, %s, %s)", ErrInvalidRecoveryPolicy, policy, RecoveryPrompt, RecoveryTruncate, RecoveryFail, RecoverySnapshot)
+	}
+	return nil
+}
+
+// OpenStore opens the kernel store and recovers it by the recovery policy when the database needs the truncation
+// The prompt policy asks the recovery only when the stdin is a terminal and fails otherwise
+func OpenStore(sc *StoreConfig, act *data.Accounter, tran *data.Transactor, evt *data.Eventer) (*kernel.Store, error) {
+	if err := CheckRecoveryPolicy(sc.RecoveryPolicy, sc.SnapshotPath); err != nil {
+		return nil, err
+	}
+	ks, err := kernel.NewStore(sc.Path, BlockchainVersion, act, tran, evt, false)
+	if err == nil {
+		return ks, nil
+	} else if !isTruncateNeeded(err) {
+		return nil, err
+	}
+
+	switch sc.RecoveryPolicy {
+	case RecoveryTruncate:
+		return truncateStore(sc, act, tran, evt)
+	case RecoverySnapshot:
+		return restoreStore(sc, act, tran, evt)
+	case RecoveryFail:
+		return nil, fmt.Errorf("%v: %s (RecoveryPolicy is %s)", err, sc.Path, RecoveryFail)
+	default:
+		if !terminal.IsTerminal(int(os.Stdin.Fd())) {
+			return nil, fmt.Errorf("%v: %s (%v, set RecoveryPolicy to %s or %s)", err, sc.Path, ErrNotTerminal, RecoveryTruncate, RecoverySnapshot)
+		}
+		fmt.Println(err)
+		fmt.Println("Do you want to recover database(it can be failed)? [y/n]")
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if strings.ToLower(strings.TrimSpace(answer)) != "y" {
+			return nil, ErrCanceledRecovery
+		}
+		return truncateStore(sc, act, tran, evt)
+	}
+}
+
+// truncateStore backups the damaged store and opens it with the truncation
+func truncateStore(sc *StoreConfig, act *data.Accounter, tran *data.Transactor, evt *data.Eventer) (*kernel.Store, error) {
+	before, err := fileSizes(sc.Path)
+	if err != nil {
+		return nil, err
+	}
+	BackupPath := backupPath(sc.Path)
+	if err := copyDir(sc.Path, BackupPath); err != nil {
+		return nil, fmt.Errorf("backup %s: %v", sc.Path, err)
+	}
+	ks, err := kernel.NewStore(sc.Path, BlockchainVersion, act, tran, evt, true)
+	if err != nil {
+		return nil, err
+	}
+	after, err := fileSizes(sc.Path)
+	if err != nil {
+		ks.Close()
+		return nil, err
+	}
+
+	var Total int64
+	changes := []string{}
+	for name, size := range before {
+		if diff := size - after[name]; diff > 0 {
+			Total += diff
+			changes = append(changes, fmt.Sprintf("%s(%d -> %d)", name, size, after[name]))
+		}
+	}
+	sort.Strings(changes)
+	storeLog.Warn("kernel store is truncated", "path", sc.Path, "backup", BackupPath, "truncated_bytes", Total, "files", strings.Join(changes, " "))
+	return ks, nil
+}
+
+// restoreStore copies the snapshot beside the store and swaps it with the damaged store
+// The damaged store is moved back when the copy can not be put in place or opened
+func restoreStore(sc *StoreConfig, act *data.Accounter, tran *data.Transactor, evt *data.Eventer) (*kernel.Store, error) {
+	if _, err := os.Stat(sc.SnapshotPath); err != nil {
+		return nil, fmt.Errorf("snapshot %s: %v", sc.SnapshotPath, err)
+	}
+	RestorePath := filepath.Clean(sc.Path) + ".restore-" + time.Now().Format("20060102150405")
+	if err := copyDir(sc.SnapshotPath, RestorePath); err != nil {
+		os.RemoveAll(RestorePath)
+		return nil, fmt.Errorf("restore %s: %v", sc.SnapshotPath, err)
+	}
+	BackupPath := backupPath(sc.Path)
+	if err := os.Rename(sc.Path, BackupPath); err != nil {
+		os.RemoveAll(RestorePath)
+		return nil, fmt.Errorf("backup %s: %v", sc.Path, err)
+	}
+	if err := os.Rename(RestorePath, sc.Path); err != nil {
+		os.RemoveAll(RestorePath)
+		if rerr := os.Rename(BackupPath, sc.Path); rerr != nil {
+			return nil, fmt.Errorf("restore %s: %v (the damaged store is left at %s: %v)", sc.Path, err, BackupPath, rerr)
+		}
+		return nil, fmt.Errorf("restore %s: %v", sc.Path, err)
+	}
+	ks, err := kernel.NewStore(sc.Path, BlockchainVersion, act, tran, evt, false)
+	if err != nil {
+		os.RemoveAll(sc.Path)
+		if rerr := os.Rename(BackupPath, sc.Path); rerr != nil {
+			return nil, fmt.Errorf("snapshot %s: %v (the damaged store is left at %s: %v)", sc.SnapshotPath, err, BackupPath, rerr)
+		}
+		return nil, fmt.Errorf("snapshot %s: %v", sc.SnapshotPath, err)
+	}
+	storeLog.Warn("kernel store is restored from the snapshot", "path", sc.Path, "snapshot", sc.SnapshotPath, "backup", BackupPath, "height", ks.Height())
+	return ks, nil
+}
+
+// isTruncateNeeded checks the cause of the error because badger wraps ErrTruncateNeeded while replaying the value log
+func isTruncateNeeded(err error) bool {
+	for err != nil {
+		if err == badger.ErrTruncateNeeded {
+			return true
+		}
+		c, is := err.(interface{ Cause() error })
+		if !is {
+			return false
+		}
+		err = c.Cause()
+	}
+	return false
+}
+
+func backupPath(path string) string {
+	return filepath.Clean(path) + ".backup-" + time.Now().Format("20060102150405")
+}
+
+func fileSizes(dir string) (map[string]int64, error) {
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	sizes := map[string]int64{}
+	for _, fi := range fis {
+		if !fi.IsDir() {
+			sizes[fi.Name()] = fi.Size()
+		}
+	}
+	return sizes, nil
+}
+
+func copyDir(src string, dst string) error {
+	return filepath.Walk(src, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if fi.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		if fi.Name() == "LOCK" {
+			return nil
+		}
+		return copyFile(path, target, fi.Mode())
+	})
+}
+
+func copyFile(src string, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,96 @@
+package command
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/fletaio/cmd/api"
+	"github.com/fletaio/cmd/health"
+	"github.com/fletaio/cmd/metrics"
+	"github.com/fletaio/cmd/tlsutil"
+	"github.com/fletaio/core/data"
+	"github.com/fletaio/core/kernel"
+)
+
+// APIServer is the api and the metrics of the daemon
+// The daemon adds its methods, its health checks and its metrics before Run
+type APIServer struct {
+	Manager  *api.Manager
+	Health   *health.Health
+	Registry *metrics.Registry
+	Chain    *metrics.Chain
+	cfg      *APIConfig
+}
+
+// NewAPIServer returns the api server of the config
+// It has the access, the tls, the limits, the rate limits and the allowed origins of the config,
+// the store and the sync health of the kernel, the log methods and the chain metrics
+func NewAPIServer(cfg *APIConfig, kn *kernel.Kernel, evt *data.Eventer) (*APIServer, error) {
+	rm := api.NewManager()
+	rm.SetEventer(evt)
+	ac, err := api.NewAccess(cfg.APIPublicGroups, cfg.APIKeys, cfg.APIJWTSecret)
+	if err != nil {
+		return nil, err
+	}
+	rm.SetAccess(ac)
+	if len(cfg.APITLSCert) > 0 {
+		kp, err := tlsutil.LoadKeyPair(cfg.APITLSCert, cfg.APITLSKey)
+		if err != nil {
+			return nil, err
+		}
+		rm.SetTLS(kp)
+	}
+	rm.SetLimits(cfg.APILimits())
+	rlr, err := cfg.RateLimiter()
+	if err != nil {
+		return nil, err
+	}
+	if rlr != nil {
+		rm.SetRateLimiter(rlr)
+	}
+	rm.SetCORSOrigins(cfg.APIAllowOrigins)
+
+	hc := health.NewHealth()
+	hc.AddLive("store", health.StoreCheck(kn))
+	hc.AddReady("sync", health.SyncCheck(kn, cfg.ReadyMaxLag))
+	rm.SetHealth(hc)
+	rm.AddLogMethods()
+	kn.AddEventHandler(rm)
+
+	reg := metrics.NewRegistry()
+	mc := metrics.NewChain(reg, kn)
+	kn.AddEventHandler(mc)
+	rm.SetMetrics(reg)
+
+	s := &APIServer{
+		Manager:  rm,
+		Health:   hc,
+		Registry: reg,
+		Chain:    mc,
+		cfg:      cfg,
+	}
+	return s, nil
+}
+
+// Run serves the api and the metrics when the metrics port is given
+// It returns the first error of them, and nil is returned when the api is closed
+func (s *APIServer) Run(kn *kernel.Kernel) error {
+	errCh := make(chan error, 2)
+	if s.cfg.MetricsPort > 0 {
+		go func() {
+			if err := metrics.Serve(net.JoinHostPort(s.cfg.MetricsBind, strconv.Itoa(s.cfg.MetricsPort)), s.Registry); err != nil {
+				errCh <- fmt.Errorf("metrics: %v", err)
+			}
+		}()
+	}
+	go func() {
+		if err := s.Manager.Run(kn, net.JoinHostPort(s.cfg.APIBind, strconv.Itoa(s.cfg.APIPort))); err != nil && err != http.ErrServerClosed {
+			errCh <- fmt.Errorf("api: %v", err)
+		} else {
+			errCh <- nil
+		}
+	}()
+	return <-errCh
+}
@@ -0,0 +1,101 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fletaio/cmd/tlsutil"
+)
+
+func runCert(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%v: cert requires a subcommand (ca, new)", ErrInvalidArgument)
+	}
+	switch args[0] {
+	case "ca":
+		return runCertCA(args[1:])
+	case "new":
+		return runCertNew(args[1:])
+	default:
+		return fmt.Errorf("%v: cert %s", ErrUnknownCommand, args[0])
+	}
+}
+
+func runCertCA(args []string) error {
+	fs := flag.NewFlagSet("cert ca", flag.ContinueOnError)
+	Dir := fs.String("dir", "./certs", "directory of the ca files")
+	Name := fs.String("name", "FLETA Local CA", "common name of the ca")
+	Days := fs.Int("days", 3650, "valid days of the ca")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *Days <= 0 {
+		return fmt.Errorf("%v: days should be positive", ErrInvalidArgument)
+	}
+	CertPath := filepath.Join(*Dir, tlsutil.CACertFile)
+	if _, err := os.Stat(CertPath); err == nil {
+		return fmt.Errorf("%v: %s", ErrExistOutputPath, CertPath)
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	Cert, Key, err := tlsutil.GenerateCA(*Name, *Days)
+	if err != nil {
+		return err
+	}
+	CertPath, KeyPath, err := tlsutil.WritePair(*Dir, tlsutil.CAName, Cert, Key)
+	if err != nil {
+		return err
+	}
+	fmt.Println("CACert :", CertPath)
+	fmt.Println("CAKey :", KeyPath)
+	return nil
+}
+
+func runCertNew(args []string) error {
+	fs := flag.NewFlagSet("cert new", flag.ContinueOnError)
+	Dir := fs.String("dir", "./certs", "directory of the ca files and the output")
+	Name := fs.String("name", "", "name of the certificate files and the common name")
+	Hosts := fs.String("hosts", "", "comma separated host names and IP addresses of the certificate")
+	Days := fs.Int("days", 365, "valid days of the certificate")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(*Name) == 0 {
+		return fmt.Errorf("%v: -name is required", ErrInvalidArgument)
+	}
+	if *Days <= 0 {
+		return fmt.Errorf("%v: days should be positive", ErrInvalidArgument)
+	}
+	hosts := []string{}
+	for _, h := range strings.Split(*Hosts, ",") {
+		if h = strings.TrimSpace(h); len(h) > 0 {
+			hosts = append(hosts, h)
+		}
+	}
+	if len(hosts) == 0 {
+		return fmt.Errorf("%v: -hosts is required", ErrInvalidArgument)
+	}
+	CACert, err := ioutil.ReadFile(filepath.Join(*Dir, tlsutil.CACertFile))
+	if err != nil {
+		return err
+	}
+	CAKey, err := ioutil.ReadFile(filepath.Join(*Dir, tlsutil.CAKeyFile))
+	if err != nil {
+		return err
+	}
+	Cert, Key, err := tlsutil.IssueCert(CACert, CAKey, *Name, hosts, *Days)
+	if err != nil {
+		return err
+	}
+	CertPath, KeyPath, err := tlsutil.WritePair(*Dir, *Name, Cert, Key)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Cert :", CertPath)
+	fmt.Println("Key :", KeyPath)
+	return nil
+}
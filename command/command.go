@@ -0,0 +1,31 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+)
+
+// IsCommand checks that the args starts with a subcommand or not
+func IsCommand(args []string) bool {
+	return len(args) > 0 && !strings.HasPrefix(args[0], "-")
+}
+
+// Run executes the subcommand of the args
+// The cfg is the config of the daemon that has the default values
+func Run(args []string, cfg Validator) error {
+	if len(args) == 0 {
+		return ErrNotExistCommand
+	}
+	switch args[0] {
+	case "genesis":
+		return runGenesis(args[1:])
+	case "key":
+		return runKey(args[1:])
+	case "config":
+		return runConfig(args[1:], cfg)
+	case "cert":
+		return runCert(args[1:])
+	default:
+		return fmt.Errorf("%v: %s", ErrUnknownCommand, args[0])
+	}
+}
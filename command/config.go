@@ -0,0 +1,304 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	toml "github.com/pelletier/go-toml"
+)
+
+// EnvPrefix is the prefix of the environment variables of the config
+const EnvPrefix = "FLETA_"
+
+// DefaultConfigPath is the path of the config file when it is not given
+const DefaultConfigPath = "./config.toml"
+
+// LoadConfig loads the config from the file, the environment variables and the flags of the args
+// The priority is flag > environment variable > file > the value of the cfg before loading
+// The flag name and the environment variable name of a field are derived from the field name (APIPort : --api-port, FLETA_API_PORT)
+// and the flag name can be overridden by the `flag` tag
+// The fields of the embedded structs are loaded as the fields of the cfg
+// The relative path of the field that has the `config:"path"` tag and the relative unix:// path of the field that has the `config:"socket"` tag
+// are resolved against the directory of the config file when they are given by the file
+func LoadConfig(args []string, cfg interface{}) error {
+	rv := reflect.ValueOf(cfg)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return ErrInvalidConfigType
+	}
+	fields, err := configFields(rv.Elem())
+	if err != nil {
+		return err
+	}
+
+	fs := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	ConfigPath := fs.String("config", "", "path of the config file (default "+DefaultConfigPath+")")
+	for _, f := range fields {
+		fs.Var(f, f.FlagName, f.Usage())
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("%v: %s", ErrInvalidArgument, strings.Join(fs.Args(), " "))
+	}
+
+	if len(*ConfigPath) == 0 {
+		*ConfigPath = os.Getenv(EnvPrefix + "CONFIG")
+	}
+	if len(*ConfigPath) > 0 {
+		if err := loadConfigFile(*ConfigPath, rv.Elem(), fields); err != nil {
+			return err
+		}
+	} else if _, err := os.Stat(DefaultConfigPath); err == nil {
+		if err := loadConfigFile(DefaultConfigPath, rv.Elem(), fields); err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	for _, f := range fields {
+		if str, has := os.LookupEnv(f.EnvName); has {
+			v, err := f.parse(str)
+			if err != nil {
+				return fmt.Errorf("%s: %v", f.EnvName, err)
+			}
+			f.Value.Set(v)
+		}
+	}
+	var rerr error
+	fs.Visit(func(fl *flag.Flag) {
+		if rerr != nil {
+			return
+		}
+		if f, is := fl.Value.(*configField); is {
+			if err := f.apply(); err != nil {
+				rerr = fmt.Errorf("--%s: %v", f.FlagName, err)
+			}
+		}
+	})
+	return rerr
+}
+
+func loadConfigFile(path string, rv reflect.Value, fields []*configField) error {
+	tree, err := toml.LoadFile(path)
+	if err != nil {
+		return err
+	}
+	// the file is unmarshaled to the flat struct of the fields because the embedded structs are not flattened by toml
+	sfs := make([]reflect.StructField, 0, len(fields))
+	for _, f := range fields {
+		sfs = append(sfs, reflect.StructField{Name: f.Name, Type: f.Value.Type()})
+	}
+	loaded := reflect.New(reflect.StructOf(sfs))
+	if err := tree.Unmarshal(loaded.Interface()); err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+	for _, f := range fields {
+		if tree.Has(f.Name) {
+			f.Value.Set(loaded.Elem().FieldByName(f.Name))
+			switch f.Tag {
+			case "path":
+				f.Value.SetString(resolvePath(dir, f.Value.String()))
+			case "socket":
+				if str := f.Value.String(); strings.HasPrefix(str, "unix://") {
+					f.Value.SetString("unix://" + resolvePath(dir, strings.TrimPrefix(str, "unix://")))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// resolvePath joins the relative path to the dir
+func resolvePath(dir string, path string) string {
+	if len(path) == 0 || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// configField is a field of the config that is used as a flag.Value
+// The flag value is kept until apply is called to give the flag the highest priority
+type configField struct {
+	Name     string
+	FlagName string
+	EnvName  string
+	Value    reflect.Value
+	Tag      string
+	flagged  []string
+}
+
+func configFields(rv reflect.Value) ([]*configField, error) {
+	rt := rv.Type()
+	fields := []*configField{}
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if len(sf.PkgPath) > 0 {
+			continue
+		}
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			sub, err := configFields(rv.Field(i))
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, sub...)
+			continue
+		}
+		switch sf.Type.Kind() {
+		case reflect.String, reflect.Bool, reflect.Int:
+		case reflect.Slice:
+			if sf.Type.Elem().Kind() != reflect.String {
+				return nil, fmt.Errorf("%v: %s", ErrInvalidConfigType, sf.Name)
+			}
+		case reflect.Map:
+			if sf.Type.Key().Kind() != reflect.String || sf.Type.Elem().Kind() != reflect.String {
+				return nil, fmt.Errorf("%v: %s", ErrInvalidConfigType, sf.Name)
+			}
+		default:
+			return nil, fmt.Errorf("%v: %s", ErrInvalidConfigType, sf.Name)
+		}
+		words := splitWords(sf.Name)
+		FlagName := strings.ToLower(strings.Join(words, "-"))
+		if tag := sf.Tag.Get("flag"); len(tag) > 0 {
+			FlagName = tag
+		}
+		Tag := sf.Tag.Get("config")
+		if len(Tag) > 0 && (sf.Type.Kind() != reflect.String || (Tag != "path" && Tag != "socket")) {
+			return nil, fmt.Errorf("%v: %s", ErrInvalidConfigType, sf.Name)
+		}
+		fields = append(fields, &configField{
+			Name:     sf.Name,
+			FlagName: FlagName,
+			EnvName:  EnvPrefix + strings.ToUpper(strings.Replace(FlagName, "-", "_", -1)),
+			Value:    rv.Field(i),
+			Tag:      Tag,
+		})
+	}
+	return fields, nil
+}
+
+// Usage returns the usage of the flag
+func (f *configField) Usage() string {
+	switch f.Value.Kind() {
+	case reflect.Slice:
+		return f.Name + " as comma separated values (env " + f.EnvName + ")"
+	case reflect.Map:
+		return f.Name + " as comma separated key=value pairs (env " + f.EnvName + ")"
+	default:
+		return f.Name + " (env " + f.EnvName + ")"
+	}
+}
+
+// String returns the string of the value
+func (f *configField) String() string {
+	if f == nil || !f.Value.IsValid() {
+		return ""
+	}
+	switch f.Value.Kind() {
+	case reflect.Slice:
+		return strings.Join(f.Value.Interface().([]string), ",")
+	case reflect.Map:
+		m := f.Value.Interface().(map[string]string)
+		list := make([]string, 0, len(m))
+		for k, v := range m {
+			list = append(list, k+"="+v)
+		}
+		sort.Strings(list)
+		return strings.Join(list, ",")
+	default:
+		return fmt.Sprint(f.Value.Interface())
+	}
+}
+
+// IsBoolFlag supports the flag without a value for the bool field
+func (f *configField) IsBoolFlag() bool {
+	return f.Value.Kind() == reflect.Bool
+}
+
+// Set validates the flag value and keeps it until apply is called
+func (f *configField) Set(str string) error {
+	if _, err := f.parse(str); err != nil {
+		return err
+	}
+	f.flagged = append(f.flagged, str)
+	return nil
+}
+
+func (f *configField) apply() error {
+	for _, str := range f.flagged {
+		v, err := f.parse(str)
+		if err != nil {
+			return err
+		}
+		f.Value.Set(v)
+	}
+	return nil
+}
+
+func (f *configField) parse(str string) (reflect.Value, error) {
+	switch f.Value.Kind() {
+	case reflect.String:
+		return reflect.ValueOf(str).Convert(f.Value.Type()), nil
+	case reflect.Bool:
+		v, err := strconv.ParseBool(str)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(v).Convert(f.Value.Type()), nil
+	case reflect.Int:
+		v, err := strconv.Atoi(str)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(v).Convert(f.Value.Type()), nil
+	case reflect.Slice:
+		list := []string{}
+		for _, v := range strings.Split(str, ",") {
+			if v = strings.TrimSpace(v); len(v) > 0 {
+				list = append(list, v)
+			}
+		}
+		return reflect.ValueOf(list).Convert(f.Value.Type()), nil
+	case reflect.Map:
+		m := map[string]string{}
+		for _, v := range strings.Split(str, ",") {
+			if v = strings.TrimSpace(v); len(v) == 0 {
+				continue
+			}
+			ls := strings.SplitN(v, "=", 2)
+			if len(ls) != 2 {
+				return reflect.Value{}, fmt.Errorf("%v: %s is not a key=value pair", ErrInvalidArgument, v)
+			}
+			m[strings.TrimSpace(ls[0])] = strings.TrimSpace(ls[1])
+		}
+		return reflect.ValueOf(m).Convert(f.Value.Type()), nil
+	default:
+		return reflect.Value{}, ErrInvalidConfigType
+	}
+}
+
+// splitWords splits the camel case name to words (ObserverKeyMap : Observer, Key, Map / APIPort : API, Port)
+func splitWords(name string) []string {
+	rs := []rune(name)
+	words := []string{}
+	start := 0
+	for i := 1; i < len(rs); i++ {
+		if !unicode.IsUpper(rs[i]) {
+			continue
+		}
+		if !unicode.IsUpper(rs[i-1]) || (i+1 < len(rs) && unicode.IsLower(rs[i+1])) {
+			words = append(words, string(rs[start:i]))
+			start = i
+		}
+	}
+	return append(words, string(rs[start:]))
+}
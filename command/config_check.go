@@ -0,0 +1,33 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+)
+
+func runConfig(args []string, cfg Validator) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%v: config requires a subcommand (check)", ErrInvalidArgument)
+	}
+	switch args[0] {
+	case "check":
+		return runConfigCheck(args[1:], cfg)
+	default:
+		return fmt.Errorf("%v: config %s", ErrUnknownCommand, args[0])
+	}
+}
+
+// runConfigCheck loads the config in the same way as the daemon and validates it without starting the daemon
+func runConfigCheck(args []string, cfg Validator) error {
+	if err := LoadConfig(args, cfg); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	fmt.Println("config is valid")
+	return nil
+}
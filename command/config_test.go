@@ -0,0 +1,198 @@
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type testConfig struct {
+	Port           int
+	Name           string
+	Seeds          []string
+	KeyMap         map[string]string
+	GenesisFile    string `config:"path"`
+	StoreRoot      string `config:"path"`
+	SignerEndpoint string `config:"socket"`
+}
+
+func writeTestConfig(t *testing.T, body string) (string, string) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "config.toml")
+	if err := ioutil.WriteFile(path, []byte(body), 0600); err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return dir, path
+}
+
+func TestLoadConfigRelativePath(t *testing.T) {
+	dir, path := writeTestConfig(t, `
+GenesisFile = "../genesis.toml"
+StoreRoot = "/var/fleta"
+SignerEndpoint = "unix://./signer.sock"
+`)
+	defer os.RemoveAll(dir)
+
+	cfg := &testConfig{}
+	if err := LoadConfig([]string{"--config", path}, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if expected := filepath.Join(dir, "..", "genesis.toml"); cfg.GenesisFile != expected {
+		t.Errorf("GenesisFile is %s, expected %s", cfg.GenesisFile, expected)
+	}
+	if cfg.StoreRoot != "/var/fleta" {
+		t.Errorf("StoreRoot is %s, expected /var/fleta", cfg.StoreRoot)
+	}
+	if expected := "unix://" + filepath.Join(dir, "signer.sock"); cfg.SignerEndpoint != expected {
+		t.Errorf("SignerEndpoint is %s, expected %s", cfg.SignerEndpoint, expected)
+	}
+}
+
+func TestLoadConfigFlagPath(t *testing.T) {
+	dir, path := writeTestConfig(t, `
+StoreRoot = "./data"
+SignerEndpoint = "127.0.0.1:7000"
+`)
+	defer os.RemoveAll(dir)
+
+	cfg := &testConfig{}
+	if err := LoadConfig([]string{"--config", path, "--genesis-file", "./genesis.toml"}, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.GenesisFile != "./genesis.toml" {
+		t.Errorf("GenesisFile is %s, expected the flag value as it is", cfg.GenesisFile)
+	}
+	if expected := filepath.Join(dir, "data"); cfg.StoreRoot != expected {
+		t.Errorf("StoreRoot is %s, expected %s", cfg.StoreRoot, expected)
+	}
+	if cfg.SignerEndpoint != "127.0.0.1:7000" {
+		t.Errorf("SignerEndpoint is %s, expected the tcp address as it is", cfg.SignerEndpoint)
+	}
+}
+
+func TestLoadConfigEmbedded(t *testing.T) {
+	dir, path := writeTestConfig(t, `
+Port = 7000
+StoreRoot = "./data"
+LogLevel = "debug"
+`)
+	defer os.RemoveAll(dir)
+
+	cfg := &struct {
+		Port int
+		StoreConfig
+		LogConfig
+	}{}
+	if err := LoadConfig([]string{"--config", path, "--log-format", "json"}, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Port != 7000 {
+		t.Errorf("Port is %d, expected 7000", cfg.Port)
+	}
+	if expected := filepath.Join(dir, "data"); cfg.StoreRoot != expected {
+		t.Errorf("StoreRoot is %s, expected %s", cfg.StoreRoot, expected)
+	}
+	if cfg.LogLevel != "debug" {
+		t.Errorf("LogLevel is %s, expected debug", cfg.LogLevel)
+	}
+	if cfg.LogFormat != "json" {
+		t.Errorf("LogFormat is %s, expected json", cfg.LogFormat)
+	}
+}
+
+func TestLoadConfigInvalidPathTag(t *testing.T) {
+	cfg := &struct {
+		Port int `config:"path"`
+	}{}
+	if err := LoadConfig([]string{}, cfg); err == nil {
+		t.Fatal("the path tag of the int field is accepted")
+	}
+}
+
+func TestLoadConfigPriority(t *testing.T) {
+	dir, path := writeTestConfig(t, `
+Port = 7000
+Name = "file"
+Seeds = ["file:1"]
+
+[KeyMap]
+a = "file"
+`)
+	defer os.RemoveAll(dir)
+
+	os.Setenv("FLETA_NAME", "env")
+	os.Setenv("FLETA_SEEDS", "env:1, env:2")
+	defer os.Unsetenv("FLETA_NAME")
+	defer os.Unsetenv("FLETA_SEEDS")
+
+	cfg := &testConfig{Port: 1, Name: "default", StoreRoot: "./default"}
+	if err := LoadConfig([]string{"--config", path, "--seeds", "flag:1", "--key-map", "b=flag"}, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Port != 7000 {
+		t.Errorf("Port is %d, expected the file value 7000", cfg.Port)
+	}
+	if cfg.Name != "env" {
+		t.Errorf("Name is %s, expected the environment variable", cfg.Name)
+	}
+	if len(cfg.Seeds) != 1 || cfg.Seeds[0] != "flag:1" {
+		t.Errorf("Seeds is %v, expected the flag value", cfg.Seeds)
+	}
+	if len(cfg.KeyMap) != 1 || cfg.KeyMap["b"] != "flag" {
+		t.Errorf("KeyMap is %v, expected the flag value", cfg.KeyMap)
+	}
+	if cfg.StoreRoot != "./default" {
+		t.Errorf("StoreRoot is %s, expected the value before loading", cfg.StoreRoot)
+	}
+}
+
+func TestLoadConfigInvalidValue(t *testing.T) {
+	dir, path := writeTestConfig(t, `Port = 7000`)
+	defer os.RemoveAll(dir)
+
+	os.Setenv("FLETA_PORT", "port")
+	defer os.Unsetenv("FLETA_PORT")
+	if err := LoadConfig([]string{"--config", path}, &testConfig{}); err == nil {
+		t.Error("the invalid environment variable is accepted")
+	}
+	os.Unsetenv("FLETA_PORT")
+
+	os.Setenv("FLETA_KEY_MAP", "a")
+	defer os.Unsetenv("FLETA_KEY_MAP")
+	if err := LoadConfig([]string{"--config", path}, &testConfig{}); err == nil {
+		t.Error("the invalid key=value pair is accepted")
+	}
+	os.Unsetenv("FLETA_KEY_MAP")
+
+	if err := LoadConfig([]string{"--config", path, "--key-map", "a"}, &testConfig{}); err == nil {
+		t.Error("the invalid key=value flag is accepted")
+	}
+	if err := LoadConfig([]string{"--config", path, "--unknown"}, &testConfig{}); err == nil {
+		t.Error("the unknown flag is accepted")
+	}
+
+	if err := LoadConfig([]string{"--config", path, "extra"}, &testConfig{}); err == nil {
+		t.Error("the extra argument is accepted")
+	}
+}
+
+func TestSplitWords(t *testing.T) {
+	tests := map[string]string{
+		"ObserverKeyMap": "Observer Key Map",
+		"APIPort":        "API Port",
+		"PeerTLSCA":      "Peer TLSCA",
+		"Port":           "Port",
+	}
+	for name, expected := range tests {
+		if words := strings.Join(splitWords(name), " "); words != expected {
+			t.Errorf("%s is split to %s, expected %s", name, words, expected)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package command
+
+import (
+	"time"
+
+	"github.com/fletaio/cmd/api"
+	"github.com/fletaio/cmd/chain"
+	"github.com/fletaio/cmd/logging"
+)
+
+// APIConfig is the config of the api and the metrics of the daemon
+// The ports are checked by the daemon with its other ports
+type APIConfig struct {
+	APIPort          int
+	APIBind          string
+	APIKeys          map[string]string
+	APIJWTSecret     string
+	APIPublicGroups  []string
+	APITLSCert       string `flag:"api-tls-cert" config:"path"`
+	APITLSKey        string `flag:"api-tls-key" config:"path"`
+	APIMaxBatchSize  int
+	APIMaxBodySize   int
+	APICallTimeout   int
+	APIRateLimits    map[string]string
+	APIKeyRateLimits map[string]string
+	APIAllowOrigins  []string
+	MetricsPort      int
+	MetricsBind      string
+	ReadyMaxLag      int
+}
+
+// APILimits returns the limits of the api requests
+// The zero value is replaced by the default limit
+func (c *APIConfig) APILimits() api.Limits {
+	return api.Limits{
+		MaxBatchSize: c.APIMaxBatchSize,
+		MaxBodySize:  int64(c.APIMaxBodySize),
+		CallTimeout:  time.Duration(c.APICallTimeout) * time.Second,
+	}
+}
+
+// RateLimiter returns the rate limiter of the api calls, and nil is returned when no limit is given
+func (c *APIConfig) RateLimiter() (*api.RateLimiter, error) {
+	if len(c.APIRateLimits) == 0 && len(c.APIKeyRateLimits) == 0 {
+		return nil, nil
+	}
+	IPLimits, err := api.ParseRateLimits(c.APIRateLimits)
+	if err != nil {
+		return nil, err
+	}
+	KeyLimits, err := api.ParseRateLimits(c.APIKeyRateLimits)
+	if err != nil {
+		return nil, err
+	}
+	return api.NewRateLimiter(IPLimits, KeyLimits), nil
+}
+
+// CheckAPIConfig checks the access, the tls, the limits and the metrics of the api config
+func (es *ConfigErrors) CheckAPIConfig(c *APIConfig) {
+	es.CheckAPIAccess(c.APIBind, c.APIPublicGroups, c.APIKeys, c.APIJWTSecret)
+	es.CheckTLS("APITLSCert", "APITLSKey", c.APITLSCert, c.APITLSKey)
+	es.CheckAPILimits(c.APIMaxBatchSize, c.APIMaxBodySize, c.APICallTimeout)
+	es.CheckRateLimits("APIRateLimits", c.APIRateLimits)
+	es.CheckRateLimits("APIKeyRateLimits", c.APIKeyRateLimits)
+	es.CheckAllowOrigins(c.APIAllowOrigins)
+	es.CheckBind("MetricsBind", c.MetricsBind)
+	if c.ReadyMaxLag < 0 {
+		es.Addf("ReadyMaxLag", "negative lag %d", c.ReadyMaxLag)
+	}
+}
+
+// StoreConfig is the config of the genesis and the stores of the daemon
+type StoreConfig struct {
+	GenesisFile    string `config:"path"`
+	StoreRoot      string `config:"path"`
+	ForceRecover   bool
+	RecoveryPolicy string
+	SnapshotPath   string `config:"path"`
+}
+
+// KernelStoreConfig returns the config of the kernel store
+// ForceRecover is the truncate recovery policy when the recovery policy is not given
+func (c *StoreConfig) KernelStoreConfig() *chain.StoreConfig {
+	sc := &chain.StoreConfig{
+		Path:           c.StoreRoot + "/kernel",
+		RecoveryPolicy: c.RecoveryPolicy,
+		SnapshotPath:   c.SnapshotPath,
+	}
+	if c.ForceRecover && len(sc.RecoveryPolicy) == 0 {
+		sc.RecoveryPolicy = chain.RecoveryTruncate
+	}
+	return sc
+}
+
+// CheckStoreConfig checks the store root and the recovery policy of the store config
+// The genesis file is loaded by the daemon to check it with its other fields
+func (es *ConfigErrors) CheckStoreConfig(c *StoreConfig) {
+	if len(c.StoreRoot) == 0 {
+		es.Addf("StoreRoot", "store root is not given")
+	}
+	es.CheckRecoveryPolicy(c.RecoveryPolicy, c.SnapshotPath)
+}
+
+// LogConfig is the config of the loggers of the daemon
+type LogConfig struct {
+	LogLevel      string
+	LogLevels     map[string]string
+	LogFormat     string
+	LogFile       string `config:"path"`
+	LogMaxSize    int
+	LogMaxBackups int
+}
+
+// LoggingConfig returns the config of the loggers
+func (c *LogConfig) LoggingConfig() *logging.Config {
+	return &logging.Config{
+		Level:      c.LogLevel,
+		Levels:     c.LogLevels,
+		Format:     c.LogFormat,
+		File:       c.LogFile,
+		MaxSize:    c.LogMaxSize,
+		MaxBackups: c.LogMaxBackups,
+	}
+}
+
+// RewardConfig is the config of the reward policy of the chain
+type RewardConfig struct {
+	RewardMode          string
+	RewardCurve         string
+	RewardInitial       string
+	RewardHalvingBlocks int
+	RewardSupply        string
+	RewardBlocks        int
+}
+
+// RewardPolicy returns the reward policy of the chain
+func (c *RewardConfig) RewardPolicy() *chain.RewardPolicy {
+	return &chain.RewardPolicy{
+		Mode:          c.RewardMode,
+		Curve:         c.RewardCurve,
+		Initial:       c.RewardInitial,
+		HalvingBlocks: uint32(c.RewardHalvingBlocks),
+		Supply:        c.RewardSupply,
+		Blocks:        uint32(c.RewardBlocks),
+	}
+}
+
+// CheckRewardConfig checks the block counts of the reward config
+// The policy is checked against the genesis by CheckRewardPolicy
+func (es *ConfigErrors) CheckRewardConfig(c *RewardConfig) {
+	if c.RewardHalvingBlocks < 0 {
+		es.Addf("RewardHalvingBlocks", "negative blocks %d", c.RewardHalvingBlocks)
+	}
+	if c.RewardBlocks < 0 {
+		es.Addf("RewardBlocks", "negative blocks %d", c.RewardBlocks)
+	}
+}
@@ -0,0 +1,16 @@
+package command
+
+import (
+	"errors"
+)
+
+// command errors
+var (
+	ErrNotExistCommand   = errors.New("not exist command")
+	ErrUnknownCommand    = errors.New("unknown command")
+	ErrInvalidArgument   = errors.New("invalid argument")
+	ErrExistOutputPath   = errors.New("exist output path")
+	ErrInvalidConfigType = errors.New("invalid config type")
+	ErrInvalidConfig     = errors.New("invalid config")
+	ErrNotExistKey       = errors.New("not exist key")
+)
@@ -0,0 +1,286 @@
+package command
+
+import (
+	"bytes"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/fletaio/cmd/chain"
+	"github.com/fletaio/common"
+	"github.com/fletaio/core/key"
+)
+
+func runGenesis(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%v: genesis requires a subcommand (new)", ErrInvalidArgument)
+	}
+	switch args[0] {
+	case "new":
+		return runGenesisNew(args[1:])
+	default:
+		return fmt.Errorf("%v: genesis %s", ErrUnknownCommand, args[0])
+	}
+}
+
+// networkConfig is the configuration of the private network to generate
+type networkConfig struct {
+	Output            string
+	Host              string
+	ObserverCount     int
+	FormulatorCount   int
+	NodeCount         int
+	Port              int
+	APIPort           int
+	ObserverPort      int
+	FormulatorPort    int
+	AccountBalance    string
+	FormulatorBalance string
+}
+
+// generated file names
+const (
+	genesisFileName = "genesis.toml"
+	keysFileName    = "keys.toml"
+	configFileName  = "config.toml"
+	storeRoot       = "./data"
+)
+
+func runGenesisNew(args []string) error {
+	nc := &networkConfig{}
+	fs := flag.NewFlagSet("genesis new", flag.ContinueOnError)
+	fs.StringVar(&nc.Output, "out", "./network", "output directory of the generated files")
+	fs.StringVar(&nc.Host, "host", "127.0.0.1", "host of every daemon of the network")
+	fs.IntVar(&nc.ObserverCount, "observers", 5, "number of observers")
+	fs.IntVar(&nc.FormulatorCount, "formulators", 1, "number of formulators")
+	fs.IntVar(&nc.NodeCount, "nodes", 1, "number of nodes")
+	fs.IntVar(&nc.Port, "port", 31000, "first p2p port of formulators and nodes")
+	fs.IntVar(&nc.APIPort, "api-port", 58000, "first api port of every daemon")
+	fs.IntVar(&nc.ObserverPort, "observer-port", 35000, "first observer port of observers")
+	fs.IntVar(&nc.FormulatorPort, "formulator-port", 37000, "first formulator port of observers")
+	fs.StringVar(&nc.AccountBalance, "balance", "2000000000", "balance of the genesis account")
+	fs.StringVar(&nc.FormulatorBalance, "formulator-balance", "0", "balance of each formulator account")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if nc.ObserverCount < 1 {
+		return fmt.Errorf("%v: observers should be positive", ErrInvalidArgument)
+	}
+	if nc.FormulatorCount < 1 {
+		return fmt.Errorf("%v: formulators should be positive", ErrInvalidArgument)
+	}
+	if nc.NodeCount < 0 {
+		return fmt.Errorf("%v: nodes should not be negative", ErrInvalidArgument)
+	}
+	if _, err := os.Stat(nc.Output); err == nil {
+		return fmt.Errorf("%v: %s", ErrExistOutputPath, nc.Output)
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	return generateNetwork(nc)
+}
+
+type generatedKey struct {
+	Name    string
+	KeyHex  string
+	KeyHash string
+	Address string
+}
+
+func newGeneratedKey(Name string) (*generatedKey, error) {
+	k, err := key.NewMemoryKey()
+	if err != nil {
+		return nil, err
+	}
+	return &generatedKey{
+		Name:    Name,
+		KeyHex:  hex.EncodeToString(k.Bytes()),
+		KeyHash: common.NewPublicHash(k.PublicKey()).String(),
+	}, nil
+}
+
+func generateNetwork(nc *networkConfig) error {
+	acg := &chain.AccCoordGenerator{}
+	gen := &chain.Genesis{
+		ConsensusPolicy: chain.DefaultConsensusPolicy(),
+	}
+
+	account, err := newGeneratedKey("private")
+	if err != nil {
+		return err
+	}
+	account.Address = common.NewAddress(acg.Generate(), 0).String()
+	gen.Accounts = append(gen.Accounts, &chain.GenesisAccount{
+		Type:    chain.GenesisSingleAccount,
+		Address: account.Address,
+		KeyHash: account.KeyHash,
+		Name:    account.Name,
+		Balance: nc.AccountBalance,
+	})
+
+	formulators := make([]*generatedKey, 0, nc.FormulatorCount)
+	for i := 0; i < nc.FormulatorCount; i++ {
+		fk, err := newGeneratedKey(fmt.Sprintf("private%05d", i+1))
+		if err != nil {
+			return err
+		}
+		fk.Address = common.NewAddress(acg.Generate(), 0).String()
+		gen.Accounts = append(gen.Accounts, &chain.GenesisAccount{
+			Type:    chain.GenesisAlphaFormulator,
+			Address: fk.Address,
+			KeyHash: fk.KeyHash,
+			Name:    fk.Name,
+			Balance: nc.FormulatorBalance,
+		})
+		formulators = append(formulators, fk)
+	}
+
+	observers := make([]*generatedKey, 0, nc.ObserverCount)
+	for i := 0; i < nc.ObserverCount; i++ {
+		ob, err := newGeneratedKey("observer" + strconv.Itoa(i+1))
+		if err != nil {
+			return err
+		}
+		observers = append(observers, ob)
+	}
+
+	if err := os.MkdirAll(nc.Output, 0755); err != nil {
+		return err
+	}
+	GenesisPath := filepath.Join(nc.Output, genesisFileName)
+	if err := chain.SaveGenesis(GenesisPath, gen); err != nil {
+		return err
+	}
+	// the relative paths of the config are resolved against the directory of the config file
+	GenesisFile := filepath.ToSlash(filepath.Join("..", genesisFileName))
+
+	var keys bytes.Buffer
+	writeKeys(&keys, "Accounts", []*generatedKey{account})
+	writeKeys(&keys, "Formulators", formulators)
+	writeKeys(&keys, "Observers", observers)
+	if err := ioutil.WriteFile(filepath.Join(nc.Output, keysFileName), keys.Bytes(), 0600); err != nil {
+		return err
+	}
+
+	APIPort := nc.APIPort
+	ObserverNetAddrs := map[string]string{}
+	FormulatorNetAddrs := map[string]string{}
+	for i, ob := range observers {
+		ObserverNetAddrs[ob.KeyHash] = nc.Host + ":" + strconv.Itoa(nc.ObserverPort+i)
+		FormulatorNetAddrs[ob.KeyHash] = nc.Host + ":" + strconv.Itoa(nc.FormulatorPort+i)
+	}
+	for i, ob := range observers {
+		var buffer bytes.Buffer
+		fmt.Fprintf(&buffer, "KeyHex = %q\n", ob.KeyHex)
+		fmt.Fprintf(&buffer, "ObseverPort = %d\n", nc.ObserverPort+i)
+		fmt.Fprintf(&buffer, "FormulatorPort = %d\n", nc.FormulatorPort+i)
+		fmt.Fprintf(&buffer, "APIPort = %d\n", APIPort)
+		fmt.Fprintf(&buffer, "GenesisFile = %q\n", GenesisFile)
+		fmt.Fprintf(&buffer, "StoreRoot = %q\n", storeRoot)
+		buffer.WriteString("\n[ObserverKeyMap]\n")
+		writeMap(&buffer, ObserverNetAddrs)
+		if err := writeConfig(nc, ob.Name, buffer.Bytes()); err != nil {
+			return err
+		}
+		APIPort++
+	}
+
+	Port := nc.Port
+	SeedNodes := make([]string, 0, nc.FormulatorCount+nc.NodeCount)
+	for i := 0; i < nc.FormulatorCount+nc.NodeCount; i++ {
+		SeedNodes = append(SeedNodes, nc.Host+":"+strconv.Itoa(nc.Port+i))
+	}
+	for i, fk := range formulators {
+		var buffer bytes.Buffer
+		writeSeedNodes(&buffer, SeedNodes, nc.Host+":"+strconv.Itoa(Port))
+		fmt.Fprintf(&buffer, "Port = %d\n", Port)
+		fmt.Fprintf(&buffer, "APIPort = %d\n", APIPort)
+		fmt.Fprintf(&buffer, "KeyHex = %q\n", fk.KeyHex)
+		fmt.Fprintf(&buffer, "Formulator = %q\n", fk.Address)
+		fmt.Fprintf(&buffer, "GenesisFile = %q\n", GenesisFile)
+		fmt.Fprintf(&buffer, "StoreRoot = %q\n", storeRoot)
+		buffer.WriteString("\n[ObserverKeyMap]\n")
+		writeMap(&buffer, FormulatorNetAddrs)
+		if err := writeConfig(nc, "formulator"+strconv.Itoa(i+1), buffer.Bytes()); err != nil {
+			return err
+		}
+		Port++
+		APIPort++
+	}
+
+	for i := 0; i < nc.NodeCount; i++ {
+		var buffer bytes.Buffer
+		writeSeedNodes(&buffer, SeedNodes, nc.Host+":"+strconv.Itoa(Port))
+		buffer.WriteString("ObserverKeys = [\n")
+		for _, ob := range observers {
+			fmt.Fprintf(&buffer, "\t%q,\n", ob.KeyHash)
+		}
+		buffer.WriteString("]\n")
+		fmt.Fprintf(&buffer, "Port = %d\n", Port)
+		fmt.Fprintf(&buffer, "APIPort = %d\n", APIPort)
+		fmt.Fprintf(&buffer, "GenesisFile = %q\n", GenesisFile)
+		fmt.Fprintf(&buffer, "StoreRoot = %q\n", storeRoot)
+		if err := writeConfig(nc, "node"+strconv.Itoa(i+1), buffer.Bytes()); err != nil {
+			return err
+		}
+		Port++
+		APIPort++
+	}
+
+	fmt.Println("Genesis :", GenesisPath)
+	fmt.Println("Account :", account.Address, account.KeyHash)
+	for _, fk := range formulators {
+		fmt.Println("Formulator :", fk.Address, fk.KeyHash)
+	}
+	for _, ob := range observers {
+		fmt.Println("Observer :", ob.KeyHash, ObserverNetAddrs[ob.KeyHash])
+	}
+	return nil
+}
+
+func writeConfig(nc *networkConfig, Name string, bs []byte) error {
+	dir := filepath.Join(nc.Output, Name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, configFileName), bs, 0600)
+}
+
+func writeSeedNodes(buffer *bytes.Buffer, SeedNodes []string, self string) {
+	buffer.WriteString("SeedNodes = [\n")
+	for _, v := range SeedNodes {
+		if v != self {
+			fmt.Fprintf(buffer, "\t%q,\n", v)
+		}
+	}
+	buffer.WriteString("]\n")
+}
+
+func writeMap(buffer *bytes.Buffer, m map[string]string) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(buffer, "%s = %q\n", k, m[k])
+	}
+}
+
+func writeKeys(buffer *bytes.Buffer, Table string, keys []*generatedKey) {
+	for _, k := range keys {
+		fmt.Fprintf(buffer, "[[%s]]\n", Table)
+		fmt.Fprintf(buffer, "Name = %q\n", k.Name)
+		if len(k.Address) > 0 {
+			fmt.Fprintf(buffer, "Address = %q\n", k.Address)
+		}
+		fmt.Fprintf(buffer, "KeyHash = %q\n", k.KeyHash)
+		fmt.Fprintf(buffer, "KeyHex = %q\n", k.KeyHex)
+		buffer.WriteString("\n")
+	}
+}
@@ -0,0 +1,203 @@
+package command
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/fletaio/cmd/keystore"
+	"github.com/fletaio/cmd/signer"
+	"github.com/fletaio/common"
+	"github.com/fletaio/core/key"
+)
+
+func runKey(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%v: key requires a subcommand (new, import, export-public)", ErrInvalidArgument)
+	}
+	switch args[0] {
+	case "new":
+		return runKeyNew(args[1:])
+	case "import":
+		return runKeyImport(args[1:])
+	case "export-public":
+		return runKeyExportPublic(args[1:])
+	default:
+		return fmt.Errorf("%v: key %s", ErrUnknownCommand, args[0])
+	}
+}
+
+func runKeyNew(args []string) error {
+	fs := flag.NewFlagSet("key new", flag.ContinueOnError)
+	Output := fs.String("out", "./key.json", "path of the keystore file")
+	KDF := fs.String("kdf", keystore.KDFScrypt, "key derivation function ("+keystore.KDFScrypt+", "+keystore.KDFArgon2id+")")
+	PassphraseFile := fs.String("passphrase-file", "", "path of the passphrase file (env "+keystore.PassphraseEnv+" or prompt when not given)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	k, err := key.NewMemoryKey()
+	if err != nil {
+		return err
+	}
+	return saveKey(k, *Output, *KDF, *PassphraseFile)
+}
+
+func runKeyImport(args []string) error {
+	fs := flag.NewFlagSet("key import", flag.ContinueOnError)
+	Output := fs.String("out", "./key.json", "path of the keystore file")
+	KeyHex := fs.String("hex", "", "hex private key to import")
+	KeyHexFile := fs.String("hex-file", "", "path of the file that has the hex private key to import")
+	KDF := fs.String("kdf", keystore.KDFScrypt, "key derivation function ("+keystore.KDFScrypt+", "+keystore.KDFArgon2id+")")
+	PassphraseFile := fs.String("passphrase-file", "", "path of the passphrase file (env "+keystore.PassphraseEnv+" or prompt when not given)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(*KeyHex) > 0 && len(*KeyHexFile) > 0 {
+		return fmt.Errorf("%v: only one of -hex and -hex-file is allowed", ErrInvalidArgument)
+	}
+	str := *KeyHex
+	if len(*KeyHexFile) > 0 {
+		bs, err := ioutil.ReadFile(*KeyHexFile)
+		if err != nil {
+			return err
+		}
+		str = string(bytes.TrimSpace(bs))
+	}
+	if len(str) == 0 {
+		return fmt.Errorf("%v: -hex or -hex-file is required", ErrInvalidArgument)
+	}
+	k, err := ParseKeyHex(str)
+	if err != nil {
+		return err
+	}
+	return saveKey(k, *Output, *KDF, *PassphraseFile)
+}
+
+func runKeyExportPublic(args []string) error {
+	fs := flag.NewFlagSet("key export-public", flag.ContinueOnError)
+	KeyFile := fs.String("key-file", "./key.json", "path of the keystore file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	kf, err := keystore.ReadFile(*KeyFile)
+	if err != nil {
+		return err
+	}
+	fmt.Println(kf.PublicHash)
+	return nil
+}
+
+func saveKey(k *key.MemoryKey, Output string, KDF string, PassphraseFile string) error {
+	passphrase, err := keystore.ReadPassphrase(PassphraseFile, true)
+	if err != nil {
+		return err
+	}
+	kf, err := keystore.Encrypt(k, passphrase, KDF)
+	if err != nil {
+		return err
+	}
+	if err := keystore.WriteFile(Output, kf); err != nil {
+		return err
+	}
+	fmt.Println("KeyFile :", Output)
+	fmt.Println("PublicHash :", kf.PublicHash)
+	return nil
+}
+
+// LoadKey returns the key of the hex string or the keystore file
+// The passphrase of the keystore file is read from the passphrase file, the environment variable or the prompt
+func LoadKey(KeyHex string, KeyFile string, KeyPassphraseFile string) (*key.MemoryKey, error) {
+	if len(KeyFile) == 0 {
+		return ParseKeyHex(KeyHex)
+	}
+	passphrase, err := keystore.ReadPassphrase(KeyPassphraseFile, false)
+	if err != nil {
+		return nil, err
+	}
+	return keystore.Load(KeyFile, passphrase)
+}
+
+// CheckKey checks that only one of the hex key and the keystore file is given and returns the public hash of it
+// It does not decrypt the keystore file because the public hash is stored as plaintext
+func (es *ConfigErrors) CheckKey(KeyHex string, KeyFile string) (common.PublicHash, bool) {
+	switch {
+	case len(KeyHex) > 0 && len(KeyFile) > 0:
+		es.Addf("KeyFile", "only one of KeyHex and KeyFile is allowed")
+	case len(KeyFile) > 0:
+		kf, err := keystore.ReadFile(KeyFile)
+		if err != nil {
+			es.Add("KeyFile", err)
+			return common.PublicHash{}, false
+		}
+		KeyHash, err := kf.KeyHash()
+		if err != nil {
+			es.Add("KeyFile", err)
+			return common.PublicHash{}, false
+		}
+		return KeyHash, true
+	case len(KeyHex) > 0:
+		k, err := ParseKeyHex(KeyHex)
+		if err != nil {
+			es.Add("KeyHex", err)
+			return common.PublicHash{}, false
+		}
+		return common.NewPublicHash(k.PublicKey()), true
+	default:
+		es.Addf("KeyFile", "KeyHex or KeyFile is required")
+	}
+	return common.PublicHash{}, false
+}
+
+// LoadSigningKey returns the remote key of the signer endpoint or the local key of the hex string or the keystore file
+// The public hash of the key should be one of the key hashes when they are given, so the signer of the other key is refused at the start
+func LoadSigningKey(KeyHex string, KeyFile string, KeyPassphraseFile string, SignerEndpoint string, KeyHashes ...common.PublicHash) (key.Key, error) {
+	var k key.Key
+	if len(SignerEndpoint) == 0 {
+		lk, err := LoadKey(KeyHex, KeyFile, KeyPassphraseFile)
+		if err != nil {
+			return nil, err
+		}
+		k = lk
+	} else {
+		rk, err := signer.NewRemoteKey(SignerEndpoint, signer.DefaultTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("signer %s: %v", SignerEndpoint, err)
+		}
+		k = rk
+	}
+	if len(KeyHashes) == 0 {
+		return k, nil
+	}
+	PublicHash := common.NewPublicHash(k.PublicKey())
+	for _, v := range KeyHashes {
+		if PublicHash.Equal(v) {
+			return k, nil
+		}
+	}
+	return nil, fmt.Errorf("%v: key hash %s is not the expected key hash %s", signer.ErrMismatchPublicKey, PublicHash.String(), joinPublicHashes(KeyHashes))
+}
+
+func joinPublicHashes(KeyHashes []common.PublicHash) string {
+	strs := make([]string, 0, len(KeyHashes))
+	for _, v := range KeyHashes {
+		strs = append(strs, v.String())
+	}
+	return strings.Join(strs, ", ")
+}
+
+// CheckSigningKey checks that only one of the hex key, the keystore file and the signer endpoint is given
+// It returns the public hash of the local key and false when the signer is used, and the key of the signer is checked by LoadSigningKey
+func (es *ConfigErrors) CheckSigningKey(KeyHex string, KeyFile string, SignerEndpoint string) (common.PublicHash, bool) {
+	if len(SignerEndpoint) == 0 {
+		return es.CheckKey(KeyHex, KeyFile)
+	}
+	if len(KeyHex) > 0 || len(KeyFile) > 0 {
+		es.Addf("SignerEndpoint", "only one of KeyHex, KeyFile and SignerEndpoint is allowed")
+	}
+	if err := signer.CheckEndpoint(SignerEndpoint); err != nil {
+		es.Add("SignerEndpoint", err)
+	}
+	return common.PublicHash{}, false
+}
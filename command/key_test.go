@@ -0,0 +1,72 @@
+package command
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fletaio/cmd/signer"
+	"github.com/fletaio/common"
+	"github.com/fletaio/core/key"
+)
+
+func TestLoadSigningKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "command")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	k, err := key.NewMemoryKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := key.NewMemoryKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	KeyHash := common.NewPublicHash(k.PublicKey())
+	OtherHash := common.NewPublicHash(other.PublicKey())
+
+	guard, err := signer.NewGuard(filepath.Join(dir, "guard.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	audit, err := signer.NewAuditLog(filepath.Join(dir, "audit.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := signer.NewServer(k, guard, audit, false)
+	defer s.Close()
+	SocketPath := filepath.Join(dir, "signer.sock")
+	go s.Run("unix://" + SocketPath)
+	for i := 0; i < 100; i++ {
+		if _, err := os.Stat(SocketPath); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	Endpoint := "unix://" + SocketPath
+
+	if _, err := LoadSigningKey("", "", "", Endpoint, OtherHash, KeyHash); err != nil {
+		t.Fatalf("the signer of the expected key is refused: %v", err)
+	}
+	if _, err := LoadSigningKey("", "", "", Endpoint); err != nil {
+		t.Fatalf("the signer is refused without the key hashes: %v", err)
+	}
+	if _, err := LoadSigningKey("", "", "", Endpoint, OtherHash); err == nil || !strings.Contains(err.Error(), signer.ErrMismatchPublicKey.Error()) {
+		t.Fatalf("the signer of the other key is not refused: %v", err)
+	}
+
+	KeyHex := hex.EncodeToString(other.Bytes())
+	if _, err := LoadSigningKey(KeyHex, "", "", "", KeyHash); err == nil || !strings.Contains(err.Error(), signer.ErrMismatchPublicKey.Error()) {
+		t.Fatalf("the local key of the other key is not refused: %v", err)
+	}
+	if _, err := LoadSigningKey(KeyHex, "", "", "", OtherHash); err != nil {
+		t.Fatalf("the local key of the expected key is refused: %v", err)
+	}
+}
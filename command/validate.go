@@ -0,0 +1,270 @@
+package command
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/fletaio/cmd/api"
+	"github.com/fletaio/cmd/chain"
+	"github.com/fletaio/cmd/logging"
+	"github.com/fletaio/cmd/tlsutil"
+	"github.com/fletaio/core/key"
+)
+
+// Validator is the config that can be validated before starting the daemon
+type Validator interface {
+	Validate() error
+}
+
+// ConfigError is a problem of a field of the config
+type ConfigError struct {
+	Field string
+	Err   error
+}
+
+// Error returns the message of the error with the field name
+func (e *ConfigError) Error() string {
+	return e.Field + ": " + e.Err.Error()
+}
+
+// ConfigErrors collects every problem of the config to report them together
+type ConfigErrors []*ConfigError
+
+// Add appends the problem of the field
+func (es *ConfigErrors) Add(Field string, err error) {
+	*es = append(*es, &ConfigError{Field: Field, Err: err})
+}
+
+// Addf appends the problem of the field with the formatted message
+func (es *ConfigErrors) Addf(Field string, format string, args ...interface{}) {
+	es.Add(Field, fmt.Errorf(format, args...))
+}
+
+// Error returns the messages of every problem line by line
+func (es ConfigErrors) Error() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(ErrInvalidConfig.Error())
+	for _, e := range es {
+		buffer.WriteString("\n  ")
+		buffer.WriteString(e.Error())
+	}
+	return buffer.String()
+}
+
+// Err returns nil when there is no problem
+func (es ConfigErrors) Err() error {
+	if len(es) == 0 {
+		return nil
+	}
+	return es
+}
+
+// PortField is a port of the config with the field name
+// The optional port is disabled by zero
+type PortField struct {
+	Field    string
+	Port     int
+	Optional bool
+}
+
+// CheckPorts checks the range of the ports and the collision between them
+func (es *ConfigErrors) CheckPorts(ports ...PortField) {
+	used := map[int]string{}
+	for _, p := range ports {
+		if p.Optional && p.Port == 0 {
+			continue
+		}
+		if p.Port <= 0 || p.Port > 65535 {
+			es.Addf(p.Field, "port %d is out of range (1 ~ 65535)", p.Port)
+			continue
+		}
+		if Field, has := used[p.Port]; has {
+			es.Addf(p.Field, "port %d is already used by %s", p.Port, Field)
+			continue
+		}
+		used[p.Port] = p.Field
+	}
+}
+
+// CheckNetAddr checks that the address is the host:port form
+func (es *ConfigErrors) CheckNetAddr(Field string, addr string) {
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		es.Addf(Field, "invalid address %q: %v", addr, err)
+		return
+	}
+	if p, err := strconv.Atoi(port); err != nil || p <= 0 || p > 65535 {
+		es.Addf(Field, "invalid port of the address %q", addr)
+	}
+}
+
+// CheckRecoveryPolicy checks the recovery policy of the kernel store and the snapshot path of it
+func (es *ConfigErrors) CheckRecoveryPolicy(policy string, SnapshotPath string) {
+	if err := chain.CheckRecoveryPolicy(policy, SnapshotPath); err == chain.ErrNotExistSnapshotPath {
+		es.Addf("SnapshotPath", "snapshot path is required by the %s recovery policy", chain.RecoverySnapshot)
+	} else if err != nil {
+		es.Add("RecoveryPolicy", err)
+	}
+}
+
+// CheckBind checks that the bind address is empty, localhost or the IP address
+func (es *ConfigErrors) CheckBind(Field string, Bind string) {
+	if len(Bind) > 0 && Bind != "localhost" && net.ParseIP(Bind) == nil {
+		es.Addf(Field, "invalid bind address %q: IP address is expected", Bind)
+	}
+}
+
+// CheckAPIAccess checks the bind address and the access control of the api
+func (es *ConfigErrors) CheckAPIAccess(Bind string, PublicGroups []string, Keys map[string]string, JWTSecret string) {
+	es.CheckBind("APIBind", Bind)
+	if len(PublicGroups) != 1 || PublicGroups[0] != api.GroupNone {
+		if _, err := api.ParseGroups(PublicGroups); err != nil {
+			es.Addf("APIPublicGroups", "%v: %v", err, PublicGroups)
+		}
+	}
+	for k, v := range Keys {
+		if len(k) < api.MinAPIKeyLength {
+			es.Addf("APIKeys", "api key should be longer than %d characters", api.MinAPIKeyLength-1)
+		}
+		if _, err := api.ParseGroups(strings.Fields(v)); err != nil {
+			es.Addf("APIKeys", "%v: %q", err, v)
+		}
+	}
+	if len(JWTSecret) > 0 && len(JWTSecret) < api.MinJWTSecretLength {
+		es.Addf("APIJWTSecret", "jwt secret should be longer than %d characters", api.MinJWTSecretLength-1)
+	}
+}
+
+// CheckTLS checks the certificate and the private key files of the tls
+func (es *ConfigErrors) CheckTLS(CertField string, KeyField string, CertFile string, KeyFile string) {
+	if len(CertFile) == 0 && len(KeyFile) == 0 {
+		return
+	}
+	if len(CertFile) == 0 {
+		es.Addf(CertField, "certificate file is not given with %s", KeyField)
+		return
+	}
+	if len(KeyFile) == 0 {
+		es.Addf(KeyField, "private key file is not given with %s", CertField)
+		return
+	}
+	if _, err := tlsutil.LoadKeyPair(CertFile, KeyFile); err != nil {
+		es.Addf(CertField, "invalid key pair of %s and %s: %v", CertFile, KeyFile, err)
+	}
+}
+
+// CheckPeerTLS checks the mutual tls of the p2p links
+func (es *ConfigErrors) CheckPeerTLS(CertFile string, KeyFile string, CAFile string) {
+	if len(CertFile) == 0 && len(KeyFile) == 0 {
+		if len(CAFile) > 0 {
+			es.Addf("PeerTLSCert", "certificate file is not given with PeerTLSCA")
+		}
+		return
+	}
+	es.CheckTLS("PeerTLSCert", "PeerTLSKey", CertFile, KeyFile)
+	if len(CAFile) == 0 {
+		es.Addf("PeerTLSCA", "ca file is not given for the mutual tls")
+	} else if _, err := tlsutil.LoadCAPool(CAFile); err != nil {
+		es.Addf("PeerTLSCA", "invalid ca file %s: %v", CAFile, err)
+	}
+}
+
+// CheckAPILimits checks the limits of the api requests, and zero is the default limit
+func (es *ConfigErrors) CheckAPILimits(MaxBatchSize int, MaxBodySize int, CallTimeout int) {
+	if MaxBatchSize < 0 {
+		es.Addf("APIMaxBatchSize", "negative batch size %d", MaxBatchSize)
+	}
+	if MaxBodySize < 0 {
+		es.Addf("APIMaxBodySize", "negative body size %d", MaxBodySize)
+	}
+	if CallTimeout < 0 {
+		es.Addf("APICallTimeout", "negative call timeout %d", CallTimeout)
+	}
+}
+
+// CheckRateLimits checks the rate limits of the methods
+func (es *ConfigErrors) CheckRateLimits(Field string, Limits map[string]string) {
+	for k, v := range Limits {
+		if _, err := api.ParseRateLimit(v); err != nil {
+			es.Addf(Field, "%v of %s: %q is not the \"rate [burst]\" form", err, k, v)
+		}
+	}
+}
+
+// CheckAllowOrigins checks the origins allowed to the browser
+func (es *ConfigErrors) CheckAllowOrigins(Origins []string) {
+	for _, v := range Origins {
+		if v == "*" {
+			continue
+		}
+		u, err := url.Parse(v)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 || len(u.Path) > 0 {
+			es.Addf("APIAllowOrigins", "invalid origin %q: scheme://host[:port] is expected", v)
+		}
+	}
+}
+
+// CheckLogging checks the levels, the format and the file limits of the loggers
+func (es *ConfigErrors) CheckLogging(lc *logging.Config) {
+	if len(lc.Level) > 0 {
+		if _, err := logging.ParseLevel(lc.Level); err != nil {
+			es.Addf("LogLevel", "%v: %q", err, lc.Level)
+		}
+	}
+	for k, v := range lc.Levels {
+		if !logging.IsComponent(k) {
+			es.Addf("LogLevels", "%v: %q is not one of %s", logging.ErrUnknownComponent, k, strings.Join(logging.Components, ", "))
+		}
+		if _, err := logging.ParseLevel(v); err != nil {
+			es.Addf("LogLevels", "%v of %s: %q", err, k, v)
+		}
+	}
+	if lc.Format != "" && lc.Format != logging.FormatConsole && lc.Format != logging.FormatJSON {
+		es.Addf("LogFormat", "%v: %q is not %s or %s", logging.ErrUnknownFormat, lc.Format, logging.FormatConsole, logging.FormatJSON)
+	}
+	if len(lc.File) > 0 {
+		if info, err := os.Stat(filepath.Dir(lc.File)); err != nil || !info.IsDir() {
+			es.Addf("LogFile", "directory of the log file %s does not exist", lc.File)
+		}
+	}
+	if lc.MaxSize < 0 {
+		es.Addf("LogMaxSize", "negative size %d", lc.MaxSize)
+	}
+	if lc.MaxBackups < 0 {
+		es.Addf("LogMaxBackups", "negative backups %d", lc.MaxBackups)
+	}
+}
+
+// CheckRewardPolicy checks the reward policy with the consensus policy of the genesis
+func (es *ConfigErrors) CheckRewardPolicy(gen *chain.Genesis, rp *chain.RewardPolicy) {
+	if _, err := gen.RewardSchedule(rp); err != nil {
+		if pe, is := err.(*chain.RewardPolicyError); is {
+			es.Add("Reward"+pe.Field, pe.Err)
+		} else {
+			es.Add("GenesisFile", err)
+		}
+	}
+}
+
+// ParseKeyHex returns the key of the hex string
+func ParseKeyHex(str string) (*key.MemoryKey, error) {
+	if len(str) == 0 {
+		return nil, ErrNotExistKey
+	}
+	bs, err := hex.DecodeString(str)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hex: %v", err)
+	}
+	k, err := key.NewMemoryKeyFromBytes(bs)
+	if err != nil {
+		return nil, err
+	}
+	return k, nil
+}
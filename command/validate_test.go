@@ -0,0 +1,115 @@
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fletaio/cmd/api"
+	"github.com/fletaio/cmd/logging"
+)
+
+func checkFields(t *testing.T, name string, es ConfigErrors, fields ...string) {
+	t.Helper()
+	if len(es) != len(fields) {
+		t.Errorf("%s returns %v, expected the problems of %v", name, es.Err(), fields)
+		return
+	}
+	for i, e := range es {
+		if e.Field != fields[i] {
+			t.Errorf("%s returns the problem of %s, expected %s", name, e.Field, fields[i])
+		}
+	}
+}
+
+func TestConfigErrors(t *testing.T) {
+	var es ConfigErrors
+	if es.Err() != nil {
+		t.Fatal("the empty errors are not nil")
+	}
+	es.Addf("Port", "port %d is out of range", 0)
+	es.Addf("Name", "name is not given")
+	if expected := ErrInvalidConfig.Error() + "\n  Port: port 0 is out of range\n  Name: name is not given"; es.Err().Error() != expected {
+		t.Errorf("the message is %q, expected %q", es.Err().Error(), expected)
+	}
+}
+
+func TestCheckPorts(t *testing.T) {
+	var es ConfigErrors
+	es.CheckPorts(
+		PortField{Field: "Port", Port: 7000},
+		PortField{Field: "APIPort", Port: 7000},
+		PortField{Field: "MetricsPort", Optional: true},
+		PortField{Field: "PeerPort", Port: 70000},
+		PortField{Field: "ObserverPort"},
+	)
+	checkFields(t, "CheckPorts", es, "APIPort", "PeerPort", "ObserverPort")
+}
+
+func TestCheckAPIAccess(t *testing.T) {
+	var es ConfigErrors
+	es.CheckAPIAccess("localhost", []string{api.GroupNone}, map[string]string{"0123456789abcdef": "tx admin"}, "")
+	checkFields(t, "CheckAPIAccess", es)
+
+	es = nil
+	es.CheckAPIAccess("host", []string{"unknown"}, map[string]string{"short": "tx"}, "secret")
+	checkFields(t, "CheckAPIAccess", es, "APIBind", "APIPublicGroups", "APIKeys", "APIJWTSecret")
+}
+
+func TestCheckAPILimits(t *testing.T) {
+	var es ConfigErrors
+	es.CheckAPILimits(0, 0, 0)
+	checkFields(t, "CheckAPILimits", es)
+
+	es.CheckAPILimits(-1, -1, -1)
+	checkFields(t, "CheckAPILimits", es, "APIMaxBatchSize", "APIMaxBodySize", "APICallTimeout")
+}
+
+func TestCheckRateLimits(t *testing.T) {
+	var es ConfigErrors
+	es.CheckRateLimits("APIRateLimits", map[string]string{"*": "10", "Height": "1 5"})
+	checkFields(t, "CheckRateLimits", es)
+
+	es.CheckRateLimits("APIKeyRateLimits", map[string]string{"Height": "fast"})
+	checkFields(t, "CheckRateLimits", es, "APIKeyRateLimits")
+}
+
+func TestCheckAllowOrigins(t *testing.T) {
+	var es ConfigErrors
+	es.CheckAllowOrigins([]string{"*", "https://fleta.io", "http://localhost:3000"})
+	checkFields(t, "CheckAllowOrigins", es)
+
+	for _, v := range []string{"fleta.io", "ftp://fleta.io", "https://fleta.io/path", "https://"} {
+		es = nil
+		es.CheckAllowOrigins([]string{v})
+		checkFields(t, "CheckAllowOrigins of "+v, es, "APIAllowOrigins")
+	}
+}
+
+func TestCheckLogging(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logging")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var es ConfigErrors
+	es.CheckLogging(&logging.Config{
+		Level:  "debug",
+		Levels: map[string]string{logging.Kernel: "warn"},
+		Format: logging.FormatJSON,
+		File:   filepath.Join(dir, "node.log"),
+	})
+	checkFields(t, "CheckLogging", es)
+
+	es.CheckLogging(&logging.Config{
+		Level:      "loud",
+		Levels:     map[string]string{"unknown": "info"},
+		Format:     "xml",
+		File:       filepath.Join(dir, "log", "node.log"),
+		MaxSize:    -1,
+		MaxBackups: -1,
+	})
+	checkFields(t, "CheckLogging", es, "LogLevel", "LogLevels", "LogFormat", "LogFile", "LogMaxSize", "LogMaxBackups")
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"sort"
+
+	"github.com/fletaio/cmd/chain"
+	"github.com/fletaio/cmd/command"
+	"github.com/fletaio/common"
+)
+
+// Config is a configuration for the cmd
+type Config struct {
+	SeedNodes         []string
+	ObserverKeyMap    map[string]string
+	KeyHex            string
+	KeyFile           string `config:"path"`
+	KeyPassphraseFile string `config:"path"`
+	SignerEndpoint    string `config:"socket"`
+	Formulator        string
+	Port              int
+	PeerTLSCert       string `flag:"peer-tls-cert" config:"path"`
+	PeerTLSKey        string `flag:"peer-tls-key" config:"path"`
+	PeerTLSCA         string `flag:"peer-tls-ca" config:"path"`
+	command.APIConfig
+	command.StoreConfig
+	command.LogConfig
+	command.RewardConfig
+
+	genesis *chain.Genesis // the genesis loaded by Validate
+}
+
+// Validate checks every field of the config and reports all problems together
+func (cfg *Config) Validate() error {
+	var es command.ConfigErrors
+	KeyHash, hasKey := es.CheckSigningKey(cfg.KeyHex, cfg.KeyFile, cfg.SignerEndpoint)
+	var addr common.Address
+	if len(cfg.Formulator) == 0 {
+		es.Addf("Formulator", "formulator address is not given")
+	} else if a, err := common.ParseAddress(cfg.Formulator); err != nil {
+		es.Addf("Formulator", "invalid address %q: %v", cfg.Formulator, err)
+	} else {
+		addr = a
+	}
+	checkObserverKeyMap(&es, cfg.ObserverKeyMap)
+	for _, v := range cfg.SeedNodes {
+		es.CheckNetAddr("SeedNodes", v)
+	}
+	es.CheckPorts(
+		command.PortField{Field: "Port", Port: cfg.Port},
+		command.PortField{Field: "APIPort", Port: cfg.APIPort},
+		command.PortField{Field: "MetricsPort", Port: cfg.MetricsPort, Optional: true},
+	)
+	es.CheckStoreConfig(&cfg.StoreConfig)
+	es.CheckAPIConfig(&cfg.APIConfig)
+	es.CheckLogging(cfg.LoggingConfig())
+	es.CheckRewardConfig(&cfg.RewardConfig)
+	es.CheckPeerTLS(cfg.PeerTLSCert, cfg.PeerTLSKey, cfg.PeerTLSCA)
+	if gen, err := chain.LoadGenesis(cfg.GenesisFile); err != nil {
+		es.Add("GenesisFile", err)
+	} else {
+		cfg.genesis = gen
+		es.CheckRewardPolicy(gen, cfg.RewardPolicy())
+		// the formulator that is created after the genesis cannot be checked here
+		if hasKey && !addr.Equal(common.Address{}) {
+			if GenesisKeyHash, err := gen.FormulatorKeyHash(addr); err == nil && !KeyHash.Equal(GenesisKeyHash) {
+				es.Addf(cfg.keyField(), "key hash %s does not match the genesis key hash %s of the formulator %s", KeyHash.String(), GenesisKeyHash.String(), cfg.Formulator)
+			}
+		}
+	}
+	return es.Err()
+}
+
+func checkObserverKeyMap(es *command.ConfigErrors, ObserverKeyMap map[string]string) {
+	if len(ObserverKeyMap) == 0 {
+		es.Addf("ObserverKeyMap", "observer keys are not given")
+	}
+	keys := make([]string, 0, len(ObserverKeyMap))
+	for k := range ObserverKeyMap {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	addrMap := map[string]string{}
+	for _, k := range keys {
+		netAddr := ObserverKeyMap[k]
+		if _, err := chain.ParsePublicHash(k); err != nil {
+			es.Addf("ObserverKeyMap", "invalid observer key %q: %v", k, err)
+		}
+		es.CheckNetAddr("ObserverKeyMap", netAddr)
+		if prev, has := addrMap[netAddr]; has {
+			es.Addf("ObserverKeyMap", "address %q of %q is already used by %q", netAddr, k, prev)
+		}
+		addrMap[netAddr] = k
+	}
+}
+
+// keyField returns the field name of the key that is used
+func (cfg *Config) keyField() string {
+	if len(cfg.KeyFile) > 0 {
+		return "KeyFile"
+	}
+	return "KeyHex"
+}
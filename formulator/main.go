@@ -1,60 +1,72 @@
 package main
 
 import (
-	"encoding/hex"
+	"flag"
 	"fmt"
-	"net/http"
 	"os"
 	"os/signal"
-	"strconv"
-	"strings"
 	"syscall"
 
-	"github.com/dgraph-io/badger"
-
+	"github.com/fletaio/cmd/api"
+	"github.com/fletaio/cmd/chain"
+	"github.com/fletaio/cmd/command"
+	"github.com/fletaio/cmd/health"
+	"github.com/fletaio/cmd/logging"
+	"github.com/fletaio/cmd/metrics"
+	"github.com/fletaio/cmd/tlsutil"
 	"github.com/fletaio/common"
-	"github.com/fletaio/core/block"
-	"github.com/fletaio/core/data"
+	"github.com/fletaio/core/consensus"
 	"github.com/fletaio/core/formulator"
 	"github.com/fletaio/core/kernel"
-	"github.com/fletaio/core/key"
-	"github.com/fletaio/core/reward"
 	"github.com/fletaio/framework/closer"
-	"github.com/fletaio/framework/config"
 	"github.com/fletaio/framework/peer"
 	"github.com/fletaio/framework/router"
 	"github.com/fletaio/framework/router/evilnode"
 	"github.com/fletaio/framework/rpc"
 )
 
-// Config is a configuration for the cmd
-type Config struct {
-	SeedNodes      []string
-	ObserverKeyMap map[string]string
-	KeyHex         string
-	Formulator     string
-	Port           int
-	APIPort        int
-	StoreRoot      string
-	ForceRecover   bool
-}
-
 func main() {
-	var cfg Config
-	if err := config.LoadFile("./config.toml", &cfg); err != nil {
-		panic(err)
+	cfg := Config{
+		StoreConfig: command.StoreConfig{
+			StoreRoot: "./formulator",
+		},
+	}
+	if command.IsCommand(os.Args[1:]) {
+		if err := command.Run(os.Args[1:], &cfg); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+	if err := command.LoadConfig(os.Args[1:], &cfg); err != nil {
+		if err == flag.ErrHelp {
+			return
+		}
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if err := logging.Setup(cfg.LoggingConfig()); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
 	}
-	if len(cfg.StoreRoot) == 0 {
-		cfg.StoreRoot = "./formulator"
+
+	Formulator, err := common.ParseAddress(cfg.Formulator)
+	if err != nil {
+		logging.Get(logging.Main).Fatal("invalid formulator address", "address", cfg.Formulator, "error", err)
 	}
 
-	var frkey key.Key
-	if bs, err := hex.DecodeString(cfg.KeyHex); err != nil {
-		panic(err)
-	} else if Key, err := key.NewMemoryKeyFromBytes(bs); err != nil {
-		panic(err)
-	} else {
-		frkey = Key
+	// the key of the formulator that is created after the genesis is not in the genesis, so it is not checked here
+	KeyHashes := []common.PublicHash{}
+	if GenesisKeyHash, err := cfg.genesis.FormulatorKeyHash(Formulator); err == nil {
+		KeyHashes = append(KeyHashes, GenesisKeyHash)
+	}
+	frkey, err := command.LoadSigningKey(cfg.KeyHex, cfg.KeyFile, cfg.KeyPassphraseFile, cfg.SignerEndpoint, KeyHashes...)
+	if err != nil {
+		logging.Get(logging.Main).Fatal("failed to load the signing key", "error", err)
 	}
 
 	ObserverKeyMap := map[common.PublicHash]string{}
@@ -62,22 +74,15 @@ func main() {
 	for k, netAddr := range cfg.ObserverKeyMap {
 		pubhash, err := common.ParsePublicHash(k)
 		if err != nil {
-			panic(err)
+			logging.Get(logging.Main).Fatal("invalid observer key", "key", k, "error", err)
 		}
 		ObserverKeyMap[pubhash] = netAddr
 		ObserverKeyBoolMap[pubhash] = true
 	}
 
-	GenCoord := common.NewCoordinate(0, 0)
-	act := data.NewAccounter(GenCoord)
-	tran := data.NewTransactor(GenCoord)
-	evt := data.NewEventer(GenCoord)
-	if err := initChainComponent(act, tran, evt); err != nil {
-		panic(err)
-	}
-	GenesisContextData, err := initGenesisContextData(act, tran, evt)
+	bs, err := chain.NewBootstrap(cfg.genesis, cfg.RewardPolicy())
 	if err != nil {
-		panic(err)
+		logging.Get(logging.Kernel).Fatal("failed to build the genesis", "file", cfg.GenesisFile, "error", err)
 	}
 
 	cm := closer.NewManager()
@@ -93,48 +98,48 @@ func main() {
 	}()
 	defer cm.CloseAll()
 
-	var ks *kernel.Store
-	if s, err := kernel.NewStore(cfg.StoreRoot+"/kernel", BlockchainVersion, act, tran, evt, cfg.ForceRecover); err != nil {
-		if cfg.ForceRecover || err != badger.ErrTruncateNeeded {
-			panic(err)
-		} else {
-			fmt.Println(err)
-			fmt.Println("Do you want to recover database(it can be failed)? [y/n]")
-			var answer string
-			fmt.Scanf("%s", &answer)
-			if strings.ToLower(answer) == "y" {
-				if s, err := kernel.NewStore(cfg.StoreRoot+"/kernel", BlockchainVersion, act, tran, evt, true); err != nil {
-					panic(err)
-				} else {
-					ks = s
-				}
-			} else {
-				os.Exit(1)
-			}
-		}
-	} else {
-		ks = s
+	ks, err := bs.OpenStore(cfg.KernelStoreConfig())
+	if err != nil {
+		logging.Get(logging.Store).Fatal("failed to open the kernel store", "error", err)
 	}
 	cm.Add("kernel.Store", ks)
 
-	rd := reward.NewTestNetRewarder()
-	kn, err := kernel.NewKernel(&kernel.Config{
-		ChainCoord:              GenCoord,
-		ObserverKeyMap:          ObserverKeyBoolMap,
-		MaxBlocksPerFormulator:  8,
-		MaxTransactionsPerBlock: 5000,
-	}, ks, rd, GenesisContextData)
+	logging.Get(logging.Kernel).Info("reward policy", "mode", bs.RewardSchedule.Mode, "curve", bs.RewardSchedule.Curve, "hash", bs.RewardSchedule.Hash())
+	rd := chain.NewRewarder(bs.RewardSchedule)
+	kn, err := bs.NewKernel(ks, rd, ObserverKeyBoolMap)
 	if err != nil {
-		panic(err)
+		cm.CloseAll()
+		logging.Get(logging.Kernel).Fatal("failed to create the kernel", "error", err)
 	}
 	cm.RemoveAll()
 	cm.Add("kernel.Kernel", kn)
 
+	// the formulator connects the observers by the local tunnels of the mutual tls when it is given
+	tunnels := tlsutil.Tunnels{}
+	if len(cfg.PeerTLSCert) > 0 {
+		pt, err := tlsutil.LoadPeer(cfg.PeerTLSCert, cfg.PeerTLSKey, cfg.PeerTLSCA)
+		if err != nil {
+			cm.CloseAll()
+			logging.Get(logging.Peer).Fatal("failed to load the peer tls", "error", err)
+		}
+		for pubhash, netAddr := range ObserverKeyMap {
+			tn, err := pt.Open(netAddr)
+			if err != nil {
+				tunnels.Close()
+				cm.CloseAll()
+				logging.Get(logging.Tunnel).Fatal("failed to open the tunnel", "addr", netAddr, "error", err)
+			}
+			ObserverKeyMap[pubhash] = tn.Addr()
+			tunnels = append(tunnels, tn)
+		}
+		cm.Add("tlsutil.Tunnels", tunnels)
+	}
+
 	frcfg := &formulator.Config{
 		Key:            frkey,
 		SeedNodes:      cfg.SeedNodes,
 		ObserverKeyMap: ObserverKeyMap,
-		Formulator:     common.MustParseAddress(cfg.Formulator),
+		Formulator:     Formulator,
 		Router: router.Config{
 			Network: "tcp",
 			Port:    cfg.Port,
@@ -148,18 +153,44 @@ func main() {
 	}
 	fr, err := formulator.NewFormulator(frcfg, kn)
 	if err != nil {
-		panic(err)
+		cm.CloseAll()
+		logging.Get(logging.Main).Fatal("failed to create the formulator", "error", err)
 	}
 	cm.RemoveAll()
 	cm.Add("cmd.Formulator", fr)
+	cm.Add("tlsutil.Tunnels", tunnels)
 
 	go fr.Run()
 
-	rm := rpc.NewManager()
+	as, err := command.NewAPIServer(&cfg.APIConfig, kn, bs.Eventer)
+	if err != nil {
+		cm.CloseAll()
+		logging.Get(logging.RPC).Fatal("failed to create the api", "error", err)
+	}
+	rm := as.Manager
+	ObserverCount := metrics.MeshPeers(fr)
+	as.Health.AddReady("observers", func() (string, error) {
+		Count := ObserverCount()
+		detail := fmt.Sprintf("%d of %d observers are connected", Count, len(ObserverKeyMap))
+		if Count == 0 {
+			return detail, health.ErrNotConnected
+		}
+		return detail, nil
+	})
 	cm.RemoveAll()
-	cm.Add("rpc.Manager", rm)
+	cm.Add("health.Health", as.Health)
+	cm.Add("api.Manager", rm)
 	cm.Add("cmd.Formulator", fr)
-	kn.AddEventHandler(rm)
+	cm.Add("tlsutil.Tunnels", tunnels)
+
+	as.Chain.WatchFormulator(frcfg.Formulator)
+	metrics.WatchStores(as.Registry, map[string]string{
+		"kernel": cfg.StoreRoot + "/kernel",
+	})
+	metrics.WatchPeers(as.Registry, map[string]func() int{
+		"node":     metrics.ConnectedPeers(fr),
+		"observer": ObserverCount,
+	})
 
 	defer func() {
 		cm.CloseAll()
@@ -215,22 +246,36 @@ func main() {
 		if err != nil {
 			return nil, err
 		}
-		cd, err := kn.Provider().Data(height)
+		return api.BlockByHeight(kn.Provider(), height)
+	})
+	rm.Add("TxFeeTable", func(kn *kernel.Kernel, ID interface{}, arg *rpc.Argument) (interface{}, error) {
+		return bs.TxFeeTable, nil
+	})
+
+	// Consensus
+	rm.Add("ConsensusPolicy", func(kn *kernel.Kernel, ID interface{}, arg *rpc.Argument) (interface{}, error) {
+		policy, err := consensus.GetConsensusPolicy(kn.ChainCoord())
 		if err != nil {
 			return nil, err
 		}
-		b := &block.Block{
-			Header: cd.Header.(*block.Header),
-			Body:   cd.Body.(*block.Body),
+		return policy, nil
+	})
+	rm.Add("RewardSchedule", func(kn *kernel.Kernel, ID interface{}, arg *rpc.Argument) (interface{}, error) {
+		height := kn.Provider().Height() + 1
+		if arg.Len() > 0 {
+			h, err := arg.Uint32(0)
+			if err != nil {
+				return nil, err
+			}
+			height = h
 		}
-		return b, nil
+		return bs.RewardSchedule.Expected(height), nil
 	})
 
 	go func() {
-		if err := rm.Run(kn, ":"+strconv.Itoa(cfg.APIPort)); err != nil {
-			if http.ErrServerClosed != err {
-				panic(err)
-			}
+		if err := as.Run(kn); err != nil {
+			cm.CloseAll()
+			logging.Get(logging.RPC).Fatal("failed to serve the api", "error", err)
 		}
 	}()
 
@@ -1,4 +1,4 @@
-module github.com/fletaio/cmd/observer
+module github.com/fletaio/cmd
 
 go 1.12
 
@@ -14,11 +14,12 @@ require (
 	github.com/fletaio/network v0.0.0-20190401100130-91d2fc4f149e // indirect
 	github.com/fletaio/solidity v0.0.0-20190515055506-1a7e542da38e
 	github.com/golang/protobuf v1.3.1 // indirect
-	github.com/gorilla/websocket v1.4.0 // indirect
-	github.com/labstack/echo v3.3.10+incompatible // indirect
+	github.com/gorilla/websocket v1.4.0
+	github.com/labstack/echo v3.3.10+incompatible
 	github.com/labstack/gommon v0.2.9 // indirect
 	github.com/mr-tron/base58 v1.1.2 // indirect
-	github.com/pelletier/go-toml v1.4.0 // indirect
+	github.com/pelletier/go-toml v1.4.0
 	github.com/pkg/errors v0.8.1 // indirect
+	golang.org/x/crypto v0.0.0-20190308221718-c2843e01d9a2
 	golang.org/x/net v0.0.0-20190611141213-3f473d35a33a // indirect
 )
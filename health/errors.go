@@ -0,0 +1,13 @@
+package health
+
+import (
+	"errors"
+)
+
+// health errors
+var (
+	ErrClosing        = errors.New("closing")
+	ErrNotInitialized = errors.New("not initialized")
+	ErrNotSynced      = errors.New("not synced")
+	ErrNotConnected   = errors.New("not connected")
+)
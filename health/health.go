@@ -0,0 +1,122 @@
+package health
+
+import (
+	"sort"
+	"sync"
+)
+
+// statuses of the checks
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// Check returns the detail of the check, and the error when the check is failed
+type Check func() (string, error)
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Result is the result of a check
+type Result struct {
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Report is the results of the checks
+type Report struct {
+	Status string             `json:"status"`
+	Checks map[string]*Result `json:"checks"`
+}
+
+// IsOK returns true when every check is passed
+func (rp *Report) IsOK() bool {
+	return rp.Status == StatusOK
+}
+
+// Health is the liveness and the readiness checks of the daemon
+// The readiness includes the liveness, and both are failed after it is closed by the closer manager
+type Health struct {
+	sync.Mutex
+	live    []*namedCheck
+	ready   []*namedCheck
+	isClose bool
+}
+
+// NewHealth returns a Health
+func NewHealth() *Health {
+	return &Health{}
+}
+
+// AddLive adds the check of the liveness
+func (hc *Health) AddLive(name string, check Check) {
+	hc.Lock()
+	defer hc.Unlock()
+
+	hc.live = append(hc.live, &namedCheck{name: name, check: check})
+}
+
+// AddReady adds the check of the readiness
+func (hc *Health) AddReady(name string, check Check) {
+	hc.Lock()
+	defer hc.Unlock()
+
+	hc.ready = append(hc.ready, &namedCheck{name: name, check: check})
+}
+
+// Close marks the daemon as closing
+func (hc *Health) Close() {
+	hc.Lock()
+	defer hc.Unlock()
+
+	hc.isClose = true
+}
+
+// Live returns the report of the liveness
+func (hc *Health) Live() *Report {
+	hc.Lock()
+	checks := append([]*namedCheck{}, hc.live...)
+	isClose := hc.isClose
+	hc.Unlock()
+
+	return run(checks, isClose)
+}
+
+// Ready returns the report of the readiness
+func (hc *Health) Ready() *Report {
+	hc.Lock()
+	checks := append(append([]*namedCheck{}, hc.live...), hc.ready...)
+	isClose := hc.isClose
+	hc.Unlock()
+
+	return run(checks, isClose)
+}
+
+func run(checks []*namedCheck, isClose bool) *Report {
+	rp := &Report{
+		Status: StatusOK,
+		Checks: map[string]*Result{},
+	}
+	if isClose {
+		rp.Status = StatusFail
+		rp.Checks["process"] = &Result{Status: StatusFail, Error: ErrClosing.Error()}
+		return rp
+	}
+	rp.Checks["process"] = &Result{Status: StatusOK}
+	sort.SliceStable(checks, func(i, j int) bool {
+		return checks[i].name < checks[j].name
+	})
+	for _, c := range checks {
+		detail, err := c.check()
+		if err != nil {
+			rp.Status = StatusFail
+			rp.Checks[c.name] = &Result{Status: StatusFail, Detail: detail, Error: err.Error()}
+		} else {
+			rp.Checks[c.name] = &Result{Status: StatusOK, Detail: detail}
+		}
+	}
+	return rp
+}
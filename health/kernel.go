@@ -0,0 +1,58 @@
+package health
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fletaio/core/kernel"
+)
+
+// BlockInterval is the target interval of the blocks of the formulator
+// The heights of the peers are kept private by the core package, so the lag is estimated by the time from the last block
+const BlockInterval = 500 * time.Millisecond
+
+// DefaultMaxLag is the maximum lag of the readiness in blocks when it is not given
+const DefaultMaxLag = 20
+
+// StoreCheck reads the hash of the last block from the store of the kernel
+func StoreCheck(kn *kernel.Kernel) Check {
+	return func() (string, error) {
+		if kn == nil {
+			return "", ErrNotInitialized
+		}
+		provider := kn.Provider()
+		Height := provider.Height()
+		h, err := provider.Hash(Height)
+		if err != nil {
+			return fmt.Sprintf("height %d", Height), err
+		}
+		return fmt.Sprintf("height %d hash %s", Height, h.String()), nil
+	}
+}
+
+// SyncCheck checks that the last block is within the lag of the blocks
+func SyncCheck(kn *kernel.Kernel, MaxLag int) Check {
+	if MaxLag <= 0 {
+		MaxLag = DefaultMaxLag
+	}
+	return func() (string, error) {
+		provider := kn.Provider()
+		Height := provider.Height()
+		if Height == 0 {
+			return "no block after the genesis", ErrNotSynced
+		}
+		h, err := provider.Header(Height)
+		if err != nil {
+			return "", err
+		}
+		Lag := int(time.Since(time.Unix(0, int64(h.Timestamp()))) / BlockInterval)
+		if Lag < 0 {
+			Lag = 0
+		}
+		detail := fmt.Sprintf("height %d, about %d blocks behind", Height, Lag)
+		if Lag > MaxLag {
+			return detail, ErrNotSynced
+		}
+		return detail, nil
+	}
+}
@@ -0,0 +1,227 @@
+package index
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+
+	"github.com/dgraph-io/badger"
+	"github.com/fletaio/cmd/chain"
+	"github.com/fletaio/common"
+	"github.com/fletaio/common/hash"
+	"github.com/fletaio/core/amount"
+	"github.com/fletaio/core/consensus"
+	"github.com/fletaio/core/event"
+	"github.com/fletaio/core/transaction"
+	"github.com/fletaio/core/txpool"
+	"github.com/fletaio/extension/account_tx"
+	"github.com/fletaio/extension/utxo_tx"
+	"github.com/fletaio/solidity"
+)
+
+// kinds of the history item
+const (
+	HistoryTransaction = uint8(1)
+	HistoryEvent       = uint8(2)
+	HistoryReward      = uint8(3)
+)
+
+// rewardTxIndex is the transaction index of the reward, so the reward is placed after the transactions of the block
+const rewardTxIndex = 65535
+
+// historySuffixSize is the size of height, transaction index, kind and event index of the history key
+const historySuffixSize = 9
+
+// PayoutSource gives the rewards paid by the processed block
+type PayoutSource interface {
+	Payouts(height uint32) []*chain.Payout
+}
+
+// HistoryItem is a transaction, an event or a reward that touches the address
+type HistoryItem struct {
+	Kind       uint8
+	Height     uint32
+	TxIndex    uint16
+	EventIndex uint16
+	TxHash     hash.Hash256
+	Amount     *amount.Amount
+	Cursor     string
+}
+
+// SetPayoutSource sets the source of the rewards that are indexed to the address history
+func (idx *Index) SetPayoutSource(ps PayoutSource) {
+	idx.Lock()
+	defer idx.Unlock()
+
+	idx.payouts = ps
+}
+
+// IsAddressIndex returns the address index is enabled or not
+func (idx *Index) IsAddressIndex() bool {
+	return idx.addressIndex
+}
+
+// AddressHistory returns the history items of the address from the newest one
+// The cursor is the cursor of the last item of the previous page, and the first page is returned when it is empty
+func (idx *Index) AddressHistory(addr common.Address, Cursor string, Limit int) ([]*HistoryItem, error) {
+	idx.closeLock.RLock()
+	defer idx.closeLock.RUnlock()
+	if idx.isClose {
+		return nil, ErrIndexClosed
+	}
+	if !idx.addressIndex {
+		return nil, ErrDisabledAddressIndex
+	}
+
+	prefix := toAddressPrefix(addr)
+	seek := make([]byte, len(prefix)+historySuffixSize)
+	copy(seek, prefix)
+	if len(Cursor) > 0 {
+		suffix, err := hex.DecodeString(Cursor)
+		if err != nil || len(suffix) != historySuffixSize {
+			return nil, ErrInvalidCursor
+		}
+		copy(seek[len(prefix):], suffix)
+	} else {
+		for i := len(prefix); i < len(seek); i++ {
+			seek[i] = 0xFF
+		}
+	}
+
+	list := []*HistoryItem{}
+	if err := idx.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Reverse = true
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		for it.Seek(seek); it.ValidForPrefix(prefix) && len(list) < Limit; it.Next() {
+			item := it.Item()
+			key := item.KeyCopy(nil)
+			if len(Cursor) > 0 && bytes.Equal(key, seek) {
+				continue
+			}
+			value, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			suffix := key[len(prefix):]
+			hi := &HistoryItem{
+				Height:     binary.BigEndian.Uint32(suffix[:4]),
+				TxIndex:    binary.BigEndian.Uint16(suffix[4:6]),
+				Kind:       suffix[6],
+				EventIndex: binary.BigEndian.Uint16(suffix[7:9]),
+				Cursor:     hex.EncodeToString(suffix),
+			}
+			if hi.Kind == HistoryReward {
+				hi.Amount = amount.NewAmountFromBytes(value)
+			} else {
+				copy(hi.TxHash[:], value)
+			}
+			list = append(list, hi)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// indexAddresses stores the history items of the addresses touched by the block
+func (idx *Index) indexAddresses(txn *badger.Txn, height uint32, TxHashes []hash.Hash256, txs []transaction.Transaction, events []event.Event, payouts []*chain.Payout) error {
+	for i, tx := range txs {
+		coord := common.NewCoordinate(height, uint16(i))
+		for _, addr := range TxAddresses(tx, coord) {
+			if err := txn.Set(toHistoryKey(addr, height, uint16(i), HistoryTransaction, 0), TxHashes[i][:]); err != nil {
+				return err
+			}
+		}
+	}
+	for _, e := range events {
+		coord := e.Coord()
+		if int(coord.Index) >= len(TxHashes) {
+			continue
+		}
+		for _, addr := range EventAddresses(e) {
+			if err := txn.Set(toHistoryKey(addr, coord.Height, coord.Index, HistoryEvent, e.Index()), TxHashes[coord.Index][:]); err != nil {
+				return err
+			}
+		}
+	}
+	for _, po := range payouts {
+		if err := txn.Set(toHistoryKey(po.Address, height, rewardTxIndex, HistoryReward, 0), po.Amount.Int.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TxAddresses returns the addresses touched by the transaction of the coordinate
+// The created account has the address of the coordinate of the transaction
+func TxAddresses(tx transaction.Transaction, coord *common.Coordinate) []common.Address {
+	addrs := []common.Address{}
+	if atx, is := tx.(txpool.AccountTransaction); is {
+		addrs = append(addrs, atx.From())
+	}
+	switch tx := tx.(type) {
+	case *account_tx.Transfer:
+		addrs = append(addrs, tx.To)
+	case *account_tx.CreateAccount, *account_tx.CreateMultiSigAccount, *utxo_tx.OpenAccount, *consensus.CreateFormulation, *solidity.CreateContract:
+		addrs = append(addrs, common.NewAddress(coord, 0))
+	case *utxo_tx.Deposit:
+		addrs = append(addrs, tx.To)
+	case *consensus.RevokeFormulation:
+		addrs = append(addrs, tx.Heritor)
+	case *consensus.Staking:
+		addrs = append(addrs, tx.HyperFormulator)
+	case *consensus.Unstaking:
+		addrs = append(addrs, tx.HyperFormulator)
+	case *consensus.SigmaFormulation:
+		addrs = append(addrs, tx.AlphaFormulators...)
+	case *consensus.OmegaFormulation:
+		addrs = append(addrs, tx.SigmaFormulators...)
+	case *solidity.CallContract:
+		addrs = append(addrs, tx.To)
+	}
+	return uniqueAddresses(addrs)
+}
+
+// EventAddresses returns the addresses touched by the event
+func EventAddresses(e event.Event) []common.Address {
+	switch e := e.(type) {
+	case *solidity.LogEvent:
+		return []common.Address{e.Address}
+	default:
+		return nil
+	}
+}
+
+func uniqueAddresses(addrs []common.Address) []common.Address {
+	list := make([]common.Address, 0, len(addrs))
+	addrMap := map[common.Address]bool{}
+	for _, addr := range addrs {
+		if !addrMap[addr] {
+			addrMap[addr] = true
+			list = append(list, addr)
+		}
+	}
+	return list
+}
+
+func toAddressPrefix(addr common.Address) []byte {
+	bs := make([]byte, 2+common.AddressSize)
+	copy(bs, tagAddress)
+	copy(bs[2:], addr[:])
+	return bs
+}
+
+// the numbers are big endian to keep the order of the keys same as the order of the history
+func toHistoryKey(addr common.Address, Height uint32, TxIndex uint16, Kind uint8, EventIndex uint16) []byte {
+	prefix := toAddressPrefix(addr)
+	bs := make([]byte, len(prefix)+historySuffixSize)
+	copy(bs, prefix)
+	binary.BigEndian.PutUint32(bs[len(prefix):], Height)
+	binary.BigEndian.PutUint16(bs[len(prefix)+4:], TxIndex)
+	bs[len(prefix)+6] = Kind
+	binary.BigEndian.PutUint16(bs[len(prefix)+7:], EventIndex)
+	return bs
+}
@@ -0,0 +1,103 @@
+package index
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/fletaio/cmd/chain"
+	"github.com/fletaio/common"
+	"github.com/fletaio/core/amount"
+	"github.com/fletaio/extension/account_tx"
+)
+
+func TestAddressHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "index")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	idx, err := Open(dir, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	From := common.NewAddress(common.NewCoordinate(0, 1), 0)
+	To := common.NewAddress(common.NewCoordinate(0, 2), 0)
+	Formulator := common.NewAddress(common.NewCoordinate(0, 3), 0)
+	Reward := amount.NewCoinAmount(0, 500000000000000000)
+	for h := uint32(1); h <= 3; h++ {
+		b := newTestBlock(h, newTestTransfer(uint64(h), From, To))
+		payouts := []*chain.Payout{{Address: Formulator, Amount: Reward}}
+		if err := idx.IndexBlock(b, nil, payouts, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	list, err := idx.AddressHistory(To, "", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].Height != 3 || list[1].Height != 2 {
+		t.Fatalf("the first page is %v, expected the heights 3 and 2", list)
+	}
+	if list[0].Kind != HistoryTransaction || list[0].TxHash != newTestTransfer(3, From, To).Hash() {
+		t.Errorf("the item is %+v, expected the transaction of the height 3", list[0])
+	}
+	list, err = idx.AddressHistory(To, list[1].Cursor, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].Height != 1 {
+		t.Fatalf("the next page is %v, expected the height 1", list)
+	}
+
+	list, err = idx.AddressHistory(Formulator, "", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 3 || list[0].Kind != HistoryReward || !list[0].Amount.Equal(Reward) {
+		t.Errorf("the rewards are %v, expected 3 rewards of %v", list, Reward)
+	}
+	if _, err := idx.AddressHistory(To, "zz", 2); err != ErrInvalidCursor {
+		t.Errorf("the invalid cursor returns %v, expected %v", err, ErrInvalidCursor)
+	}
+	idx.Close()
+
+	idx, err = Open(dir, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h := idx.Height(); h != 3 {
+		t.Errorf("the height is %d after disabling the address index, expected 3", h)
+	}
+	if _, err := idx.AddressHistory(To, "", 2); err != ErrDisabledAddressIndex {
+		t.Errorf("the disabled address index returns %v, expected %v", err, ErrDisabledAddressIndex)
+	}
+	idx.Close()
+
+	idx, err = Open(dir, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+	if h := idx.Height(); h != 0 {
+		t.Errorf("the height is %d after enabling the address index, expected 0 to backfill again", h)
+	}
+}
+
+func TestTxAddresses(t *testing.T) {
+	From := common.NewAddress(common.NewCoordinate(0, 1), 0)
+	coord := common.NewCoordinate(5, 1)
+
+	if addrs := TxAddresses(newTestTransfer(1, From, From), coord); len(addrs) != 1 || addrs[0] != From {
+		t.Errorf("the addresses of the transfer to itself are %v, expected %v", addrs, From)
+	}
+	tx := &account_tx.CreateAccount{
+		Base: account_tx.Base{From_: From},
+	}
+	if addrs := TxAddresses(tx, coord); len(addrs) != 2 || addrs[1] != common.NewAddress(coord, 0) {
+		t.Errorf("the addresses of the account creation are %v, expected the address of the coordinate", addrs)
+	}
+}
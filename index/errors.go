@@ -0,0 +1,15 @@
+package index
+
+import (
+	"errors"
+)
+
+// index errors
+var (
+	ErrIndexClosed          = errors.New("index closed")
+	ErrNotExistTransaction  = errors.New("not exist transaction")
+	ErrNotExistBlock        = errors.New("not exist block")
+	ErrDisabledAddressIndex = errors.New("disabled address index")
+	ErrInvalidCursor        = errors.New("invalid cursor")
+	ErrReplayAhead          = errors.New("replay store is ahead of the index")
+)
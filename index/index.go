@@ -0,0 +1,460 @@
+package index
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/dgraph-io/badger"
+	"github.com/fletaio/cmd/chain"
+	"github.com/fletaio/cmd/logging"
+	"github.com/fletaio/common"
+	"github.com/fletaio/common/hash"
+	"github.com/fletaio/common/util"
+	"github.com/fletaio/core/block"
+	"github.com/fletaio/core/data"
+	"github.com/fletaio/core/event"
+	"github.com/fletaio/core/kernel"
+	"github.com/fletaio/core/message_def"
+	"github.com/fletaio/core/transaction"
+	fchain "github.com/fletaio/framework/chain"
+)
+
+// Version is the version of the index
+// The index of the other version is backfilled again from the first block when it is opened
+const Version = 3
+
+var indexLog = logging.Get(logging.Index)
+
+// key tags of the index
+var (
+	tagVersion = []byte("version")
+	tagHeight  = []byte("height")
+	tagTx      = []byte{1, 0}
+	tagEvent   = []byte{1, 1}
+	tagBlock   = []byte{1, 2}
+	tagAddress = []byte{1, 3}
+
+	tagAddressIndex = []byte("address")
+)
+
+// TxPosition is the position of a transaction in the chain
+// EventsIndexed is false when the transaction is indexed without the events of the block
+type TxPosition struct {
+	Height        uint32
+	Index         uint16
+	EventsIndexed bool
+}
+
+// Index keeps the heights of the blocks and the positions and the events of the transactions of the chain
+// It is rebuilt from the kernel store, so it is stored apart from the kernel store
+type Index struct {
+	sync.Mutex
+	closeLock    sync.RWMutex
+	db           *badger.DB
+	eventer      *data.Eventer
+	addressIndex bool
+	payouts      PayoutSource
+	isClose      bool
+}
+
+// Open opens the index of the path
+// The address history is indexed when AddressIndex is true, and the index is backfilled again from the first block when it is enabled
+func Open(path string, evt *data.Eventer, AddressIndex bool) (*Index, error) {
+	opts := badger.DefaultOptions
+	opts.Dir = path
+	opts.ValueDir = path
+	opts.Truncate = true
+	opts.SyncWrites = true
+	if err := os.MkdirAll(path, 0700); err != nil {
+		return nil, err
+	}
+	os.Remove(filepath.Join(path, "LOCK"))
+
+	db, err := badger.Open(opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Update(func(txn *badger.Txn) error {
+		version, err := getUint32(txn, tagVersion)
+		if err != nil {
+			return err
+		}
+		enabled, err := getUint32(txn, tagAddressIndex)
+		if err != nil {
+			return err
+		}
+		if version != Version || (AddressIndex && enabled == 0) {
+			if err := txn.Set(tagHeight, util.Uint32ToBytes(0)); err != nil {
+				return err
+			}
+		}
+		if AddressIndex {
+			enabled = 1
+		} else {
+			enabled = 0
+		}
+		if err := txn.Set(tagAddressIndex, util.Uint32ToBytes(enabled)); err != nil {
+			return err
+		}
+		return txn.Set(tagVersion, util.Uint32ToBytes(Version))
+	}); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Index{
+		db:           db,
+		eventer:      evt,
+		addressIndex: AddressIndex,
+	}, nil
+}
+
+// getUint32 returns the uint32 value of the key, and it returns 0 when the key is not exist
+func getUint32(txn *badger.Txn, key []byte) (uint32, error) {
+	item, err := txn.Get(key)
+	if err != nil {
+		if err == badger.ErrKeyNotFound {
+			return 0, nil
+		}
+		return 0, err
+	}
+	value, err := item.ValueCopy(nil)
+	if err != nil {
+		return 0, err
+	}
+	return util.BytesToUint32(value), nil
+}
+
+// Close closes the index
+func (idx *Index) Close() {
+	idx.closeLock.Lock()
+	defer idx.closeLock.Unlock()
+
+	if !idx.isClose {
+		idx.isClose = true
+		idx.db.Close()
+	}
+}
+
+// Height returns the last indexed height
+func (idx *Index) Height() uint32 {
+	idx.closeLock.RLock()
+	defer idx.closeLock.RUnlock()
+	if idx.isClose {
+		return 0
+	}
+
+	var height uint32
+	idx.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(tagHeight)
+		if err != nil {
+			return err
+		}
+		value, err := item.ValueCopy(nil)
+		if err != nil {
+			return err
+		}
+		height = util.BytesToUint32(value)
+		return nil
+	})
+	return height
+}
+
+// TxPosition returns the position of the transaction of the hash
+func (idx *Index) TxPosition(TxHash hash.Hash256) (*TxPosition, error) {
+	idx.closeLock.RLock()
+	defer idx.closeLock.RUnlock()
+	if idx.isClose {
+		return nil, ErrIndexClosed
+	}
+
+	var pos *TxPosition
+	if err := idx.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(toTxKey(TxHash))
+		if err != nil {
+			if err == badger.ErrKeyNotFound {
+				return ErrNotExistTransaction
+			}
+			return err
+		}
+		value, err := item.ValueCopy(nil)
+		if err != nil {
+			return err
+		}
+		pos = &TxPosition{
+			Height:        util.BytesToUint32(value[:4]),
+			Index:         util.BytesToUint16(value[4:6]),
+			EventsIndexed: value[6] == 1,
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return pos, nil
+}
+
+// BlockHeight returns the height of the block of the hash
+func (idx *Index) BlockHeight(BlockHash hash.Hash256) (uint32, error) {
+	idx.closeLock.RLock()
+	defer idx.closeLock.RUnlock()
+	if idx.isClose {
+		return 0, ErrIndexClosed
+	}
+
+	var height uint32
+	if err := idx.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(toBlockKey(BlockHash))
+		if err != nil {
+			if err == badger.ErrKeyNotFound {
+				return ErrNotExistBlock
+			}
+			return err
+		}
+		value, err := item.ValueCopy(nil)
+		if err != nil {
+			return err
+		}
+		height = util.BytesToUint32(value)
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+	return height, nil
+}
+
+// TxEvents returns the events emitted by the transaction of the position
+func (idx *Index) TxEvents(pos *TxPosition) ([]event.Event, error) {
+	idx.closeLock.RLock()
+	defer idx.closeLock.RUnlock()
+	if idx.isClose {
+		return nil, ErrIndexClosed
+	}
+
+	list := []event.Event{}
+	if err := idx.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+		prefix := toEventPrefix(pos.Height, pos.Index)
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			value, err := it.Item().ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			e, err := idx.eventer.NewByType(event.Type(util.BytesToUint64(value[:8])))
+			if err != nil {
+				return err
+			}
+			if _, err := e.ReadFrom(bytes.NewReader(value[8:])); err != nil {
+				return err
+			}
+			list = append(list, e)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// IndexBlock stores the height of the block, the positions of the transactions and the events of the block
+// EventsIndexed should be false when the events of the block are not given, and then the indexed transactions are kept
+// The payouts are the rewards paid by the block, and they are stored to the address history with the transactions and the events
+func (idx *Index) IndexBlock(b *block.Block, events []event.Event, payouts []*chain.Payout, EventsIndexed bool) error {
+	idx.closeLock.RLock()
+	defer idx.closeLock.RUnlock()
+	if idx.isClose {
+		return ErrIndexClosed
+	}
+
+	height := b.Header.Height()
+	return idx.db.Update(func(txn *badger.Txn) error {
+		if err := txn.Set(toBlockKey(b.Header.Hash()), util.Uint32ToBytes(height)); err != nil {
+			return err
+		}
+		TxHashes := make([]hash.Hash256, 0, len(b.Body.Transactions))
+		for i, tx := range b.Body.Transactions {
+			TxHash := tx.Hash()
+			TxHashes = append(TxHashes, TxHash)
+			if !EventsIndexed {
+				if _, err := txn.Get(toTxKey(TxHash)); err == nil {
+					continue
+				} else if err != badger.ErrKeyNotFound {
+					return err
+				}
+			}
+			value := make([]byte, 7)
+			copy(value, util.Uint32ToBytes(height))
+			copy(value[4:], util.Uint16ToBytes(uint16(i)))
+			if EventsIndexed {
+				value[6] = 1
+			}
+			if err := txn.Set(toTxKey(TxHash), value); err != nil {
+				return err
+			}
+		}
+		for _, e := range events {
+			var buffer bytes.Buffer
+			buffer.Write(util.Uint64ToBytes(uint64(e.Type())))
+			if _, err := e.WriteTo(&buffer); err != nil {
+				return err
+			}
+			coord := e.Coord()
+			if err := txn.Set(toEventKey(coord.Height, coord.Index, e.Index()), buffer.Bytes()); err != nil {
+				return err
+			}
+		}
+		if idx.addressIndex {
+			if err := idx.indexAddresses(txn, height, TxHashes, b.Body.Transactions, events, payouts); err != nil {
+				return err
+			}
+		}
+		return txn.Set(tagHeight, util.Uint32ToBytes(height))
+	})
+}
+
+// Backfill indexes the blocks of the provider from the next of the last indexed height
+// The blocks are processed again by the replay kernel of the ReplayPath from the genesis of the bootstrap,
+// so the events and the rewards of the backfilled blocks are indexed like AfterProcessBlock
+// The replay store is kept to continue the next backfill, and it is rebuilt when it is ahead of the index
+func (idx *Index) Backfill(provider fchain.Provider, bs *chain.Bootstrap, ObserverKeyMap map[common.PublicHash]bool, ReplayPath string) error {
+	Height := provider.Height()
+	From := idx.Height() + 1
+	if From > Height {
+		return nil
+	}
+	sc := &chain.StoreConfig{
+		Path:           ReplayPath,
+		RecoveryPolicy: chain.RecoveryFail,
+	}
+	ks, err := bs.OpenStore(sc)
+	if err == nil && ks.Height() >= From {
+		ks.Close()
+		err = ErrReplayAhead
+	}
+	if err != nil {
+		indexLog.Warn("rebuild the replay store", "path", ReplayPath, "error", err)
+		if err := os.RemoveAll(ReplayPath); err != nil {
+			return err
+		}
+		if ks, err = bs.OpenStore(sc); err != nil {
+			return err
+		}
+	}
+	pr := chain.NewPayoutRecorder(chain.NewRewarder(bs.RewardSchedule), ks)
+	kn, err := bs.NewKernel(ks, pr, ObserverKeyMap)
+	if err != nil {
+		ks.Close()
+		return err
+	}
+	defer kn.Close()
+	rh := &replayHandler{
+		Index:   idx,
+		from:    From,
+		payouts: pr,
+	}
+	kn.AddEventHandler(rh)
+
+	indexLog.Info("backfill", "from", From, "to", Height, "replay", kn.Provider().Height()+1)
+	for h := kn.Provider().Height() + 1; h <= Height; h++ {
+		cd, err := provider.Data(h)
+		if err != nil {
+			return err
+		}
+		if err := kn.Process(cd, nil); err != nil {
+			return err
+		}
+		if rh.err != nil {
+			return rh.err
+		}
+		if h%10000 == 0 {
+			indexLog.Info("backfill", "height", h, "to", Height)
+		}
+	}
+	indexLog.Info("backfill is done", "height", Height)
+	return nil
+}
+
+// replayHandler indexes the blocks of the backfill that are processed by the replay kernel
+type replayHandler struct {
+	*Index
+	from    uint32
+	payouts PayoutSource
+	err     error
+}
+
+// AfterProcessBlock indexes the replayed block with the events of the context when it is not indexed
+func (rh *replayHandler) AfterProcessBlock(kn *kernel.Kernel, b *block.Block, s *block.ObserverSigned, ctx *data.Context) {
+	height := b.Header.Height()
+	if height < rh.from {
+		return
+	}
+	if err := rh.IndexBlock(b, ctx.Top().Events, rh.payouts.Payouts(height), true); err != nil {
+		rh.err = err
+	}
+}
+
+// OnProcessBlock called when processing a block to the chain (error prevent processing block)
+func (idx *Index) OnProcessBlock(kn *kernel.Kernel, b *block.Block, s *block.ObserverSigned, ctx *data.Context) error {
+	return nil
+}
+
+// AfterProcessBlock indexes the processed block with the events of the context
+func (idx *Index) AfterProcessBlock(kn *kernel.Kernel, b *block.Block, s *block.ObserverSigned, ctx *data.Context) {
+	var payouts []*chain.Payout
+	idx.Lock()
+	ps := idx.payouts
+	idx.Unlock()
+	if ps != nil {
+		payouts = ps.Payouts(b.Header.Height())
+	}
+	if err := idx.IndexBlock(b, ctx.Top().Events, payouts, true); err != nil {
+		indexLog.Error("failed to index the block", "height", b.Header.Height(), "error", err)
+	}
+}
+
+// OnPushTransaction called when pushing a transaction to the transaction pool (error prevent push transaction)
+func (idx *Index) OnPushTransaction(kn *kernel.Kernel, tx transaction.Transaction, sigs []common.Signature) error {
+	return nil
+}
+
+// AfterPushTransaction called when pushed a transaction to the transaction pool
+func (idx *Index) AfterPushTransaction(kn *kernel.Kernel, tx transaction.Transaction, sigs []common.Signature) {
+}
+
+// DoTransactionBroadcast called when a transaction need to be broadcast
+func (idx *Index) DoTransactionBroadcast(kn *kernel.Kernel, msg *message_def.TransactionMessage) {
+}
+
+// DebugLog provides internal debug logs to handlers
+func (idx *Index) DebugLog(kn *kernel.Kernel, args ...interface{}) {
+}
+
+func toTxKey(TxHash hash.Hash256) []byte {
+	bs := make([]byte, 2+hash.Hash256Size)
+	copy(bs, tagTx)
+	copy(bs[2:], TxHash[:])
+	return bs
+}
+
+func toBlockKey(BlockHash hash.Hash256) []byte {
+	bs := make([]byte, 2+hash.Hash256Size)
+	copy(bs, tagBlock)
+	copy(bs[2:], BlockHash[:])
+	return bs
+}
+
+func toEventPrefix(Height uint32, TxIndex uint16) []byte {
+	bs := make([]byte, 8)
+	copy(bs, tagEvent)
+	copy(bs[2:], util.Uint32ToBytes(Height))
+	copy(bs[6:], util.Uint16ToBytes(TxIndex))
+	return bs
+}
+
+func toEventKey(Height uint32, TxIndex uint16, Index uint16) []byte {
+	bs := make([]byte, 10)
+	copy(bs, toEventPrefix(Height, TxIndex))
+	copy(bs[8:], util.Uint16ToBytes(Index))
+	return bs
+}
@@ -0,0 +1,99 @@
+package index
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/fletaio/common"
+	"github.com/fletaio/core/amount"
+	"github.com/fletaio/core/block"
+	"github.com/fletaio/core/transaction"
+	"github.com/fletaio/extension/account_tx"
+	fchain "github.com/fletaio/framework/chain"
+)
+
+func newTestBlock(height uint32, txs ...transaction.Transaction) *block.Block {
+	return &block.Block{
+		Header: &block.Header{
+			Base: fchain.Base{
+				Height_:    height,
+				Timestamp_: uint64(height),
+			},
+		},
+		Body: &block.Body{
+			Transactions: txs,
+		},
+	}
+}
+
+func newTestTransfer(Seq uint64, From common.Address, To common.Address) *account_tx.Transfer {
+	return &account_tx.Transfer{
+		Base: account_tx.Base{
+			Base: transaction.Base{
+				Timestamp_: Seq,
+			},
+			Seq_:  Seq,
+			From_: From,
+		},
+		Amount: amount.NewCoinAmount(1, 0),
+		To:     To,
+	}
+}
+
+func TestIndexBlock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "index")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	idx, err := Open(dir, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+
+	From := common.NewAddress(common.NewCoordinate(0, 1), 0)
+	To := common.NewAddress(common.NewCoordinate(0, 2), 0)
+	tx := newTestTransfer(1, From, To)
+	b1 := newTestBlock(1, tx)
+	if err := idx.IndexBlock(b1, nil, nil, false); err != nil {
+		t.Fatal(err)
+	}
+	b2 := newTestBlock(2, tx)
+	if err := idx.IndexBlock(b2, nil, nil, false); err != nil {
+		t.Fatal(err)
+	}
+	if h := idx.Height(); h != 2 {
+		t.Errorf("the height is %d, expected 2", h)
+	}
+	if h, err := idx.BlockHeight(b1.Header.Hash()); err != nil || h != 1 {
+		t.Errorf("the height of the block is %d, %v, expected 1", h, err)
+	}
+	if _, err := idx.BlockHeight(newTestBlock(3).Header.Hash()); err != ErrNotExistBlock {
+		t.Errorf("the unknown block returns %v, expected %v", err, ErrNotExistBlock)
+	}
+	if pos, err := idx.TxPosition(tx.Hash()); err != nil {
+		t.Fatal(err)
+	} else if pos.Height != 1 || pos.Index != 0 || pos.EventsIndexed {
+		t.Errorf("the position is %+v, expected the first position without the events", pos)
+	}
+
+	if err := idx.IndexBlock(b2, nil, nil, true); err != nil {
+		t.Fatal(err)
+	}
+	if pos, err := idx.TxPosition(tx.Hash()); err != nil {
+		t.Fatal(err)
+	} else if pos.Height != 2 || !pos.EventsIndexed {
+		t.Errorf("the position is %+v, expected the position indexed with the events", pos)
+	}
+	if _, err := idx.TxPosition(newTestTransfer(2, From, To).Hash()); err != ErrNotExistTransaction {
+		t.Errorf("the unknown transaction returns %v, expected %v", err, ErrNotExistTransaction)
+	}
+
+	idx.Close()
+	if _, err := idx.TxPosition(tx.Hash()); err != ErrIndexClosed {
+		t.Errorf("the closed index returns %v, expected %v", err, ErrIndexClosed)
+	}
+}
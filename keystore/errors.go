@@ -0,0 +1,20 @@
+package keystore
+
+import (
+	"errors"
+)
+
+// keystore errors
+var (
+	ErrUnsupportedVersion = errors.New("unsupported keystore version")
+	ErrUnsupportedKDF     = errors.New("unsupported kdf")
+	ErrUnsupportedCipher  = errors.New("unsupported cipher")
+	ErrInvalidKDFParams   = errors.New("invalid kdf params")
+	ErrInvalidNonce       = errors.New("invalid nonce")
+	ErrInvalidCipherText  = errors.New("invalid cipher text")
+	ErrInvalidPassphrase  = errors.New("invalid passphrase")
+	ErrMismatchPublicHash = errors.New("mismatch public hash")
+	ErrEmptyPassphrase    = errors.New("empty passphrase")
+	ErrMismatchPassphrase = errors.New("mismatch passphrase")
+	ErrNotExistPassphrase = errors.New("not exist passphrase")
+)
@@ -0,0 +1,296 @@
+package keystore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/fletaio/common"
+	"github.com/fletaio/core/key"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Version is the version of the keystore file format
+const Version = 1
+
+// key derivation functions
+const (
+	KDFScrypt   = "scrypt"
+	KDFArgon2id = "argon2id"
+)
+
+// CipherAES256GCM is the cipher of the private key
+const CipherAES256GCM = "aes-256-gcm"
+
+// default parameters of the key derivation functions
+const (
+	ScryptN         = 1 << 18
+	ScryptR         = 8
+	ScryptP         = 1
+	Argon2Time      = 3
+	Argon2Memory    = 64 * 1024
+	Argon2Threads   = 4
+	derivedKeySize  = 32
+	saltSize        = 32
+	gcmNonceSize    = 12
+	privateKeyBytes = 32
+)
+
+// maximum parameters of the key derivation functions to bound the memory and the time of loading a key file
+const (
+	MaxScryptN       = 1 << 20
+	MaxScryptR       = 8
+	MaxScryptP       = 16
+	MaxArgon2Time    = 16
+	MaxArgon2Memory  = 1024 * 1024
+	MaxArgon2Threads = 64
+)
+
+// KeyFile is the encrypted key that is stored in the keystore file
+// The public hash is stored as plaintext to be used without the passphrase
+type KeyFile struct {
+	Version    int        `json:"version"`
+	PublicHash string     `json:"publicHash"`
+	KDF        string     `json:"kdf"`
+	KDFParams  *KDFParams `json:"kdfparams"`
+	Cipher     string     `json:"cipher"`
+	Nonce      string     `json:"nonce"`
+	CipherText string     `json:"ciphertext"`
+}
+
+// KDFParams is the parameters of the key derivation function
+type KDFParams struct {
+	Salt    string `json:"salt"`
+	N       int    `json:"n,omitempty"`
+	R       int    `json:"r,omitempty"`
+	P       int    `json:"p,omitempty"`
+	Time    uint32 `json:"time,omitempty"`
+	Memory  uint32 `json:"memory,omitempty"`
+	Threads uint8  `json:"threads,omitempty"`
+}
+
+// Encrypt encrypts the key by the passphrase using the key derivation function
+func Encrypt(k *key.MemoryKey, passphrase []byte, KDF string) (*KeyFile, error) {
+	salt := make([]byte, saltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+	params := &KDFParams{
+		Salt: hex.EncodeToString(salt),
+	}
+	switch KDF {
+	case KDFScrypt:
+		params.N = ScryptN
+		params.R = ScryptR
+		params.P = ScryptP
+	case KDFArgon2id:
+		params.Time = Argon2Time
+		params.Memory = Argon2Memory
+		params.Threads = Argon2Threads
+	default:
+		return nil, fmt.Errorf("%v %q", ErrUnsupportedKDF, KDF)
+	}
+	dk, err := deriveKey(KDF, params, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	PublicHash := common.NewPublicHash(k.PublicKey())
+	gcm, err := newGCM(dk)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcmNonceSize)
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	pk := leftPad(k.Bytes(), privateKeyBytes)
+	return &KeyFile{
+		Version:    Version,
+		PublicHash: PublicHash.String(),
+		KDF:        KDF,
+		KDFParams:  params,
+		Cipher:     CipherAES256GCM,
+		Nonce:      hex.EncodeToString(nonce),
+		CipherText: hex.EncodeToString(gcm.Seal(nil, nonce, pk, PublicHash[:])),
+	}, nil
+}
+
+// Decrypt decrypts the key by the passphrase and checks that it matches the public hash
+func (kf *KeyFile) Decrypt(passphrase []byte) (*key.MemoryKey, error) {
+	if err := kf.Validate(); err != nil {
+		return nil, err
+	}
+	PublicHash, err := kf.KeyHash()
+	if err != nil {
+		return nil, err
+	}
+	dk, err := deriveKey(kf.KDF, kf.KDFParams, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(dk)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hex.DecodeString(kf.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	ct, err := hex.DecodeString(kf.CipherText)
+	if err != nil {
+		return nil, err
+	}
+	pk, err := gcm.Open(nil, nonce, ct, PublicHash[:])
+	if err != nil {
+		return nil, ErrInvalidPassphrase
+	}
+	k, err := key.NewMemoryKeyFromBytes(pk)
+	if err != nil {
+		return nil, err
+	}
+	if !common.NewPublicHash(k.PublicKey()).Equal(PublicHash) {
+		return nil, ErrMismatchPublicHash
+	}
+	return k, nil
+}
+
+// Validate checks the format of the key file without decrypting it
+func (kf *KeyFile) Validate() error {
+	if kf.Version != Version {
+		return fmt.Errorf("%v %d", ErrUnsupportedVersion, kf.Version)
+	}
+	if _, err := common.ParsePublicHash(kf.PublicHash); err != nil {
+		return fmt.Errorf("invalid public hash %q: %v", kf.PublicHash, err)
+	}
+	if kf.KDF != KDFScrypt && kf.KDF != KDFArgon2id {
+		return fmt.Errorf("%v %q", ErrUnsupportedKDF, kf.KDF)
+	}
+	if err := checkKDFParams(kf.KDF, kf.KDFParams); err != nil {
+		return err
+	}
+	if kf.Cipher != CipherAES256GCM {
+		return fmt.Errorf("%v %q", ErrUnsupportedCipher, kf.Cipher)
+	}
+	if bs, err := hex.DecodeString(kf.Nonce); err != nil || len(bs) != gcmNonceSize {
+		return ErrInvalidNonce
+	}
+	if _, err := hex.DecodeString(kf.CipherText); err != nil {
+		return ErrInvalidCipherText
+	}
+	return nil
+}
+
+// KeyHash returns the public hash of the key file
+func (kf *KeyFile) KeyHash() (common.PublicHash, error) {
+	return common.ParsePublicHash(kf.PublicHash)
+}
+
+// ReadFile reads the key file without decrypting it
+func ReadFile(path string) (*KeyFile, error) {
+	bs, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	kf := &KeyFile{}
+	if err := json.Unmarshal(bs, kf); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if err := kf.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return kf, nil
+}
+
+// WriteFile writes the key file that is only readable by the owner
+// It does not overwrite the existing file
+func WriteFile(path string, kf *KeyFile) error {
+	bs, err := json.MarshalIndent(kf, "", "\t")
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(bs, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Load reads the key file and decrypts it by the passphrase
+func Load(path string, passphrase []byte) (*key.MemoryKey, error) {
+	kf, err := ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	k, err := kf.Decrypt(passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return k, nil
+}
+
+func checkKDFParams(KDF string, params *KDFParams) error {
+	if params == nil {
+		return ErrInvalidKDFParams
+	}
+	switch KDF {
+	case KDFScrypt:
+		if params.N <= 1 || params.N > MaxScryptN || params.R <= 0 || params.R > MaxScryptR || params.P <= 0 || params.P > MaxScryptP {
+			return fmt.Errorf("%v: scrypt n %d, r %d, p %d (max %d, %d, %d)", ErrInvalidKDFParams, params.N, params.R, params.P, MaxScryptN, MaxScryptR, MaxScryptP)
+		}
+	case KDFArgon2id:
+		if params.Time == 0 || params.Time > MaxArgon2Time || params.Memory == 0 || params.Memory > MaxArgon2Memory || params.Threads == 0 || params.Threads > MaxArgon2Threads {
+			return fmt.Errorf("%v: argon2id time %d, memory %d, threads %d (max %d, %d, %d)", ErrInvalidKDFParams, params.Time, params.Memory, params.Threads, MaxArgon2Time, MaxArgon2Memory, MaxArgon2Threads)
+		}
+	default:
+		return fmt.Errorf("%v %q", ErrUnsupportedKDF, KDF)
+	}
+	return nil
+}
+
+func deriveKey(KDF string, params *KDFParams, passphrase []byte) ([]byte, error) {
+	if err := checkKDFParams(KDF, params); err != nil {
+		return nil, err
+	}
+	salt, err := hex.DecodeString(params.Salt)
+	if err != nil || len(salt) == 0 {
+		return nil, ErrInvalidKDFParams
+	}
+	switch KDF {
+	case KDFScrypt:
+		dk, err := scrypt.Key(passphrase, salt, params.N, params.R, params.P, derivedKeySize)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", ErrInvalidKDFParams, err)
+		}
+		return dk, nil
+	default:
+		return argon2.IDKey(passphrase, salt, params.Time, params.Memory, params.Threads, derivedKeySize), nil
+	}
+}
+
+func newGCM(dk []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(dk)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func leftPad(bs []byte, size int) []byte {
+	if len(bs) >= size {
+		return bs
+	}
+	padded := make([]byte, size)
+	copy(padded[size-len(bs):], bs)
+	return padded
+}
@@ -0,0 +1,158 @@
+package keystore
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fletaio/common"
+	"github.com/fletaio/core/key"
+)
+
+func TestKeyFileRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, KDF := range []string{KDFScrypt, KDFArgon2id} {
+		k, err := key.NewMemoryKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		kf, err := Encrypt(k, []byte("passphrase"), KDF)
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, KDF+".json")
+		if err := WriteFile(path, kf); err != nil {
+			t.Fatal(err)
+		}
+		if err := WriteFile(path, kf); !os.IsExist(err) {
+			t.Errorf("the existing %s key file is overwritten: %v", KDF, err)
+		}
+		if info, err := os.Stat(path); err != nil {
+			t.Fatal(err)
+		} else if info.Mode().Perm() != 0600 {
+			t.Errorf("the mode of the %s key file is %v, expected 0600", KDF, info.Mode().Perm())
+		}
+
+		loaded, err := Load(path, []byte("passphrase"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !loaded.PublicKey().Equal(k.PublicKey()) {
+			t.Errorf("the %s key is not loaded as it is", KDF)
+		}
+		if PublicHash, err := kf.KeyHash(); err != nil {
+			t.Fatal(err)
+		} else if !PublicHash.Equal(common.NewPublicHash(k.PublicKey())) {
+			t.Errorf("the public hash of the %s key file is %v", KDF, PublicHash)
+		}
+		if _, err := Load(path, []byte("wrong")); err == nil || !strings.Contains(err.Error(), ErrInvalidPassphrase.Error()) {
+			t.Errorf("the wrong passphrase of the %s key file returns %v", KDF, err)
+		}
+	}
+}
+
+func TestKeyFileValidate(t *testing.T) {
+	k, err := key.NewMemoryKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	kf, err := Encrypt(k, []byte("passphrase"), KDFArgon2id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := kf.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Encrypt(k, []byte("passphrase"), "pbkdf2"); err == nil {
+		t.Error("the unsupported kdf is accepted")
+	}
+
+	other, err := key.NewMemoryKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		modify func(kf *KeyFile)
+	}{
+		{"version", func(kf *KeyFile) { kf.Version = 2 }},
+		{"kdf", func(kf *KeyFile) { kf.KDF = "pbkdf2" }},
+		{"cipher", func(kf *KeyFile) { kf.Cipher = "aes-128-ctr" }},
+		{"nonce", func(kf *KeyFile) { kf.Nonce = "00" }},
+		{"public hash", func(kf *KeyFile) { kf.PublicHash = common.NewPublicHash(other.PublicKey()).String() }},
+		{"cipher text", func(kf *KeyFile) {
+			ct, _ := hex.DecodeString(kf.CipherText)
+			ct[0] ^= 0xff
+			kf.CipherText = hex.EncodeToString(ct)
+		}},
+	}
+	for _, tt := range tests {
+		modified := *kf
+		tt.modify(&modified)
+		if _, err := modified.Decrypt([]byte("passphrase")); err == nil {
+			t.Errorf("the key file of the modified %s is decrypted", tt.name)
+		}
+	}
+}
+
+func TestKDFParamsLimit(t *testing.T) {
+	tests := []struct {
+		kdf    string
+		params KDFParams
+	}{
+		{KDFScrypt, KDFParams{N: MaxScryptN * 2, R: ScryptR, P: ScryptP}},
+		{KDFScrypt, KDFParams{N: ScryptN, R: MaxScryptR + 1, P: ScryptP}},
+		{KDFScrypt, KDFParams{N: ScryptN, R: ScryptR, P: MaxScryptP + 1}},
+		{KDFScrypt, KDFParams{N: 0, R: ScryptR, P: ScryptP}},
+		{KDFArgon2id, KDFParams{Time: MaxArgon2Time + 1, Memory: Argon2Memory, Threads: Argon2Threads}},
+		{KDFArgon2id, KDFParams{Time: Argon2Time, Memory: MaxArgon2Memory + 1, Threads: Argon2Threads}},
+		{KDFArgon2id, KDFParams{Time: Argon2Time, Memory: Argon2Memory, Threads: MaxArgon2Threads + 1}},
+	}
+	for _, tt := range tests {
+		params := tt.params
+		params.Salt = "00"
+		kf := &KeyFile{
+			Version:   Version,
+			KDF:       tt.kdf,
+			KDFParams: &params,
+		}
+		if err := checkKDFParams(kf.KDF, kf.KDFParams); err == nil {
+			t.Errorf("the %s params %+v are accepted", tt.kdf, tt.params)
+		}
+		if _, err := kf.Decrypt([]byte("passphrase")); err == nil {
+			t.Errorf("the key file of the %s params %+v is decrypted", tt.kdf, tt.params)
+		}
+	}
+}
+
+func TestReadPassphrase(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "passphrase")
+	if err := ioutil.WriteFile(path, []byte("passphrase\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if passphrase, err := ReadPassphrase(path, false); err != nil {
+		t.Fatal(err)
+	} else if string(passphrase) != "passphrase" {
+		t.Errorf("the passphrase is %q, expected the file without the newline", passphrase)
+	}
+
+	os.Setenv(PassphraseEnv, "")
+	defer os.Unsetenv(PassphraseEnv)
+	if _, err := ReadPassphrase("", false); err == nil || !strings.Contains(err.Error(), ErrEmptyPassphrase.Error()) {
+		t.Errorf("the empty environment variable returns %v", err)
+	}
+}
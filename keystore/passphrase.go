@@ -0,0 +1,63 @@
+package keystore
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// PassphraseEnv is the environment variable of the passphrase
+const PassphraseEnv = "FLETA_KEY_PASSPHRASE"
+
+// ReadPassphrase returns the passphrase from the file, the environment variable or the prompt in order
+// The prompt is only used when the stdin is a terminal and it asks twice when confirm is true
+func ReadPassphrase(PassphraseFile string, confirm bool) ([]byte, error) {
+	if len(PassphraseFile) > 0 {
+		bs, err := ioutil.ReadFile(PassphraseFile)
+		if err != nil {
+			return nil, err
+		}
+		passphrase := bytes.TrimRight(bs, "\r\n")
+		if len(passphrase) == 0 {
+			return nil, fmt.Errorf("%v: %s", ErrEmptyPassphrase, PassphraseFile)
+		}
+		return passphrase, nil
+	}
+	if str, has := os.LookupEnv(PassphraseEnv); has {
+		if len(str) == 0 {
+			return nil, fmt.Errorf("%v: %s", ErrEmptyPassphrase, PassphraseEnv)
+		}
+		return []byte(str), nil
+	}
+	fd := int(os.Stdin.Fd())
+	if !terminal.IsTerminal(fd) {
+		return nil, fmt.Errorf("%v: give the passphrase file or %s", ErrNotExistPassphrase, PassphraseEnv)
+	}
+	passphrase, err := prompt(fd, "Passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+	if len(passphrase) == 0 {
+		return nil, ErrEmptyPassphrase
+	}
+	if confirm {
+		again, err := prompt(fd, "Repeat passphrase: ")
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(passphrase, again) {
+			return nil, ErrMismatchPassphrase
+		}
+	}
+	return passphrase, nil
+}
+
+func prompt(fd int, msg string) ([]byte, error) {
+	fmt.Fprint(os.Stderr, msg)
+	passphrase, err := terminal.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	return passphrase, err
+}
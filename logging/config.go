@@ -0,0 +1,70 @@
+package logging
+
+// default limits of the log file
+const (
+	DefaultMaxSize    = 100
+	DefaultMaxBackups = 5
+)
+
+// Config is the config of the loggers
+// Levels are the levels of the components that differ from Level, and MaxSize is given in megabytes
+type Config struct {
+	Level      string
+	Levels     map[string]string
+	Format     string
+	File       string
+	MaxSize    int
+	MaxBackups int
+}
+
+// levels returns the levels of every component
+func (cfg *Config) levels() (map[string]Level, error) {
+	def := LevelInfo
+	if len(cfg.Level) > 0 {
+		lv, err := ParseLevel(cfg.Level)
+		if err != nil {
+			return nil, err
+		}
+		def = lv
+	}
+	levels := map[string]Level{}
+	for _, v := range Components {
+		levels[v] = def
+	}
+	for k, v := range cfg.Levels {
+		if !IsComponent(k) {
+			return nil, ErrUnknownComponent
+		}
+		lv, err := ParseLevel(v)
+		if err != nil {
+			return nil, err
+		}
+		levels[k] = lv
+	}
+	return levels, nil
+}
+
+func (cfg *Config) isJSON() (bool, error) {
+	switch cfg.Format {
+	case "", FormatConsole:
+		return false, nil
+	case FormatJSON:
+		return true, nil
+	default:
+		return false, ErrUnknownFormat
+	}
+}
+
+func (cfg *Config) maxSize() int64 {
+	if cfg.MaxSize <= 0 {
+		return DefaultMaxSize << 20
+	}
+	return int64(cfg.MaxSize) << 20
+}
+
+func (cfg *Config) maxBackups() int {
+	if cfg.MaxBackups <= 0 {
+		return DefaultMaxBackups
+	}
+	return cfg.MaxBackups
+}
@@ -0,0 +1,12 @@
+package logging
+
+import (
+	"errors"
+)
+
+// logging errors
+var (
+	ErrUnknownLevel     = errors.New("unknown level")
+	ErrUnknownFormat    = errors.New("unknown format")
+	ErrUnknownComponent = errors.New("unknown component")
+)
@@ -0,0 +1,42 @@
+package logging
+
+import (
+	"strings"
+)
+
+// Level is the level of the log entry
+type Level int
+
+// levels of the log entry
+const (
+	LevelDebug = Level(iota)
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+// String returns the name of the level
+func (lv Level) String() string {
+	if lv < LevelDebug || lv > LevelError {
+		return "unknown"
+	}
+	return levelNames[lv]
+}
+
+// ParseLevel returns the level of the name
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	default:
+		return 0, ErrUnknownLevel
+	}
+}
@@ -0,0 +1,289 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// components of the loggers
+// The log of the package that is not in the components is written by the main component
+const (
+	Main       = "main"
+	Store      = "store"
+	Kernel     = "kernel"
+	Router     = "router"
+	Peer       = "peer"
+	Observer   = "observer"
+	Formulator = "formulator"
+	RPC        = "rpc"
+	Index      = "index"
+	Tunnel     = "tunnel"
+)
+
+// Components are the components of the loggers
+var Components = []string{Main, Store, Kernel, Router, Peer, Observer, Formulator, RPC, Index, Tunnel}
+
+// AllComponents is used to set the level of every component
+const AllComponents = "*"
+
+// formats of the log output
+const (
+	FormatConsole = "console"
+	FormatJSON    = "json"
+)
+
+// TimeFormat is the time format of the log entry
+const TimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// IsComponent returns true when the name is the component of the loggers
+func IsComponent(name string) bool {
+	for _, v := range Components {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
+
+type output struct {
+	sync.Mutex
+	w      io.Writer
+	file   *RotatingFile
+	isJSON bool
+	levels map[string]Level
+}
+
+var out = newOutput()
+
+func newOutput() *output {
+	o := &output{
+		w:      os.Stderr,
+		levels: map[string]Level{},
+	}
+	for _, v := range Components {
+		o.levels[v] = LevelInfo
+	}
+	return o
+}
+
+// Setup applies the config to the loggers and captures the standard log
+// The writer of the previous config is closed when the file is changed
+func Setup(cfg *Config) error {
+	levels, err := cfg.levels()
+	if err != nil {
+		return err
+	}
+	isJSON, err := cfg.isJSON()
+	if err != nil {
+		return err
+	}
+	var w io.Writer = os.Stderr
+	var file *RotatingFile
+	if len(cfg.File) > 0 {
+		f, err := OpenRotatingFile(cfg.File, cfg.maxSize(), cfg.maxBackups())
+		if err != nil {
+			return err
+		}
+		w = f
+		file = f
+	}
+
+	out.Lock()
+	prev := out.file
+	out.w = w
+	out.file = file
+	out.isJSON = isJSON
+	out.levels = levels
+	out.Unlock()
+
+	if prev != nil {
+		prev.Close()
+	}
+	CaptureStd()
+	return nil
+}
+
+// SetLevel sets the level of the component, and * sets the level of every component
+func SetLevel(component string, lv Level) error {
+	if component != AllComponents && !IsComponent(component) {
+		return ErrUnknownComponent
+	}
+
+	out.Lock()
+	defer out.Unlock()
+
+	if component == AllComponents {
+		for _, v := range Components {
+			out.levels[v] = lv
+		}
+	} else {
+		out.levels[component] = lv
+	}
+	return nil
+}
+
+// Levels returns the names of the levels of the components
+func Levels() map[string]string {
+	out.Lock()
+	defer out.Unlock()
+
+	m := map[string]string{}
+	for k, v := range out.levels {
+		m[k] = v.String()
+	}
+	return m
+}
+
+// Logger writes the log entries of the component
+type Logger struct {
+	component string
+}
+
+// Get returns the logger of the component
+func Get(component string) *Logger {
+	return &Logger{component: component}
+}
+
+// Component returns the component of the logger
+func (l *Logger) Component() string {
+	return l.component
+}
+
+// Enabled returns true when the entry of the level is written
+func (l *Logger) Enabled(lv Level) bool {
+	out.Lock()
+	defer out.Unlock()
+
+	return lv >= out.level(l.component)
+}
+
+// Debug writes the debug entry with the key value pairs
+func (l *Logger) Debug(msg string, kv ...interface{}) {
+	out.write(time.Now(), LevelDebug, l.component, msg, kv)
+}
+
+// Info writes the info entry with the key value pairs
+func (l *Logger) Info(msg string, kv ...interface{}) {
+	out.write(time.Now(), LevelInfo, l.component, msg, kv)
+}
+
+// Warn writes the warn entry with the key value pairs
+func (l *Logger) Warn(msg string, kv ...interface{}) {
+	out.write(time.Now(), LevelWarn, l.component, msg, kv)
+}
+
+// Error writes the error entry with the key value pairs
+func (l *Logger) Error(msg string, kv ...interface{}) {
+	out.write(time.Now(), LevelError, l.component, msg, kv)
+}
+
+// Fatal writes the error entry with the key value pairs and exits the process with the status 1
+func (l *Logger) Fatal(msg string, kv ...interface{}) {
+	l.Error(msg, kv...)
+	os.Exit(1)
+}
+
+func (o *output) level(component string) Level {
+	if lv, has := o.levels[component]; has {
+		return lv
+	}
+	return o.levels[Main]
+}
+
+func (o *output) write(t time.Time, lv Level, component string, msg string, kv []interface{}) {
+	o.Lock()
+	defer o.Unlock()
+
+	if lv < o.level(component) {
+		return
+	}
+	var buffer bytes.Buffer
+	if o.isJSON {
+		writeJSON(&buffer, t, lv, component, msg, kv)
+	} else {
+		writeConsole(&buffer, t, lv, component, msg, kv)
+	}
+	o.w.Write(buffer.Bytes())
+}
+
+// writeConsole writes the entry as a line of the time, the level, the component, the message and the key=value pairs
+func writeConsole(buffer *bytes.Buffer, t time.Time, lv Level, component string, msg string, kv []interface{}) {
+	buffer.WriteString(t.Format(TimeFormat))
+	buffer.WriteString(" ")
+	buffer.WriteString(fmt.Sprintf("%-5s", strings.ToUpper(lv.String())))
+	buffer.WriteString(" [")
+	buffer.WriteString(component)
+	buffer.WriteString("] ")
+	buffer.WriteString(msg)
+	for i := 0; i < len(kv); i += 2 {
+		buffer.WriteString(" ")
+		buffer.WriteString(fmt.Sprint(kv[i]))
+		buffer.WriteString("=")
+		var str string
+		if i+1 < len(kv) {
+			str = fmt.Sprint(kv[i+1])
+		}
+		if len(str) == 0 || strings.ContainsAny(str, " =\"\t\r\n") {
+			str = strconv.Quote(str)
+		}
+		buffer.WriteString(str)
+	}
+	buffer.WriteString("\n")
+}
+
+// writeJSON writes the entry as a json object of a line
+// The value that cannot be marshaled is written as the string of it
+func writeJSON(buffer *bytes.Buffer, t time.Time, lv Level, component string, msg string, kv []interface{}) {
+	buffer.WriteString(`{"time":`)
+	writeJSONValue(buffer, t.Format(TimeFormat))
+	buffer.WriteString(`,"level":`)
+	writeJSONValue(buffer, lv.String())
+	buffer.WriteString(`,"component":`)
+	writeJSONValue(buffer, component)
+	buffer.WriteString(`,"msg":`)
+	writeJSONValue(buffer, msg)
+	keys := make([]string, 0, len(kv)/2)
+	values := map[string]interface{}{}
+	for i := 0; i < len(kv); i += 2 {
+		key := fmt.Sprint(kv[i])
+		if _, has := values[key]; !has {
+			keys = append(keys, key)
+		}
+		if i+1 < len(kv) {
+			values[key] = kv[i+1]
+		} else {
+			values[key] = nil
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		buffer.WriteString(",")
+		writeJSONValue(buffer, key)
+		buffer.WriteString(":")
+		switch v := values[key].(type) {
+		case error:
+			writeJSONValue(buffer, v.Error())
+		case fmt.Stringer:
+			writeJSONValue(buffer, v.String())
+		default:
+			writeJSONValue(buffer, v)
+		}
+	}
+	buffer.WriteString("}\n")
+}
+
+func writeJSONValue(buffer *bytes.Buffer, v interface{}) {
+	bs, err := json.Marshal(v)
+	if err != nil {
+		bs, _ = json.Marshal(fmt.Sprint(v))
+	}
+	buffer.Write(bs)
+}
@@ -0,0 +1,103 @@
+package logging
+
+import (
+	"os"
+	"strconv"
+	"sync"
+)
+
+// RotatingFile is the log file that is rotated when the size exceeds the max size
+// The rotated files are kept as path.1 to path.N and the oldest one is removed
+type RotatingFile struct {
+	sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+// OpenRotatingFile opens the file to append the log entries
+func OpenRotatingFile(path string, maxSize int64, maxBackups int) (*RotatingFile, error) {
+	rf := &RotatingFile{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+	if err := rf.open(); err != nil {
+		return nil, err
+	}
+	return rf, nil
+}
+
+func (rf *RotatingFile) open() error {
+	file, err := os.OpenFile(rf.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	rf.file = file
+	rf.size = info.Size()
+	return nil
+}
+
+// Write appends the data to the file after the rotation when the data exceeds the max size
+func (rf *RotatingFile) Write(p []byte) (int, error) {
+	rf.Lock()
+	defer rf.Unlock()
+
+	if rf.file == nil {
+		return 0, os.ErrClosed
+	}
+	if rf.size > 0 && rf.size+int64(len(p)) > rf.maxSize {
+		if err := rf.rotate(); err != nil && rf.file == nil {
+			return 0, err
+		}
+	}
+	n, err := rf.file.Write(p)
+	rf.size += int64(n)
+	return n, err
+}
+
+// rotate moves the file to path.1 after shifting the rotated files
+// The file is reopened to keep appending when the rotation is failed
+func (rf *RotatingFile) rotate() error {
+	rf.file.Close()
+	rf.file = nil
+	err := rf.shift()
+	if oerr := rf.open(); oerr != nil {
+		return oerr
+	}
+	return err
+}
+
+func (rf *RotatingFile) shift() error {
+	os.Remove(rf.backupPath(rf.maxBackups))
+	for i := rf.maxBackups - 1; i > 0; i-- {
+		if err := os.Rename(rf.backupPath(i), rf.backupPath(i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return os.Rename(rf.path, rf.backupPath(1))
+}
+
+func (rf *RotatingFile) backupPath(i int) string {
+	return rf.path + "." + strconv.Itoa(i)
+}
+
+// Close closes the file
+func (rf *RotatingFile) Close() error {
+	rf.Lock()
+	defer rf.Unlock()
+
+	if rf.file == nil {
+		return nil
+	}
+	err := rf.file.Close()
+	rf.file = nil
+	return err
+}
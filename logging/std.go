@@ -0,0 +1,112 @@
+package logging
+
+import (
+	"log"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// packageComponents are the components of the packages that write the standard log
+var packageComponents = []struct {
+	prefix    string
+	component string
+}{
+	{"github.com/fletaio/core/kernel", Kernel},
+	{"github.com/fletaio/core/store", Store},
+	{"github.com/fletaio/core/db", Store},
+	{"github.com/dgraph-io/badger", Store},
+	{"github.com/fletaio/core/observer", Observer},
+	{"github.com/fletaio/core/formulator", Formulator},
+	{"github.com/fletaio/core/node", Peer},
+	{"github.com/fletaio/framework/router", Router},
+	{"github.com/fletaio/framework/peer", Peer},
+	{"github.com/fletaio/framework/rpc", RPC},
+}
+
+// packages that only pass the standard log of the caller
+var wrapperPackages = []string{
+	"log.",
+	"github.com/fletaio/framework/log.",
+	"github.com/fletaio/cmd/logging.",
+}
+
+// level prefixes of the entries of the framework log
+var levelPrefixes = []struct {
+	prefix string
+	level  Level
+}{
+	{"DEBUG ", LevelDebug},
+	{"INFO ", LevelInfo},
+	{"NOTICE ", LevelInfo},
+	{"WARN ", LevelWarn},
+	{"ERROR ", LevelError},
+}
+
+var captureOnce sync.Once
+
+// CaptureStd redirects the standard log to the loggers
+// The component is found by the package of the caller and the level is found by the prefix of the framework log
+func CaptureStd() {
+	captureOnce.Do(func() {
+		log.SetFlags(0)
+		log.SetPrefix("")
+		log.SetOutput(stdWriter{})
+	})
+}
+
+type stdWriter struct{}
+
+func (w stdWriter) Write(p []byte) (int, error) {
+	t := time.Now()
+	lv, msg := parseStd(strings.TrimRight(string(p), "\n"))
+	out.write(t, lv, callerComponent(), msg, nil)
+	return len(p), nil
+}
+
+// parseStd returns the level of the entry and the message without the level prefix
+// The framework log writes the entry as [LEVEL  message] by printing the slice of the arguments
+func parseStd(msg string) (Level, string) {
+	body := msg
+	isSlice := strings.HasPrefix(body, "[") && strings.HasSuffix(body, "]")
+	if isSlice {
+		body = body[1 : len(body)-1]
+	}
+	for _, v := range levelPrefixes {
+		if strings.HasPrefix(body, v.prefix) {
+			return v.level, strings.TrimSpace(body[len(v.prefix):])
+		}
+	}
+	return LevelInfo, msg
+}
+
+// callerComponent returns the component of the package of the first caller that is not the log wrapper
+func callerComponent() string {
+	pcs := make([]uintptr, 16)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !isWrapper(frame.Function) {
+			for _, v := range packageComponents {
+				if strings.HasPrefix(frame.Function, v.prefix+".") || strings.HasPrefix(frame.Function, v.prefix+"/") {
+					return v.component
+				}
+			}
+			return Main
+		}
+		if !more {
+			return Main
+		}
+	}
+}
+
+func isWrapper(fn string) bool {
+	for _, v := range wrapperPackages {
+		if strings.HasPrefix(fn, v) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,171 @@
+package metrics
+
+import (
+	"reflect"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/fletaio/common"
+	"github.com/fletaio/core/block"
+	"github.com/fletaio/core/data"
+	"github.com/fletaio/core/kernel"
+	"github.com/fletaio/core/message_def"
+	"github.com/fletaio/core/transaction"
+	"github.com/fletaio/core/txpool"
+)
+
+// metric names of the chain
+const (
+	// ChainHeight is the height of the last block
+	ChainHeight = "fleta_chain_height"
+	// BlockInterval is the timestamp difference between the last block and the previous block in seconds
+	BlockInterval = "fleta_block_interval_seconds"
+	// BlockTransactions is the number of the transactions of the last block
+	BlockTransactions = "fleta_block_transactions"
+	// BlockTimeouts is the timeout count of the last block, that is the formulators skipped by the observers
+	BlockTimeouts = "fleta_block_timeout_count"
+	// TransactionsTotal is the number of the transactions of the processed blocks
+	TransactionsTotal = "fleta_transactions_total"
+	// TxPoolSize is the number of the transactions in the transaction pool
+	TxPoolSize = "fleta_txpool_size"
+	// FormulatorBlocksProduced is the number of the processed blocks of the formulator
+	FormulatorBlocksProduced = "fleta_formulator_blocks_produced_total"
+	// FormulatorBlocksMissed is the number of the blocks that the formulator was skipped by the timeout
+	FormulatorBlocksMissed = "fleta_formulator_blocks_missed_total"
+)
+
+// Chain is the metrics of the chain that are updated by the kernel events
+type Chain struct {
+	sync.Mutex
+	reg           *Registry
+	kn            *kernel.Kernel
+	height        *Gauge
+	interval      *Gauge
+	blockTxs      *Gauge
+	timeouts      *Gauge
+	txs           *Counter
+	poolSize      *Gauge
+	produced      *Counter
+	missed        *Counter
+	formulator    common.Address
+	lastTimestamp uint64
+	missedCount   int
+}
+
+// NewChain returns a Chain that is registered to the registry
+// It should be added to the event handlers of the kernel
+func NewChain(reg *Registry, kn *kernel.Kernel) *Chain {
+	ch := &Chain{
+		reg:      reg,
+		kn:       kn,
+		height:   NewGauge(ChainHeight, "height of the last block"),
+		interval: NewGauge(BlockInterval, "timestamp difference between the last block and the previous block in seconds"),
+		blockTxs: NewGauge(BlockTransactions, "number of the transactions of the last block"),
+		timeouts: NewGauge(BlockTimeouts, "timeout count of the last block"),
+		txs:      NewCounter(TransactionsTotal, "number of the transactions of the processed blocks"),
+		poolSize: NewGauge(TxPoolSize, "number of the transactions in the transaction pool"),
+	}
+	reg.Register(ch.height)
+	reg.Register(ch.interval)
+	reg.Register(ch.blockTxs)
+	reg.Register(ch.timeouts)
+	reg.Register(ch.txs)
+	reg.Register(ch.poolSize)
+	reg.OnCollect(ch.collect)
+
+	provider := kn.Provider()
+	if Height := provider.Height(); Height > 0 {
+		if h, err := provider.Header(Height); err == nil {
+			ch.lastTimestamp = h.Timestamp()
+		}
+	}
+	return ch
+}
+
+// WatchFormulator counts the produced and the missed blocks of the formulator
+func (ch *Chain) WatchFormulator(Formulator common.Address) {
+	ch.Lock()
+	defer ch.Unlock()
+
+	ch.formulator = Formulator
+	ch.produced = NewCounter(FormulatorBlocksProduced, "number of the processed blocks of the formulator")
+	ch.missed = NewCounter(FormulatorBlocksMissed, "number of the blocks that the formulator was skipped by the timeout")
+	ch.reg.Register(ch.produced)
+	ch.reg.Register(ch.missed)
+}
+
+// collect updates the height and the size of the transaction pool
+func (ch *Chain) collect() {
+	ch.height.Set(float64(ch.kn.Provider().Height()))
+	if size, ok := txPoolSize(ch.kn); ok {
+		ch.poolSize.Set(float64(size))
+	}
+}
+
+// txPoolSize returns the size of the transaction pool of the kernel
+// The kernel of the core does not expose the pool, so it is read from the field of the kernel and false is returned when the field is not found
+func txPoolSize(kn *kernel.Kernel) (int, bool) {
+	v := reflect.ValueOf(kn).Elem().FieldByName("txPool")
+	if !v.IsValid() || v.Type() != reflect.TypeOf((*txpool.TransactionPool)(nil)) || v.IsNil() {
+		return 0, false
+	}
+	return (*txpool.TransactionPool)(unsafe.Pointer(v.Pointer())).Size(), true
+}
+
+// OnProcessBlock finds the missed block of the formulator
+// The ranks of the timeout count are the formulators skipped before the block, and they are counted after the block is processed
+func (ch *Chain) OnProcessBlock(kn *kernel.Kernel, b *block.Block, s *block.ObserverSigned, ctx *data.Context) error {
+	ch.Lock()
+	defer ch.Unlock()
+
+	ch.missedCount = 0
+	if ch.missed == nil {
+		return nil
+	}
+	for i := 0; i < int(b.Header.TimeoutCount); i++ {
+		if rank, err := kn.TopRank(i); err == nil && rank.Address.Equal(ch.formulator) {
+			ch.missedCount++
+		}
+	}
+	return nil
+}
+
+// AfterProcessBlock updates the metrics of the block
+func (ch *Chain) AfterProcessBlock(kn *kernel.Kernel, b *block.Block, s *block.ObserverSigned, ctx *data.Context) {
+	ch.Lock()
+	defer ch.Unlock()
+
+	ch.height.Set(float64(b.Header.Height()))
+	if ch.lastTimestamp > 0 && b.Header.Timestamp() > ch.lastTimestamp {
+		ch.interval.Set(time.Duration(b.Header.Timestamp() - ch.lastTimestamp).Seconds())
+	}
+	ch.lastTimestamp = b.Header.Timestamp()
+	ch.blockTxs.Set(float64(len(b.Body.Transactions)))
+	ch.timeouts.Set(float64(b.Header.TimeoutCount))
+	ch.txs.Add(float64(len(b.Body.Transactions)))
+	if ch.produced != nil && b.Header.Formulator.Equal(ch.formulator) {
+		ch.produced.Inc()
+	}
+	if ch.missed != nil {
+		ch.missed.Add(float64(ch.missedCount))
+	}
+	ch.missedCount = 0
+}
+
+// OnPushTransaction is not used
+func (ch *Chain) OnPushTransaction(kn *kernel.Kernel, tx transaction.Transaction, sigs []common.Signature) error {
+	return nil
+}
+
+// AfterPushTransaction is not used
+func (ch *Chain) AfterPushTransaction(kn *kernel.Kernel, tx transaction.Transaction, sigs []common.Signature) {
+}
+
+// DoTransactionBroadcast is not used
+func (ch *Chain) DoTransactionBroadcast(kn *kernel.Kernel, msg *message_def.TransactionMessage) {
+}
+
+// DebugLog is not used
+func (ch *Chain) DebugLog(kn *kernel.Kernel, args ...interface{}) {
+}
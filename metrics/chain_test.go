@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fletaio/cmd/chain"
+	"github.com/fletaio/common"
+)
+
+func TestTxPoolSize(t *testing.T) {
+	bs, err := chain.NewBootstrap(chain.DefaultGenesis(), &chain.RewardPolicy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ks, err := bs.OpenStore(&chain.StoreConfig{
+		Path:           filepath.Join(dir, "kernel"),
+		RecoveryPolicy: chain.RecoveryFail,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	kn, err := bs.NewKernel(ks, chain.NewRewarder(bs.RewardSchedule), map[common.PublicHash]bool{})
+	if err != nil {
+		ks.Close()
+		t.Fatal(err)
+	}
+	defer kn.Close()
+
+	size, ok := txPoolSize(kn)
+	if !ok {
+		t.Fatal("the transaction pool is not found in the kernel")
+	}
+	if size != 0 {
+		t.Fatalf("the size of the empty pool is %d", size)
+	}
+}
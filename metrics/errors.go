@@ -0,0 +1,11 @@
+package metrics
+
+import (
+	"errors"
+)
+
+// metrics errors
+var (
+	ErrDuplicatedMetric  = errors.New("duplicated metric")
+	ErrInvalidLabelCount = errors.New("invalid label count")
+)
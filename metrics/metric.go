@@ -0,0 +1,209 @@
+package metrics
+
+import (
+	"bufio"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are the buckets of the durations in seconds
+var DefaultBuckets = []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 10}
+
+// vec is the values of the label values
+type vec struct {
+	sync.Mutex
+	name   string
+	help   string
+	labels []string
+	values map[string][]string
+}
+
+func newVec(name string, help string, labels []string) vec {
+	return vec{
+		name:   name,
+		help:   help,
+		labels: labels,
+		values: map[string][]string{},
+	}
+}
+
+// Name returns the name of the metric
+func (v *vec) Name() string {
+	return v.name
+}
+
+// key returns the key of the label values, and the label values are kept to write them
+func (v *vec) key(values []string) string {
+	if len(values) != len(v.labels) {
+		panic(ErrInvalidLabelCount.Error() + ": " + v.name)
+	}
+	k := strings.Join(values, "\xff")
+	if _, has := v.values[k]; !has {
+		v.values[k] = append([]string{}, values...)
+	}
+	return k
+}
+
+// keys returns the keys in order
+func (v *vec) keys() []string {
+	keys := make([]string, 0, len(v.values))
+	for k := range v.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Counter is the value that only increases
+type Counter struct {
+	vec
+	counts map[string]float64
+}
+
+// NewCounter returns a Counter of the labels
+func NewCounter(name string, help string, labels ...string) *Counter {
+	return &Counter{
+		vec:    newVec(name, help, labels),
+		counts: map[string]float64{},
+	}
+}
+
+// Inc adds one to the counter of the label values
+func (c *Counter) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Add adds the delta to the counter of the label values
+func (c *Counter) Add(delta float64, values ...string) {
+	if delta < 0 {
+		return
+	}
+	c.Lock()
+	defer c.Unlock()
+
+	c.counts[c.key(values)] += delta
+}
+
+func (c *Counter) write(w *bufio.Writer) {
+	c.Lock()
+	defer c.Unlock()
+
+	writeHeader(w, c.name, c.help, "counter")
+	if len(c.labels) == 0 && len(c.counts) == 0 {
+		writeSample(w, c.name, nil, nil, 0)
+	}
+	for _, k := range c.keys() {
+		writeSample(w, c.name, c.labels, c.values[k], c.counts[k])
+	}
+}
+
+// Gauge is the value that can be set
+type Gauge struct {
+	vec
+	gauges map[string]float64
+}
+
+// NewGauge returns a Gauge of the labels
+func NewGauge(name string, help string, labels ...string) *Gauge {
+	return &Gauge{
+		vec:    newVec(name, help, labels),
+		gauges: map[string]float64{},
+	}
+}
+
+// Set sets the gauge of the label values
+func (g *Gauge) Set(value float64, values ...string) {
+	g.Lock()
+	defer g.Unlock()
+
+	g.gauges[g.key(values)] = value
+}
+
+// Add adds the delta to the gauge of the label values
+func (g *Gauge) Add(delta float64, values ...string) {
+	g.Lock()
+	defer g.Unlock()
+
+	g.gauges[g.key(values)] += delta
+}
+
+func (g *Gauge) write(w *bufio.Writer) {
+	g.Lock()
+	defer g.Unlock()
+
+	writeHeader(w, g.name, g.help, "gauge")
+	if len(g.labels) == 0 && len(g.gauges) == 0 {
+		writeSample(w, g.name, nil, nil, 0)
+	}
+	for _, k := range g.keys() {
+		writeSample(w, g.name, g.labels, g.values[k], g.gauges[k])
+	}
+}
+
+type histogramValue struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// Histogram counts the observed values by the buckets
+type Histogram struct {
+	vec
+	buckets    []float64
+	histograms map[string]*histogramValue
+}
+
+// NewHistogram returns a Histogram of the buckets and the labels
+func NewHistogram(name string, help string, buckets []float64, labels ...string) *Histogram {
+	buckets = append([]float64{}, buckets...)
+	sort.Float64s(buckets)
+	return &Histogram{
+		vec:        newVec(name, help, labels),
+		buckets:    buckets,
+		histograms: map[string]*histogramValue{},
+	}
+}
+
+// Observe adds the value to the histogram of the label values
+func (h *Histogram) Observe(value float64, values ...string) {
+	h.Lock()
+	defer h.Unlock()
+
+	k := h.key(values)
+	hv, has := h.histograms[k]
+	if !has {
+		hv = &histogramValue{
+			counts: make([]uint64, len(h.buckets)),
+		}
+		h.histograms[k] = hv
+	}
+	for i, b := range h.buckets {
+		if value <= b {
+			hv.counts[i]++
+		}
+	}
+	hv.sum += value
+	hv.count++
+}
+
+func (h *Histogram) write(w *bufio.Writer) {
+	h.Lock()
+	defer h.Unlock()
+
+	writeHeader(w, h.name, h.help, "histogram")
+	labels := append(append([]string{}, h.labels...), "le")
+	for _, k := range h.keys() {
+		hv := h.histograms[k]
+		values := append(append([]string{}, h.values[k]...), "")
+		for i, b := range h.buckets {
+			values[len(values)-1] = formatFloat(b)
+			writeSample(w, h.name+"_bucket", labels, values, float64(hv.counts[i]))
+		}
+		values[len(values)-1] = formatFloat(math.Inf(1))
+		writeSample(w, h.name+"_bucket", labels, values, float64(hv.count))
+		writeSample(w, h.name+"_sum", h.labels, h.values[k], hv.sum)
+		writeSample(w, h.name+"_count", h.labels, h.values[k], float64(hv.count))
+	}
+}
@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"reflect"
+	"unsafe"
+
+	"github.com/fletaio/framework/chain/mesh"
+)
+
+// PeerCount is the metric name of the number of the connected peers
+const PeerCount = "fleta_peer_count"
+
+// WatchPeers registers the number of the connected peers of the kinds
+// The number is read by the count function of the kind when the metrics are written
+func WatchPeers(reg *Registry, Counts map[string]func() int) {
+	g := NewGauge(PeerCount, "number of the connected peers", "kind")
+	reg.Register(g)
+	reg.OnCollect(func() {
+		for kind, fn := range Counts {
+			g.Set(float64(fn()), kind)
+		}
+	})
+}
+
+// ConnectedPeers returns the count function of the connected peers of the node or the formulator of the core
+// The core does not expose the peer manager, so it is read from the field and 0 is counted when the field is not found
+func ConnectedPeers(owner interface{}) func() int {
+	return func() int {
+		pm, is := privateField(owner, "pm").(interface{ ConnectedList() []string })
+		if !is {
+			return 0
+		}
+		return len(pm.ConnectedList())
+	}
+}
+
+// MeshPeers returns the count function of the observers connected to the formulator or the observer of the core
+func MeshPeers(owner interface{}) func() int {
+	return func() int {
+		ms, is := privateField(owner, "ms").(interface{ Peers() []mesh.Peer })
+		if !is {
+			return 0
+		}
+		return len(ms.Peers())
+	}
+}
+
+// FormulatorPeers returns the count function of the formulators connected to the observer of the core
+func FormulatorPeers(owner interface{}) func() int {
+	return func() int {
+		fs, is := privateField(owner, "fs").(interface{ PeerCount() int })
+		if !is {
+			return 0
+		}
+		return fs.PeerCount()
+	}
+}
+
+// privateField returns the value of the unexported field of the struct that the owner points
+// nil is returned when the field is not found or it is nil
+func privateField(owner interface{}, name string) interface{} {
+	rv := reflect.ValueOf(owner)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	f := rv.Elem().FieldByName(name)
+	if !f.IsValid() {
+		return nil
+	}
+	switch f.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		if f.IsNil() {
+			return nil
+		}
+	}
+	return reflect.NewAt(f.Type(), unsafe.Pointer(f.UnsafeAddr())).Elem().Interface()
+}
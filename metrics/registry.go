@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType is the content type of the prometheus text format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Metric is the metric that is written by the prometheus text format
+type Metric interface {
+	Name() string
+	write(w *bufio.Writer)
+}
+
+// Registry is the set of the metrics
+// The collectors are called before writing the metrics to update the values that are read on demand
+type Registry struct {
+	sync.Mutex
+	metrics    []Metric
+	names      map[string]bool
+	collectors []func()
+}
+
+// NewRegistry returns a Registry
+func NewRegistry() *Registry {
+	return &Registry{
+		names: map[string]bool{},
+	}
+}
+
+// Register adds the metric to the registry
+// It panics when the name is already registered because the names are fixed in the code
+func (reg *Registry) Register(m Metric) {
+	reg.Lock()
+	defer reg.Unlock()
+
+	if reg.names[m.Name()] {
+		panic(ErrDuplicatedMetric.Error() + ": " + m.Name())
+	}
+	reg.names[m.Name()] = true
+	reg.metrics = append(reg.metrics, m)
+}
+
+// OnCollect adds the collector that is called before writing the metrics
+func (reg *Registry) OnCollect(fn func()) {
+	reg.Lock()
+	defer reg.Unlock()
+
+	reg.collectors = append(reg.collectors, fn)
+}
+
+// Write writes the metrics in the order of the names
+func (reg *Registry) Write(w io.Writer) error {
+	reg.Lock()
+	collectors := append([]func(){}, reg.collectors...)
+	list := append([]Metric{}, reg.metrics...)
+	reg.Unlock()
+
+	for _, fn := range collectors {
+		fn()
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name() < list[j].Name()
+	})
+	bw := bufio.NewWriter(w)
+	for _, m := range list {
+		m.write(bw)
+	}
+	return bw.Flush()
+}
+
+// ServeHTTP serves the metrics
+func (reg *Registry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	reg.Write(w)
+}
+
+// Serve serves the metrics at /metrics of the bind address
+func Serve(Bind string, reg *Registry) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", reg)
+	return http.ListenAndServe(Bind, mux)
+}
+
+func writeHeader(w *bufio.Writer, name string, help string, typ string) {
+	w.WriteString("# HELP " + name + " " + strings.NewReplacer("\\", `\\`, "\n", `\n`).Replace(help) + "\n")
+	w.WriteString("# TYPE " + name + " " + typ + "\n")
+}
+
+func writeSample(w *bufio.Writer, name string, labels []string, values []string, v float64) {
+	w.WriteString(name)
+	if len(labels) > 0 {
+		w.WriteString("{")
+		for i, l := range labels {
+			if i > 0 {
+				w.WriteString(",")
+			}
+			w.WriteString(l + `="` + escapeLabel(values[i]) + `"`)
+		}
+		w.WriteString("}")
+	}
+	w.WriteString(" " + formatFloat(v) + "\n")
+}
+
+func escapeLabel(v string) string {
+	return strings.NewReplacer("\\", `\\`, "\"", `\"`, "\n", `\n`).Replace(v)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"github.com/fletaio/core/observer"
+	"github.com/fletaio/framework/router"
+)
+
+// metric names of the voting round of the observer
+const (
+	// ObserverRoundState is the state of the voting round (0 empty, 1 round vote, 2 round vote ack, 3 block vote)
+	ObserverRoundState = "fleta_observer_round_state"
+	// ObserverRoundHeight is the target height of the voting round
+	ObserverRoundHeight = "fleta_observer_round_target_height"
+	// ObserverRoundFails is the number of the failed votes of the voting round
+	ObserverRoundFails = "fleta_observer_round_fail_count"
+)
+
+// WatchRound registers the state of the voting round of the observer
+// The state is read when the metrics are written
+func WatchRound(reg *Registry, ob *observer.Observer) {
+	state := NewGauge(ObserverRoundState, "state of the voting round (0 empty, 1 round vote, 2 round vote ack, 3 block vote)")
+	height := NewGauge(ObserverRoundHeight, "target height of the voting round")
+	fails := NewGauge(ObserverRoundFails, "number of the failed votes of the voting round")
+	reg.Register(state)
+	reg.Register(height)
+	reg.Register(fails)
+	reg.OnCollect(func() {
+		State, TargetHeight, FailCount, has := roundState(ob)
+		if !has {
+			return
+		}
+		state.Set(float64(State))
+		height.Set(float64(TargetHeight))
+		fails.Set(float64(FailCount))
+	})
+}
+
+// roundState returns the state, the target height and the fail count of the voting round of the observer
+// The observer of the core does not expose the round, so it is read from the field under the lock of the observer
+func roundState(ob *observer.Observer) (int, uint32, int, bool) {
+	lock, is := privateField(ob, "obLock").(*router.NamedLock)
+	if !is {
+		return 0, 0, 0, false
+	}
+	lock.Lock("RoundState")
+	defer lock.Unlock()
+
+	round, is := privateField(ob, "round").(*observer.VoteRound)
+	if !is {
+		return 0, 0, 0, false
+	}
+	return round.RoundState, round.VoteTargetHeight, round.VoteFailCount, true
+}
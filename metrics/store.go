@@ -0,0 +1,32 @@
+package metrics
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// StoreSize is the metric name of the size of the files of the stores in bytes
+const StoreSize = "fleta_store_size_bytes"
+
+// WatchStores registers the size of the directories of the stores
+// The size is read when the metrics are written
+func WatchStores(reg *Registry, Stores map[string]string) {
+	g := NewGauge(StoreSize, "size of the files of the store in bytes", "store")
+	reg.Register(g)
+	reg.OnCollect(func() {
+		for name, dir := range Stores {
+			g.Set(float64(dirSize(dir)), name)
+		}
+	})
+}
+
+func dirSize(dir string) int64 {
+	var size int64
+	filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err == nil && !fi.IsDir() {
+			size += fi.Size()
+		}
+		return nil
+	})
+	return size
+}
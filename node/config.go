@@ -0,0 +1,61 @@
+package main
+
+import (
+	"github.com/fletaio/cmd/chain"
+	"github.com/fletaio/cmd/command"
+	"github.com/fletaio/common"
+)
+
+// Config is a configuration for the cmd
+type Config struct {
+	SeedNodes    []string
+	ObserverKeys []string
+	Port         int
+	AddressIndex bool
+	command.APIConfig
+	command.StoreConfig
+	command.LogConfig
+	command.RewardConfig
+
+	genesis *chain.Genesis // the genesis loaded by Validate
+}
+
+// Validate checks every field of the config and reports all problems together
+func (cfg *Config) Validate() error {
+	var es command.ConfigErrors
+	if len(cfg.ObserverKeys) == 0 {
+		es.Addf("ObserverKeys", "observer keys are not given")
+	}
+	keyMap := map[common.PublicHash]bool{}
+	for _, k := range cfg.ObserverKeys {
+		pubhash, err := chain.ParsePublicHash(k)
+		if err != nil {
+			es.Addf("ObserverKeys", "invalid observer key %q: %v", k, err)
+			continue
+		}
+		if keyMap[pubhash] {
+			es.Addf("ObserverKeys", "duplicated observer key %q", k)
+			continue
+		}
+		keyMap[pubhash] = true
+	}
+	for _, v := range cfg.SeedNodes {
+		es.CheckNetAddr("SeedNodes", v)
+	}
+	es.CheckPorts(
+		command.PortField{Field: "Port", Port: cfg.Port},
+		command.PortField{Field: "APIPort", Port: cfg.APIPort},
+		command.PortField{Field: "MetricsPort", Port: cfg.MetricsPort, Optional: true},
+	)
+	es.CheckStoreConfig(&cfg.StoreConfig)
+	es.CheckAPIConfig(&cfg.APIConfig)
+	es.CheckLogging(cfg.LoggingConfig())
+	es.CheckRewardConfig(&cfg.RewardConfig)
+	if gen, err := chain.LoadGenesis(cfg.GenesisFile); err != nil {
+		es.Add("GenesisFile", err)
+	} else {
+		cfg.genesis = gen
+		es.CheckRewardPolicy(gen, cfg.RewardPolicy())
+	}
+	return es.Err()
+}
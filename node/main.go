@@ -1,69 +1,75 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
-	"strconv"
-	"strings"
 	"syscall"
 
-	"github.com/dgraph-io/badger"
-
+	"github.com/fletaio/cmd/api"
+	"github.com/fletaio/cmd/chain"
+	"github.com/fletaio/cmd/command"
+	"github.com/fletaio/cmd/index"
+	"github.com/fletaio/cmd/logging"
+	"github.com/fletaio/cmd/metrics"
 	"github.com/fletaio/common"
-	"github.com/fletaio/core/block"
-	"github.com/fletaio/core/data"
+	"github.com/fletaio/core/consensus"
 	"github.com/fletaio/core/kernel"
 	"github.com/fletaio/core/node"
-	"github.com/fletaio/core/reward"
 	"github.com/fletaio/framework/closer"
-	"github.com/fletaio/framework/config"
 	"github.com/fletaio/framework/peer"
 	"github.com/fletaio/framework/router"
 	"github.com/fletaio/framework/router/evilnode"
 	"github.com/fletaio/framework/rpc"
 )
 
-// Config is a configuration for the cmd
-type Config struct {
-	SeedNodes    []string
-	ObserverKeys []string
-	Port         int
-	APIPort      int
-	StoreRoot    string
-	ForceRecover bool
-}
-
 func main() {
-	var cfg Config
-	if err := config.LoadFile("./config.toml", &cfg); err != nil {
-		panic(err)
+	cfg := Config{
+		StoreConfig: command.StoreConfig{
+			StoreRoot: "./data",
+		},
 	}
-	if len(cfg.StoreRoot) == 0 {
-		cfg.StoreRoot = "./data"
+	if command.IsCommand(os.Args[1:]) {
+		if err := command.Run(os.Args[1:], &cfg); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+	if err := command.LoadConfig(os.Args[1:], &cfg); err != nil {
+		if err == flag.ErrHelp {
+			return
+		}
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if err := logging.Setup(cfg.LoggingConfig()); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
 	}
 
 	ObserverKeyMap := map[common.PublicHash]bool{}
 	for _, k := range cfg.ObserverKeys {
 		pubhash, err := common.ParsePublicHash(k)
 		if err != nil {
-			panic(err)
+			logging.Get(logging.Main).Fatal("invalid observer key", "key", k, "error", err)
 		}
 		ObserverKeyMap[pubhash] = true
 	}
 
-	GenCoord := common.NewCoordinate(0, 0)
-	act := data.NewAccounter(GenCoord)
-	tran := data.NewTransactor(GenCoord)
-	evt := data.NewEventer(GenCoord)
-	if err := initChainComponent(act, tran, evt); err != nil {
-		panic(err)
+	GenesisNameMap, err := cfg.genesis.NameMap()
+	if err != nil {
+		logging.Get(logging.Main).Fatal("invalid genesis", "file", cfg.GenesisFile, "error", err)
 	}
-
-	GenesisContextData, err := initGenesisContextData(act, tran, evt)
+	bs, err := chain.NewBootstrap(cfg.genesis, cfg.RewardPolicy())
 	if err != nil {
-		panic(err)
+		logging.Get(logging.Kernel).Fatal("failed to build the genesis", "file", cfg.GenesisFile, "error", err)
 	}
 
 	cm := closer.NewManager()
@@ -79,45 +85,29 @@ func main() {
 	}()
 	defer cm.CloseAll()
 
-	var ks *kernel.Store
-	if s, err := kernel.NewStore(cfg.StoreRoot+"/kernel", BlockchainVersion, act, tran, evt, cfg.ForceRecover); err != nil {
-		if cfg.ForceRecover || err != badger.ErrTruncateNeeded {
-			panic(err)
-		} else {
-			fmt.Println(err)
-			fmt.Println("Do you want to recover database(it can be failed)? [y/n]")
-			var answer string
-			fmt.Scanf("%s", &answer)
-			if strings.ToLower(answer) == "y" {
-				if s, err := kernel.NewStore(cfg.StoreRoot+"/kernel", BlockchainVersion, act, tran, evt, true); err != nil {
-					panic(err)
-				} else {
-					ks = s
-				}
-			} else {
-				os.Exit(1)
-			}
-		}
-	} else {
-		ks = s
+	ks, err := bs.OpenStore(cfg.KernelStoreConfig())
+	if err != nil {
+		logging.Get(logging.Store).Fatal("failed to open the kernel store", "error", err)
 	}
 	cm.Add("kernel.Store", ks)
 
-	rd := reward.NewTestNetRewarder()
-	kn, err := kernel.NewKernel(&kernel.Config{
-		ChainCoord:              GenCoord,
-		ObserverKeyMap:          ObserverKeyMap,
-		MaxBlocksPerFormulator:  8,
-		MaxTransactionsPerBlock: 5000,
-	}, ks, rd, GenesisContextData)
+	logging.Get(logging.Kernel).Info("reward policy", "mode", bs.RewardSchedule.Mode, "curve", bs.RewardSchedule.Curve, "hash", bs.RewardSchedule.Hash())
+	rd := chain.NewRewarder(bs.RewardSchedule)
+	var pr *chain.PayoutRecorder
+	if cfg.AddressIndex {
+		pr = chain.NewPayoutRecorder(rd, ks)
+		rd = pr
+	}
+	kn, err := bs.NewKernel(ks, rd, ObserverKeyMap)
 	if err != nil {
-		panic(err)
+		cm.CloseAll()
+		logging.Get(logging.Kernel).Fatal("failed to create the kernel", "error", err)
 	}
 	cm.RemoveAll()
 	cm.Add("kernel.Kernel", kn)
 
 	ndcfg := &node.Config{
-		ChainCoord: GenCoord,
+		ChainCoord: bs.ChainCoord,
 		SeedNodes:  cfg.SeedNodes,
 		Router: router.Config{
 			Network: "tcp",
@@ -132,18 +122,48 @@ func main() {
 	}
 	nd, err := node.NewNode(ndcfg, kn)
 	if err != nil {
-		panic(err)
+		cm.CloseAll()
+		logging.Get(logging.Main).Fatal("failed to create the node", "error", err)
 	}
 	cm.RemoveAll()
 	cm.Add("cmd.Node", nd)
 
+	idx, err := index.Open(cfg.StoreRoot+"/index", bs.Eventer, cfg.AddressIndex)
+	if err != nil {
+		cm.CloseAll()
+		logging.Get(logging.Index).Fatal("failed to open the index", "error", err)
+	}
+	cm.Add("index.Index", idx)
+	if pr != nil {
+		idx.SetPayoutSource(pr)
+	}
+	if err := idx.Backfill(kn.Provider(), bs, ObserverKeyMap, cfg.StoreRoot+"/replay"); err != nil {
+		cm.CloseAll()
+		logging.Get(logging.Index).Fatal("failed to backfill the index", "error", err)
+	}
+	kn.AddEventHandler(idx)
+
 	go nd.Run()
 
-	rm := rpc.NewManager()
+	as, err := command.NewAPIServer(&cfg.APIConfig, kn, bs.Eventer)
+	if err != nil {
+		cm.CloseAll()
+		logging.Get(logging.RPC).Fatal("failed to create the api", "error", err)
+	}
+	rm := as.Manager
 	cm.RemoveAll()
-	cm.Add("rpc.Manager", rm)
+	cm.Add("health.Health", as.Health)
+	cm.Add("api.Manager", rm)
 	cm.Add("cmd.Node", nd)
-	kn.AddEventHandler(rm)
+	cm.Add("index.Index", idx)
+
+	metrics.WatchStores(as.Registry, map[string]string{
+		"kernel": cfg.StoreRoot + "/kernel",
+		"index":  cfg.StoreRoot + "/index",
+	})
+	metrics.WatchPeers(as.Registry, map[string]func() int{
+		"node": metrics.ConnectedPeers(nd),
+	})
 
 	defer func() {
 		cm.CloseAll()
@@ -199,23 +219,268 @@ func main() {
 		if err != nil {
 			return nil, err
 		}
-		cd, err := kn.Provider().Data(height)
+		return api.BlockByHeight(kn.Provider(), height)
+	})
+	rm.Add("LastBlock", func(kn *kernel.Kernel, ID interface{}, arg *rpc.Argument) (interface{}, error) {
+		provider := kn.Provider()
+		return api.BlockByHeight(provider, provider.Height())
+	})
+	rm.Add("BlockByHash", func(kn *kernel.Kernel, ID interface{}, arg *rpc.Argument) (interface{}, error) {
+		if arg.Len() < 1 {
+			return nil, rpc.ErrInvalidArgument
+		}
+		BlockHash, err := api.Hash(arg, 0)
 		if err != nil {
 			return nil, err
 		}
-		b := &block.Block{
-			Header: cd.Header.(*block.Header),
-			Body:   cd.Body.(*block.Body),
+		height, err := idx.BlockHeight(BlockHash)
+		if err != nil {
+			return nil, err
 		}
-		return b, nil
+		return api.BlockByHeight(kn.Provider(), height)
+	})
+	rm.Add("HeaderByHash", func(kn *kernel.Kernel, ID interface{}, arg *rpc.Argument) (interface{}, error) {
+		if arg.Len() < 1 {
+			return nil, rpc.ErrInvalidArgument
+		}
+		BlockHash, err := api.Hash(arg, 0)
+		if err != nil {
+			return nil, err
+		}
+		height, err := idx.BlockHeight(BlockHash)
+		if err != nil {
+			return nil, err
+		}
+		return kn.Provider().Header(height)
+	})
+	rm.Add("Blocks", func(kn *kernel.Kernel, ID interface{}, arg *rpc.Argument) (interface{}, error) {
+		if arg.Len() < 2 {
+			return nil, rpc.ErrInvalidArgument
+		}
+		from, err := arg.Uint32(0)
+		if err != nil {
+			return nil, err
+		}
+		to, err := arg.Uint32(1)
+		if err != nil {
+			return nil, err
+		}
+		return api.Blocks(kn.Provider(), from, to)
+	})
+	rm.Add("Headers", func(kn *kernel.Kernel, ID interface{}, arg *rpc.Argument) (interface{}, error) {
+		if arg.Len() < 2 {
+			return nil, rpc.ErrInvalidArgument
+		}
+		from, err := arg.Uint32(0)
+		if err != nil {
+			return nil, err
+		}
+		to, err := arg.Uint32(1)
+		if err != nil {
+			return nil, err
+		}
+		return api.Headers(kn.Provider(), from, to)
+	})
+	rm.Add("TxFeeTable", func(kn *kernel.Kernel, ID interface{}, arg *rpc.Argument) (interface{}, error) {
+		return bs.TxFeeTable, nil
 	})
 
-	go func() {
-		if err := rm.Run(kn, ":"+strconv.Itoa(cfg.APIPort)); err != nil {
-			if http.ErrServerClosed != err {
-				panic(err)
+	// Account
+	rm.Add("Account", func(kn *kernel.Kernel, ID interface{}, arg *rpc.Argument) (interface{}, error) {
+		if arg.Len() < 1 {
+			return nil, rpc.ErrInvalidArgument
+		}
+		addr, err := api.Address(arg, 0)
+		if err != nil {
+			return nil, err
+		}
+		loader := kn.Loader()
+		acc, err := loader.Account(addr)
+		if err != nil {
+			return nil, err
+		}
+		return api.NewAccountView(loader, acc)
+	})
+	rm.Add("Balance", func(kn *kernel.Kernel, ID interface{}, arg *rpc.Argument) (interface{}, error) {
+		if arg.Len() < 1 {
+			return nil, rpc.ErrInvalidArgument
+		}
+		addr, err := api.Address(arg, 0)
+		if err != nil {
+			return nil, err
+		}
+		acc, err := kn.Loader().Account(addr)
+		if err != nil {
+			return nil, err
+		}
+		return acc.Balance(), nil
+	})
+	rm.Add("Seq", func(kn *kernel.Kernel, ID interface{}, arg *rpc.Argument) (interface{}, error) {
+		if arg.Len() < 1 {
+			return nil, rpc.ErrInvalidArgument
+		}
+		addr, err := api.Address(arg, 0)
+		if err != nil {
+			return nil, err
+		}
+		return kn.Loader().Seq(addr), nil
+	})
+	rm.Add("AccountByName", func(kn *kernel.Kernel, ID interface{}, arg *rpc.Argument) (interface{}, error) {
+		if arg.Len() < 1 {
+			return nil, rpc.ErrInvalidArgument
+		}
+		name, err := arg.String(0)
+		if err != nil {
+			return nil, err
+		}
+		loader := kn.Loader()
+		addr, err := loader.AddressByName(name)
+		if err != nil {
+			GenAddr, has := GenesisNameMap[name]
+			if !has {
+				return nil, err
+			}
+			addr = GenAddr
+		}
+		acc, err := loader.Account(addr)
+		if err != nil {
+			return nil, err
+		}
+		return api.NewAccountView(loader, acc)
+	})
+	rm.Add("IsExistAccount", func(kn *kernel.Kernel, ID interface{}, arg *rpc.Argument) (interface{}, error) {
+		if arg.Len() < 1 {
+			return nil, rpc.ErrInvalidArgument
+		}
+		addr, err := api.Address(arg, 0)
+		if err != nil {
+			return nil, err
+		}
+		return kn.Loader().IsExistAccount(addr)
+	})
+	rm.Add("AddressHistory", func(kn *kernel.Kernel, ID interface{}, arg *rpc.Argument) (interface{}, error) {
+		if arg.Len() < 1 {
+			return nil, rpc.ErrInvalidArgument
+		}
+		addr, err := api.Address(arg, 0)
+		if err != nil {
+			return nil, err
+		}
+		var Cursor string
+		if arg.Len() > 1 {
+			if Cursor, err = api.OptionalString(arg, 1); err != nil {
+				return nil, err
 			}
 		}
+		var Limit int
+		if arg.Len() > 2 {
+			if Limit, err = arg.Int(2); err != nil {
+				return nil, err
+			}
+		}
+		return api.AddressHistoryOf(idx, addr, Cursor, Limit)
+	})
+
+	// Transaction
+	ts := api.NewTxSender(kn, nd, bs.TxFeeTable)
+	rm.AddUnlocked("SendTransaction", func(kn *kernel.Kernel, ID interface{}, arg *rpc.Argument) (interface{}, error) {
+		if arg.Len() < 2 {
+			return nil, rpc.ErrInvalidArgument
+		}
+		TxData, err := arg.String(0)
+		if err != nil {
+			return nil, err
+		}
+		SigData := make([]string, 0, arg.Len()-1)
+		for i := 1; i < arg.Len(); i++ {
+			sig, err := arg.String(i)
+			if err != nil {
+				return nil, err
+			}
+			SigData = append(SigData, sig)
+		}
+		return ts.Send(TxData, SigData)
+	})
+
+	rm.Add("Transaction", func(kn *kernel.Kernel, ID interface{}, arg *rpc.Argument) (interface{}, error) {
+		if arg.Len() < 1 {
+			return nil, rpc.ErrInvalidArgument
+		}
+		TxHash, err := api.Hash(arg, 0)
+		if err != nil {
+			return nil, err
+		}
+		return api.TransactionByHash(kn, idx, TxHash)
+	})
+	rm.Add("TransactionReceipt", func(kn *kernel.Kernel, ID interface{}, arg *rpc.Argument) (interface{}, error) {
+		if arg.Len() < 1 {
+			return nil, rpc.ErrInvalidArgument
+		}
+		TxHash, err := api.Hash(arg, 0)
+		if err != nil {
+			return nil, err
+		}
+		return api.TransactionReceiptByHash(kn, idx, TxHash)
+	})
+
+	// Consensus
+	rm.Add("ConsensusPolicy", func(kn *kernel.Kernel, ID interface{}, arg *rpc.Argument) (interface{}, error) {
+		policy, err := consensus.GetConsensusPolicy(kn.ChainCoord())
+		if err != nil {
+			return nil, err
+		}
+		return policy, nil
+	})
+	rm.Add("RewardSchedule", func(kn *kernel.Kernel, ID interface{}, arg *rpc.Argument) (interface{}, error) {
+		height := kn.Provider().Height() + 1
+		if arg.Len() > 0 {
+			h, err := arg.Uint32(0)
+			if err != nil {
+				return nil, err
+			}
+			height = h
+		}
+		return bs.RewardSchedule.Expected(height), nil
+	})
+
+	// REST
+	addrParam := &api.RouteParam{Name: "address", In: api.InPath, Type: api.TypeString, Required: true, Description: "the address"}
+	hashParam := &api.RouteParam{Name: "hash", In: api.InPath, Type: api.TypeString, Required: true, Description: "the hash in hex"}
+	fromParam := &api.RouteParam{Name: "from", In: api.InQuery, Type: api.TypeInteger, Required: true, Description: "the first height of the range"}
+	toParam := &api.RouteParam{Name: "to", In: api.InQuery, Type: api.TypeInteger, Required: true, Description: "the last height of the range"}
+	routes := []*api.Route{
+		{HTTPMethod: http.MethodGet, Path: "/blocks/latest", Method: "LastBlock", Summary: "the last block"},
+		{HTTPMethod: http.MethodGet, Path: "/blocks/:height", Method: "Block", Summary: "the block of the height", Params: []*api.RouteParam{
+			{Name: "height", In: api.InPath, Type: api.TypeInteger, Required: true, Description: "the height of the block"},
+		}},
+		{HTTPMethod: http.MethodGet, Path: "/blocks/hash/:hash", Method: "BlockByHash", Summary: "the block of the hash", Params: []*api.RouteParam{hashParam}},
+		{HTTPMethod: http.MethodGet, Path: "/blocks", Method: "Blocks", Summary: "the blocks of the range", Params: []*api.RouteParam{fromParam, toParam}},
+		{HTTPMethod: http.MethodGet, Path: "/headers", Method: "Headers", Summary: "the headers of the range", Params: []*api.RouteParam{fromParam, toParam}},
+		{HTTPMethod: http.MethodGet, Path: "/accounts/:address", Method: "Account", Summary: "the account of the address", Params: []*api.RouteParam{addrParam}},
+		{HTTPMethod: http.MethodGet, Path: "/accounts/:address/history", Method: "AddressHistory", Summary: "the history of the address", Params: []*api.RouteParam{
+			addrParam,
+			{Name: "cursor", In: api.InQuery, Type: api.TypeString, Description: "the cursor of the next page"},
+			{Name: "limit", In: api.InQuery, Type: api.TypeInteger, Description: "the number of the items"},
+		}},
+		{HTTPMethod: http.MethodGet, Path: "/reward/schedule", Method: "RewardSchedule", Summary: "the expected reward of the height", Params: []*api.RouteParam{
+			{Name: "height", In: api.InQuery, Type: api.TypeInteger, Description: "the height of the block, the next height when it is not given"},
+		}},
+		{HTTPMethod: http.MethodGet, Path: "/tx/:hash", Method: "Transaction", Summary: "the transaction of the hash", Params: []*api.RouteParam{hashParam}},
+		{HTTPMethod: http.MethodGet, Path: "/tx/:hash/receipt", Method: "TransactionReceipt", Summary: "the receipt of the transaction of the hash", Params: []*api.RouteParam{hashParam}},
+		{HTTPMethod: http.MethodPost, Path: "/tx", Method: "SendTransaction", Summary: "sends the signed transaction", Params: []*api.RouteParam{
+			{Name: "tx", In: api.InBody, Type: api.TypeString, Required: true, Description: "the type and the transaction in hex or base64"},
+			{Name: "signatures", In: api.InBody, Type: api.TypeList, Required: true, Description: "the signatures in hex or base64"},
+		}},
+	}
+	for _, rt := range routes {
+		rm.AddRoute(rt)
+	}
+
+	go func() {
+		if err := as.Run(kn); err != nil {
+			cm.CloseAll()
+			logging.Get(logging.RPC).Fatal("failed to serve the api", "error", err)
+		}
 	}()
 
 	cm.Wait()
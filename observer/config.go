@@ -0,0 +1,87 @@
+package main
+
+import (
+	"sort"
+
+	"github.com/fletaio/cmd/chain"
+	"github.com/fletaio/cmd/command"
+	"github.com/fletaio/common"
+)
+
+// Config is a configuration for the cmd
+type Config struct {
+	ObserverKeyMap    map[string]string
+	KeyHex            string
+	KeyFile           string `config:"path"`
+	KeyPassphraseFile string `config:"path"`
+	SignerEndpoint    string `config:"socket"`
+	ObseverPort       int    `flag:"observer-port"`
+	FormulatorPort    int
+	PeerTLSCert       string `flag:"peer-tls-cert" config:"path"`
+	PeerTLSKey        string `flag:"peer-tls-key" config:"path"`
+	PeerTLSCA         string `flag:"peer-tls-ca" config:"path"`
+	command.APIConfig
+	command.StoreConfig
+	command.LogConfig
+	command.RewardConfig
+
+	genesis *chain.Genesis // the genesis loaded by Validate
+}
+
+// Validate checks every field of the config and reports all problems together
+func (cfg *Config) Validate() error {
+	var es command.ConfigErrors
+	KeyHash, hasKey := es.CheckSigningKey(cfg.KeyHex, cfg.KeyFile, cfg.SignerEndpoint)
+	if len(cfg.ObserverKeyMap) == 0 {
+		es.Addf("ObserverKeyMap", "observer keys are not given")
+	}
+	keys := make([]string, 0, len(cfg.ObserverKeyMap))
+	for k := range cfg.ObserverKeyMap {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	keyMap := map[common.PublicHash]bool{}
+	addrMap := map[string]string{}
+	for _, k := range keys {
+		netAddr := cfg.ObserverKeyMap[k]
+		if pubhash, err := chain.ParsePublicHash(k); err != nil {
+			es.Addf("ObserverKeyMap", "invalid observer key %q: %v", k, err)
+		} else {
+			keyMap[pubhash] = true
+		}
+		es.CheckNetAddr("ObserverKeyMap", netAddr)
+		if prev, has := addrMap[netAddr]; has {
+			es.Addf("ObserverKeyMap", "address %q of %q is already used by %q", netAddr, k, prev)
+		}
+		addrMap[netAddr] = k
+	}
+	if hasKey && len(keyMap) > 0 && !keyMap[KeyHash] {
+		es.Addf(cfg.keyField(), "key hash %s is not in the ObserverKeyMap", KeyHash.String())
+	}
+	es.CheckPorts(
+		command.PortField{Field: "ObseverPort", Port: cfg.ObseverPort},
+		command.PortField{Field: "FormulatorPort", Port: cfg.FormulatorPort},
+		command.PortField{Field: "APIPort", Port: cfg.APIPort},
+		command.PortField{Field: "MetricsPort", Port: cfg.MetricsPort, Optional: true},
+	)
+	es.CheckStoreConfig(&cfg.StoreConfig)
+	es.CheckAPIConfig(&cfg.APIConfig)
+	es.CheckLogging(cfg.LoggingConfig())
+	es.CheckRewardConfig(&cfg.RewardConfig)
+	es.CheckPeerTLS(cfg.PeerTLSCert, cfg.PeerTLSKey, cfg.PeerTLSCA)
+	if gen, err := chain.LoadGenesis(cfg.GenesisFile); err != nil {
+		es.Add("GenesisFile", err)
+	} else {
+		cfg.genesis = gen
+		es.CheckRewardPolicy(gen, cfg.RewardPolicy())
+	}
+	return es.Err()
+}
+
+// keyField returns the field name of the key that is used
+func (cfg *Config) keyField() string {
+	if len(cfg.KeyFile) > 0 {
+		return "KeyFile"
+	}
+	return "KeyHex"
+}
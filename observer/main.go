@@ -1,80 +1,77 @@
 package main
 
 import (
-	"encoding/hex"
+	"flag"
 	"fmt"
-	"net/http"
 	"os"
 	"os/signal"
 	"strconv"
-	"strings"
 	"syscall"
 
-	"github.com/dgraph-io/badger"
-
+	"github.com/fletaio/cmd/api"
+	"github.com/fletaio/cmd/chain"
+	"github.com/fletaio/cmd/command"
+	"github.com/fletaio/cmd/logging"
+	"github.com/fletaio/cmd/metrics"
+	"github.com/fletaio/cmd/tlsutil"
 	"github.com/fletaio/common"
-	"github.com/fletaio/core/block"
-	"github.com/fletaio/core/data"
+	"github.com/fletaio/core/consensus"
 	"github.com/fletaio/core/kernel"
-	"github.com/fletaio/core/key"
 	"github.com/fletaio/core/observer"
-	"github.com/fletaio/core/reward"
 	"github.com/fletaio/framework/closer"
-	"github.com/fletaio/framework/config"
 	"github.com/fletaio/framework/rpc"
 )
 
-// Config is a configuration for the cmd
-type Config struct {
-	ObserverKeyMap map[string]string
-	KeyHex         string
-	ObseverPort    int
-	FormulatorPort int
-	APIPort        int
-	StoreRoot      string
-	ForceRecover   bool
-}
-
 func main() {
-	var cfg Config
-	if err := config.LoadFile("./config.toml", &cfg); err != nil {
-		panic(err)
+	cfg := Config{
+		StoreConfig: command.StoreConfig{
+			StoreRoot: "./observer",
+		},
 	}
-	if len(cfg.StoreRoot) == 0 {
-		cfg.StoreRoot = "./observer"
+	if command.IsCommand(os.Args[1:]) {
+		if err := command.Run(os.Args[1:], &cfg); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
 	}
-
-	var obkey key.Key
-	if bs, err := hex.DecodeString(cfg.KeyHex); err != nil {
-		panic(err)
-	} else if Key, err := key.NewMemoryKeyFromBytes(bs); err != nil {
-		panic(err)
-	} else {
-		obkey = Key
+	if err := command.LoadConfig(os.Args[1:], &cfg); err != nil {
+		if err == flag.ErrHelp {
+			return
+		}
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if err := logging.Setup(cfg.LoggingConfig()); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
 	}
 
 	ObserverKeyMap := map[common.PublicHash]string{}
 	ObserverKeyBoolMap := map[common.PublicHash]bool{}
+	ObserverKeyHashes := make([]common.PublicHash, 0, len(cfg.ObserverKeyMap))
 	for k, netAddr := range cfg.ObserverKeyMap {
 		pubhash, err := common.ParsePublicHash(k)
 		if err != nil {
-			panic(err)
+			logging.Get(logging.Main).Fatal("invalid observer key", "key", k, "error", err)
 		}
 		ObserverKeyMap[pubhash] = netAddr
 		ObserverKeyBoolMap[pubhash] = true
+		ObserverKeyHashes = append(ObserverKeyHashes, pubhash)
 	}
 
-	GenCoord := common.NewCoordinate(0, 0)
-	act := data.NewAccounter(GenCoord)
-	tran := data.NewTransactor(GenCoord)
-	evt := data.NewEventer(GenCoord)
-	if err := initChainComponent(act, tran, evt); err != nil {
-		panic(err)
+	obkey, err := command.LoadSigningKey(cfg.KeyHex, cfg.KeyFile, cfg.KeyPassphraseFile, cfg.SignerEndpoint, ObserverKeyHashes...)
+	if err != nil {
+		logging.Get(logging.Main).Fatal("failed to load the signing key", "error", err)
 	}
 
-	GenesisContextData, err := initGenesisContextData(act, tran, evt)
+	bs, err := chain.NewBootstrap(cfg.genesis, cfg.RewardPolicy())
 	if err != nil {
-		panic(err)
+		logging.Get(logging.Kernel).Fatal("failed to build the genesis", "file", cfg.GenesisFile, "error", err)
 	}
 
 	cm := closer.NewManager()
@@ -90,62 +87,101 @@ func main() {
 	}()
 	defer cm.CloseAll()
 
-	var ks *kernel.Store
-	if s, err := kernel.NewStore(cfg.StoreRoot+"/kernel", BlockchainVersion, act, tran, evt, cfg.ForceRecover); err != nil {
-		if cfg.ForceRecover || err != badger.ErrTruncateNeeded {
-			panic(err)
-		} else {
-			fmt.Println(err)
-			fmt.Println("Do you want to recover database(it can be failed)? [y/n]")
-			var answer string
-			fmt.Scanf("%s", &answer)
-			if strings.ToLower(answer) == "y" {
-				if s, err := kernel.NewStore(cfg.StoreRoot+"/kernel", BlockchainVersion, act, tran, evt, true); err != nil {
-					panic(err)
-				} else {
-					ks = s
-				}
-			} else {
-				os.Exit(1)
-			}
-		}
-	} else {
-		ks = s
+	ks, err := bs.OpenStore(cfg.KernelStoreConfig())
+	if err != nil {
+		logging.Get(logging.Store).Fatal("failed to open the kernel store", "error", err)
 	}
 	cm.Add("kernel.Store", ks)
 
-	rd := reward.NewTestNetRewarder()
-	kn, err := kernel.NewKernel(&kernel.Config{
-		ChainCoord:              GenCoord,
-		ObserverKeyMap:          ObserverKeyBoolMap,
-		MaxBlocksPerFormulator:  8,
-		MaxTransactionsPerBlock: 5000,
-	}, ks, rd, GenesisContextData)
+	logging.Get(logging.Kernel).Info("reward policy", "mode", bs.RewardSchedule.Mode, "curve", bs.RewardSchedule.Curve, "hash", bs.RewardSchedule.Hash())
+	rd := chain.NewRewarder(bs.RewardSchedule)
+	kn, err := bs.NewKernel(ks, rd, ObserverKeyBoolMap)
 	if err != nil {
-		panic(err)
+		cm.CloseAll()
+		logging.Get(logging.Kernel).Fatal("failed to create the kernel", "error", err)
 	}
 	cm.RemoveAll()
 	cm.Add("kernel.Kernel", kn)
 
+	// the observer listens and connects the other observers by the tunnels of the mutual tls,
+	// and the plaintext listeners of the observer are bound to the loopback
+	BindObserver := ":" + strconv.Itoa(cfg.ObseverPort)
+	BindFormulator := ":" + strconv.Itoa(cfg.FormulatorPort)
+	tunnels := tlsutil.Tunnels{}
+	if len(cfg.PeerTLSCert) > 0 {
+		pt, err := tlsutil.LoadPeer(cfg.PeerTLSCert, cfg.PeerTLSKey, cfg.PeerTLSCA)
+		if err != nil {
+			cm.CloseAll()
+			logging.Get(logging.Peer).Fatal("failed to load the peer tls", "error", err)
+		}
+		ObPubHash := common.NewPublicHash(obkey.PublicKey())
+		for pubhash, netAddr := range ObserverKeyMap {
+			if pubhash.Equal(ObPubHash) {
+				continue
+			}
+			tn, err := pt.Open(netAddr)
+			if err != nil {
+				tunnels.Close()
+				cm.CloseAll()
+				logging.Get(logging.Tunnel).Fatal("failed to open the tunnel", "addr", netAddr, "error", err)
+			}
+			ObserverKeyMap[pubhash] = tn.Addr()
+			tunnels = append(tunnels, tn)
+		}
+		tn, Target, err := servePeer(pt, BindObserver)
+		if err != nil {
+			tunnels.Close()
+			cm.CloseAll()
+			logging.Get(logging.Peer).Fatal("failed to serve the peer tls", "bind", BindObserver, "error", err)
+		}
+		BindObserver = Target
+		tunnels = append(tunnels, tn)
+		if tn, Target, err = servePeer(pt, BindFormulator); err != nil {
+			tunnels.Close()
+			cm.CloseAll()
+			logging.Get(logging.Peer).Fatal("failed to serve the peer tls", "bind", BindFormulator, "error", err)
+		}
+		BindFormulator = Target
+		tunnels = append(tunnels, tn)
+		cm.Add("tlsutil.Tunnels", tunnels)
+	}
+
 	obcfg := &observer.Config{
-		ChainCoord:     GenCoord,
+		ChainCoord:     bs.ChainCoord,
 		Key:            obkey,
 		ObserverKeyMap: ObserverKeyMap,
 	}
 	ob, err := observer.NewObserver(obcfg, kn)
 	if err != nil {
-		panic(err)
+		cm.CloseAll()
+		logging.Get(logging.Main).Fatal("failed to create the observer", "error", err)
 	}
 	cm.RemoveAll()
 	cm.Add("cmd.Observer", ob)
+	cm.Add("tlsutil.Tunnels", tunnels)
 
-	go ob.Run(":"+strconv.Itoa(cfg.ObseverPort), ":"+strconv.Itoa(cfg.FormulatorPort))
+	go ob.Run(BindObserver, BindFormulator)
 
-	rm := rpc.NewManager()
+	as, err := command.NewAPIServer(&cfg.APIConfig, kn, bs.Eventer)
+	if err != nil {
+		cm.CloseAll()
+		logging.Get(logging.RPC).Fatal("failed to create the api", "error", err)
+	}
+	rm := as.Manager
 	cm.RemoveAll()
-	cm.Add("rpc.Manager", rm)
+	cm.Add("health.Health", as.Health)
+	cm.Add("api.Manager", rm)
 	cm.Add("cmd.Observer", ob)
-	kn.AddEventHandler(rm)
+	cm.Add("tlsutil.Tunnels", tunnels)
+
+	metrics.WatchStores(as.Registry, map[string]string{
+		"kernel": cfg.StoreRoot + "/kernel",
+	})
+	metrics.WatchPeers(as.Registry, map[string]func() int{
+		"observer":   metrics.MeshPeers(ob),
+		"formulator": metrics.FormulatorPeers(ob),
+	})
+	metrics.WatchRound(as.Registry, ob)
 
 	defer func() {
 		cm.CloseAll()
@@ -201,24 +237,51 @@ func main() {
 		if err != nil {
 			return nil, err
 		}
-		cd, err := kn.Provider().Data(height)
+		return api.BlockByHeight(kn.Provider(), height)
+	})
+	rm.Add("TxFeeTable", func(kn *kernel.Kernel, ID interface{}, arg *rpc.Argument) (interface{}, error) {
+		return bs.TxFeeTable, nil
+	})
+
+	// Consensus
+	rm.Add("ConsensusPolicy", func(kn *kernel.Kernel, ID interface{}, arg *rpc.Argument) (interface{}, error) {
+		policy, err := consensus.GetConsensusPolicy(kn.ChainCoord())
 		if err != nil {
 			return nil, err
 		}
-		b := &block.Block{
-			Header: cd.Header.(*block.Header),
-			Body:   cd.Body.(*block.Body),
+		return policy, nil
+	})
+	rm.Add("RewardSchedule", func(kn *kernel.Kernel, ID interface{}, arg *rpc.Argument) (interface{}, error) {
+		height := kn.Provider().Height() + 1
+		if arg.Len() > 0 {
+			h, err := arg.Uint32(0)
+			if err != nil {
+				return nil, err
+			}
+			height = h
 		}
-		return b, nil
+		return bs.RewardSchedule.Expected(height), nil
 	})
 
 	go func() {
-		if err := rm.Run(kn, ":"+strconv.Itoa(cfg.APIPort)); err != nil {
-			if http.ErrServerClosed != err {
-				panic(err)
-			}
+		if err := as.Run(kn); err != nil {
+			cm.CloseAll()
+			logging.Get(logging.RPC).Fatal("failed to serve the api", "error", err)
 		}
 	}()
 
 	cm.Wait()
 }
+
+// servePeer serves the mutual tls of the bind address and returns the tunnel and the loopback address to bind the plaintext listener
+func servePeer(pt *tlsutil.Peer, Bind string) (*tlsutil.Tunnel, string, error) {
+	Target, err := tlsutil.LoopbackAddr()
+	if err != nil {
+		return nil, "", err
+	}
+	tn, err := pt.Serve(Bind, Target)
+	if err != nil {
+		return nil, "", err
+	}
+	return tn, Target, nil
+}
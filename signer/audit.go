@@ -0,0 +1,66 @@
+package signer
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+// AuditRecord is a line of the audit log
+type AuditRecord struct {
+	Time    string `json:"time"`
+	Remote  string `json:"remote"`
+	Kind    string `json:"kind"`
+	Hash    string `json:"hash"`
+	Height  uint32 `json:"height,omitempty"`
+	Round   uint32 `json:"round,omitempty"`
+	Subject string `json:"subject,omitempty"`
+	Result  string `json:"result"`
+	Error   string `json:"error,omitempty"`
+}
+
+// audit results
+const (
+	AuditSigned   = "signed"
+	AuditRejected = "rejected"
+)
+
+// AuditLog appends every sign request to the file as a json line
+type AuditLog struct {
+	sync.Mutex
+	file *os.File
+}
+
+// NewAuditLog returns an AuditLog that appends to the file of the path
+func NewAuditLog(path string) (*AuditLog, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &AuditLog{file: f}, nil
+}
+
+// Write appends the record and syncs the file
+func (al *AuditLog) Write(rec *AuditRecord) error {
+	al.Lock()
+	defer al.Unlock()
+
+	rec.Time = time.Now().UTC().Format(time.RFC3339Nano)
+	bs, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if _, err := al.file.Write(append(bs, '\n')); err != nil {
+		return err
+	}
+	return al.file.Sync()
+}
+
+// Close closes the file of the audit log
+func (al *AuditLog) Close() {
+	al.Lock()
+	defer al.Unlock()
+
+	al.file.Close()
+}
@@ -0,0 +1,22 @@
+package signer
+
+import (
+	"errors"
+)
+
+// signer errors
+var (
+	ErrNotSupported       = errors.New("not supported")
+	ErrInvalidEndpoint    = errors.New("invalid endpoint")
+	ErrInvalidKind        = errors.New("invalid kind")
+	ErrInvalidHeight      = errors.New("invalid height")
+	ErrDoubleSign         = errors.New("double sign")
+	ErrTooOldHeight       = errors.New("too old height")
+	ErrMismatchPublicKey  = errors.New("mismatch public key")
+	ErrInvalidSignature   = errors.New("invalid signature")
+	ErrRejectedBySigner   = errors.New("rejected by signer")
+	ErrInvalidRequestBody = errors.New("invalid request body")
+	ErrInvalidSubject     = errors.New("invalid subject")
+	ErrInvalidChallenge   = errors.New("invalid challenge")
+	ErrInvalidMessage     = errors.New("invalid message")
+)
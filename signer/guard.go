@@ -0,0 +1,147 @@
+package signer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+)
+
+// GuardWindow is the number of the recent heights that the guard remembers
+const GuardWindow = 256
+
+// Guard protects the key from signing two different subjects of the same kind, height and round
+// The subject is made by the server from the signed message: the header hash of the block, the hash of the round vote without the reply flag
+// and the hash of the header hash and the generator signature of the block vote, so a different message is never signed as the same subject
+// The signed subjects are stored to the file before the signature is returned, so it works across the restart
+type Guard struct {
+	sync.Mutex
+	path      string
+	maxHeight uint32
+	signed    map[guardKey]string
+}
+
+type guardKey struct {
+	Kind   string
+	Height uint32
+	Round  uint32
+}
+
+type guardState struct {
+	MaxHeight uint32         `json:"maxHeight"`
+	Signed    []*guardRecord `json:"signed"`
+}
+
+type guardRecord struct {
+	Kind    string `json:"kind"`
+	Height  uint32 `json:"height"`
+	Round   uint32 `json:"round"`
+	Subject string `json:"subject"`
+}
+
+// NewGuard returns a Guard that loads the signed subjects from the file of the path
+func NewGuard(path string) (*Guard, error) {
+	g := &Guard{
+		path:   path,
+		signed: map[guardKey]string{},
+	}
+	bs, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return g, nil
+		}
+		return nil, err
+	}
+	var st guardState
+	if err := json.Unmarshal(bs, &st); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	g.maxHeight = st.MaxHeight
+	for _, v := range st.Signed {
+		g.signed[guardKey{Kind: v.Kind, Height: v.Height, Round: v.Round}] = v.Subject
+	}
+	return g, nil
+}
+
+// Check records the subject of the kind, the height and the round when it is not conflicted with the signed one
+// Signing the same subject of the same kind, height and round again is allowed
+func (g *Guard) Check(Kind string, Height uint32, Round uint32, Subject string) error {
+	g.Lock()
+	defer g.Unlock()
+
+	if Height == 0 {
+		return ErrInvalidHeight
+	}
+	if len(Subject) == 0 {
+		return ErrInvalidSubject
+	}
+	gk := guardKey{Kind: Kind, Height: Height, Round: Round}
+	if prev, has := g.signed[gk]; has {
+		if prev != Subject {
+			return fmt.Errorf("%v: %s of height %d round %d is already signed with %s", ErrDoubleSign, Kind, Height, Round, prev)
+		}
+		return nil
+	}
+	if g.maxHeight > GuardWindow && Height <= g.maxHeight-GuardWindow {
+		return fmt.Errorf("%v: height %d is older than the window of the last signed height %d", ErrTooOldHeight, Height, g.maxHeight)
+	}
+
+	g.signed[gk] = Subject
+	prevMaxHeight := g.maxHeight
+	if Height > g.maxHeight {
+		g.maxHeight = Height
+	}
+	if err := g.save(); err != nil {
+		delete(g.signed, gk)
+		g.maxHeight = prevMaxHeight
+		return err
+	}
+	if g.maxHeight > GuardWindow {
+		for k := range g.signed {
+			if k.Height <= g.maxHeight-GuardWindow {
+				delete(g.signed, k)
+			}
+		}
+	}
+	return nil
+}
+
+func (g *Guard) save() error {
+	st := &guardState{
+		MaxHeight: g.maxHeight,
+		Signed:    make([]*guardRecord, 0, len(g.signed)),
+	}
+	for k, v := range g.signed {
+		if g.maxHeight > GuardWindow && k.Height <= g.maxHeight-GuardWindow {
+			continue
+		}
+		st.Signed = append(st.Signed, &guardRecord{
+			Kind:    k.Kind,
+			Height:  k.Height,
+			Round:   k.Round,
+			Subject: v,
+		})
+	}
+	bs, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+	tmp := g.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(bs); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, g.path)
+}
@@ -0,0 +1,83 @@
+package signer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newTestGuard(t *testing.T) (*Guard, string) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "guard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := NewGuard(filepath.Join(dir, "guard.json"))
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return g, dir
+}
+
+func TestGuardDoubleSign(t *testing.T) {
+	g, dir := newTestGuard(t)
+	defer os.RemoveAll(dir)
+
+	if err := g.Check(KindBlock, 10, 0, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Check(KindBlock, 10, 0, "a"); err != nil {
+		t.Fatalf("the same subject is refused: %v", err)
+	}
+	if err := g.Check(KindBlock, 10, 0, "b"); err == nil {
+		t.Fatal("the second subject of the same height is signed")
+	}
+	if err := g.Check(KindBlock, 10, 1, "b"); err != nil {
+		t.Fatalf("the subject of the next round is refused: %v", err)
+	}
+	if err := g.Check(KindBlockVote, 10, 0, "b"); err != nil {
+		t.Fatalf("the subject of the other kind is refused: %v", err)
+	}
+}
+
+func TestGuardRestart(t *testing.T) {
+	g, dir := newTestGuard(t)
+	defer os.RemoveAll(dir)
+
+	if err := g.Check(KindRoundVote, 7, 2, "formulator"); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := NewGuard(g.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.Check(KindRoundVote, 7, 2, "other"); err == nil {
+		t.Fatal("the second subject is signed after the restart")
+	}
+	if err := loaded.Check(KindRoundVote, 7, 2, "formulator"); err != nil {
+		t.Fatalf("the same subject is refused after the restart: %v", err)
+	}
+}
+
+func TestGuardWindow(t *testing.T) {
+	g, dir := newTestGuard(t)
+	defer os.RemoveAll(dir)
+
+	if err := g.Check(KindBlock, 0, 0, "a"); err == nil {
+		t.Fatal("the height 0 is signed")
+	}
+	if err := g.Check(KindBlock, 1, 0, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Check(KindBlock, GuardWindow+10, 0, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Check(KindBlock, 1, 0, "b"); err == nil {
+		t.Fatal("the height out of the window is signed")
+	}
+	if len(g.signed) != 1 {
+		t.Fatalf("%d heights are kept, expected 1", len(g.signed))
+	}
+}
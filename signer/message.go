@@ -0,0 +1,73 @@
+package signer
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/fletaio/common"
+	"github.com/fletaio/common/hash"
+	"github.com/fletaio/core/block"
+	"github.com/fletaio/core/observer"
+)
+
+// encodeMessage returns the hex string of the serialized message
+func encodeMessage(m io.WriterTo) (string, error) {
+	var buffer bytes.Buffer
+	if _, err := m.WriteTo(&buffer); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buffer.Bytes()), nil
+}
+
+// decodeMessage reads the message from the hex string and refuses the trailing bytes
+func decodeMessage(Data string, m io.ReaderFrom) error {
+	bs, err := hex.DecodeString(Data)
+	if err != nil {
+		return fmt.Errorf("%v: %v", ErrInvalidMessage, err)
+	}
+	r := bytes.NewReader(bs)
+	if _, err := m.ReadFrom(r); err != nil {
+		return fmt.Errorf("%v: %v", ErrInvalidMessage, err)
+	}
+	if r.Len() > 0 {
+		return fmt.Errorf("%v: %d trailing bytes", ErrInvalidMessage, r.Len())
+	}
+	return nil
+}
+
+func newRoundVote() *observer.RoundVote {
+	return &observer.RoundVote{
+		ChainCoord: &common.Coordinate{},
+	}
+}
+
+func newBlockVote() *observer.BlockVote {
+	return &observer.BlockVote{
+		Header: &block.Header{},
+	}
+}
+
+// blockVoteHash returns the hash that is signed as the observer signature of the block vote
+func blockVoteHash(vt *observer.BlockVote) hash.Hash256 {
+	s := &block.Signed{
+		HeaderHash:         vt.Header.Hash(),
+		GeneratorSignature: vt.GeneratorSignature,
+	}
+	return s.Hash()
+}
+
+// roundVoteSubject returns the hash of the round vote without the reply flag, so the vote and the reply of it have the same subject
+func roundVoteSubject(vt *observer.RoundVote) hash.Hash256 {
+	c := *vt
+	c.IsReply = false
+	return c.Hash()
+}
+
+// roundVoteAckSubject returns the hash of the round vote ack without the reply flag
+func roundVoteAckSubject(vt *observer.RoundVoteAck) hash.Hash256 {
+	c := *vt
+	c.IsReply = false
+	return c.Hash()
+}
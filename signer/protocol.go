@@ -0,0 +1,51 @@
+package signer
+
+// sign request kinds
+const (
+	// KindHash is the request of signing the hash without the context
+	KindHash = "hash"
+	// KindBlock is the request of signing the block header that is protected from the double signing of the height and the round
+	KindBlock = "block"
+	// KindRoundVote is the request of signing the round vote for the formulator of the height and the round
+	KindRoundVote = "round_vote"
+	// KindRoundVoteAck is the request of signing the round vote ack for the formulator of the height and the round
+	KindRoundVoteAck = "round_vote_ack"
+	// KindBlockVote is the request of signing the observer signature of the block vote for the block header of the height and the round
+	KindBlockVote = "block_vote"
+	// KindBlockVoteMessage is the request of signing the block vote message that has the observer signature of the signer
+	KindBlockVoteMessage = "block_vote_message"
+	// KindHandshake is the request of signing the handshake challenge of the peer
+	KindHandshake = "handshake"
+)
+
+// paths of the signer api
+const (
+	PublicKeyPath = "/v1/public_key"
+	SignPath      = "/v1/sign"
+)
+
+// PublicKeyResponse is the response of the public key request
+type PublicKeyResponse struct {
+	PublicKey  string `json:"publicKey"`
+	PublicHash string `json:"publicHash"`
+}
+
+// SignRequest is the request of signing the hash or the message
+// The data is the hex string of the serialized block header or vote, and the signer makes the hash, the height and the round from it
+// The hash of the handshake is made by the signer from the challenge
+type SignRequest struct {
+	Kind      string `json:"kind"`
+	Hash      string `json:"hash,omitempty"`
+	Data      string `json:"data,omitempty"`
+	Challenge string `json:"challenge,omitempty"`
+}
+
+// SignResponse is the response of the sign request
+type SignResponse struct {
+	Signature string `json:"signature"`
+}
+
+// ErrorResponse is the response of the failed request
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
@@ -0,0 +1,232 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fletaio/common"
+	"github.com/fletaio/common/hash"
+	"github.com/fletaio/core/block"
+	"github.com/fletaio/core/observer"
+)
+
+// DefaultTimeout is the timeout of a request to the signer
+const DefaultTimeout = 5 * time.Second
+
+// RemoteKey is the key.Key and the Signer that forwards the sign requests to the signer
+// It never holds the private key, so the serialization is not supported
+type RemoteKey struct {
+	baseURL    string
+	client     *http.Client
+	pubkey     common.PublicKey
+	PublicHash common.PublicHash
+}
+
+// NewRemoteKey returns a RemoteKey of the endpoint and loads the public key from the signer
+// The endpoint is unix:///path/of/socket or http://host:port
+func NewRemoteKey(Endpoint string, Timeout time.Duration) (*RemoteKey, error) {
+	if Timeout <= 0 {
+		Timeout = DefaultTimeout
+	}
+	if err := CheckEndpoint(Endpoint); err != nil {
+		return nil, err
+	}
+	rk := &RemoteKey{}
+	if strings.HasPrefix(Endpoint, "unix://") {
+		SocketPath := strings.TrimPrefix(Endpoint, "unix://")
+		rk.baseURL = "http://signer"
+		rk.client = &http.Client{
+			Timeout: Timeout,
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var d net.Dialer
+					return d.DialContext(ctx, "unix", SocketPath)
+				},
+			},
+		}
+	} else {
+		rk.baseURL = strings.TrimRight(Endpoint, "/")
+		rk.client = &http.Client{Timeout: Timeout}
+	}
+
+	var res PublicKeyResponse
+	if err := rk.call(http.MethodGet, PublicKeyPath, nil, &res); err != nil {
+		return nil, err
+	}
+	pubkey, err := common.ParsePublicKey(res.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	rk.pubkey = pubkey
+	rk.PublicHash = common.NewPublicHash(pubkey)
+	return rk, nil
+}
+
+// CheckEndpoint checks that the endpoint is a unix socket or a http address of the local host
+// The sign requests are not encrypted, so the signer should not be reached over the network
+func CheckEndpoint(Endpoint string) error {
+	switch {
+	case strings.HasPrefix(Endpoint, "unix://"):
+		if len(strings.TrimPrefix(Endpoint, "unix://")) == 0 {
+			return fmt.Errorf("%v %q", ErrInvalidEndpoint, Endpoint)
+		}
+		return nil
+	case strings.HasPrefix(Endpoint, "http://"):
+		return CheckLocalAddress(strings.TrimRight(strings.TrimPrefix(Endpoint, "http://"), "/"))
+	default:
+		return fmt.Errorf("%v %q (unix:///path or http://127.0.0.1:port)", ErrInvalidEndpoint, Endpoint)
+	}
+}
+
+// CheckLocalAddress checks that the host:port address is the loopback address
+func CheckLocalAddress(addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("%v %q: %v", ErrInvalidEndpoint, addr, err)
+	}
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+		return fmt.Errorf("%v %q: only the loopback address is allowed", ErrInvalidEndpoint, addr)
+	}
+	return nil
+}
+
+// PublicKey returns the public key of the signer
+func (rk *RemoteKey) PublicKey() common.PublicKey {
+	return rk.pubkey
+}
+
+// Sign requests the signature of the hash to the signer
+func (rk *RemoteKey) Sign(h hash.Hash256) (common.Signature, error) {
+	return rk.sign(&SignRequest{
+		Kind: KindHash,
+		Hash: h.String(),
+	}, h)
+}
+
+// SignBlock requests the signature of the block header to the signer
+// The signer refuses to sign a different block header of the same height and round
+func (rk *RemoteKey) SignBlock(Header *block.Header) (common.Signature, error) {
+	return rk.signMessage(KindBlock, Header, Header.Hash())
+}
+
+// SignRoundVote requests the signature of the round vote to the signer
+// The signer refuses to sign a different vote of the same height and round except the reply of the vote
+func (rk *RemoteKey) SignRoundVote(vt *observer.RoundVote) (common.Signature, error) {
+	return rk.signMessage(KindRoundVote, vt, vt.Hash())
+}
+
+// SignRoundVoteAck requests the signature of the round vote ack to the signer
+// The signer refuses to sign a different ack of the same height and round except the reply of the ack
+func (rk *RemoteKey) SignRoundVoteAck(vt *observer.RoundVoteAck) (common.Signature, error) {
+	return rk.signMessage(KindRoundVoteAck, vt, vt.Hash())
+}
+
+// SignBlockVote requests the observer signature of the block vote to the signer
+// The signer refuses to vote a different block header of the same height and round
+func (rk *RemoteKey) SignBlockVote(vt *observer.BlockVote) (common.Signature, error) {
+	return rk.signMessage(KindBlockVote, vt, blockVoteHash(vt))
+}
+
+// SignBlockVoteMessage requests the signature of the block vote message that has the observer signature of the signer
+func (rk *RemoteKey) SignBlockVoteMessage(vt *observer.BlockVote) (common.Signature, error) {
+	return rk.signMessage(KindBlockVoteMessage, vt, vt.Hash())
+}
+
+// SignHandshake requests the signature of the handshake challenge to the signer
+func (rk *RemoteKey) SignHandshake(Challenge []byte) (common.Signature, error) {
+	return rk.sign(&SignRequest{
+		Kind:      KindHandshake,
+		Challenge: hex.EncodeToString(Challenge),
+	}, hash.Hash(Challenge))
+}
+
+// SignWithPassphrase is not supported because the passphrase is managed by the signer
+func (rk *RemoteKey) SignWithPassphrase(h hash.Hash256, passphrase []byte) (common.Signature, error) {
+	return common.Signature{}, ErrNotSupported
+}
+
+// Verify checks that the signatures is generated by the hash and the key or not
+// The public key is recovered from the signature because the signature has the recovery id
+func (rk *RemoteKey) Verify(h hash.Hash256, sig common.Signature) bool {
+	pubkey, err := common.RecoverPubkey(h, sig)
+	if err != nil {
+		return false
+	}
+	return pubkey.Equal(rk.pubkey)
+}
+
+// WriteTo is not supported because the private key is not exported from the signer
+func (rk *RemoteKey) WriteTo(w io.Writer) (int64, error) {
+	return 0, ErrNotSupported
+}
+
+// ReadFrom is not supported because the private key is not imported to the remote key
+func (rk *RemoteKey) ReadFrom(r io.Reader) (int64, error) {
+	return 0, ErrNotSupported
+}
+
+func (rk *RemoteKey) signMessage(Kind string, m io.WriterTo, h hash.Hash256) (common.Signature, error) {
+	Data, err := encodeMessage(m)
+	if err != nil {
+		return common.Signature{}, err
+	}
+	return rk.sign(&SignRequest{
+		Kind: Kind,
+		Data: Data,
+	}, h)
+}
+
+func (rk *RemoteKey) sign(req *SignRequest, h hash.Hash256) (common.Signature, error) {
+	var res SignResponse
+	if err := rk.call(http.MethodPost, SignPath, req, &res); err != nil {
+		return common.Signature{}, err
+	}
+	sig, err := common.ParseSignature(res.Signature)
+	if err != nil {
+		return common.Signature{}, err
+	}
+	if !rk.Verify(h, sig) {
+		return common.Signature{}, ErrInvalidSignature
+	}
+	return sig, nil
+}
+
+func (rk *RemoteKey) call(method string, path string, req interface{}, res interface{}) error {
+	var body io.Reader
+	if req != nil {
+		bs, err := json.Marshal(req)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(bs)
+	}
+	hreq, err := http.NewRequest(method, rk.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	hreq.Header.Set("Content-Type", "application/json")
+	hres, err := rk.client.Do(hreq)
+	if err != nil {
+		return err
+	}
+	defer hres.Body.Close()
+	if hres.StatusCode != http.StatusOK {
+		var eres ErrorResponse
+		if err := json.NewDecoder(hres.Body).Decode(&eres); err != nil || len(eres.Error) == 0 {
+			return fmt.Errorf("%v: %s", ErrRejectedBySigner, hres.Status)
+		}
+		return fmt.Errorf("%v: %s", ErrRejectedBySigner, eres.Error)
+	}
+	return json.NewDecoder(hres.Body).Decode(res)
+}
@@ -0,0 +1,217 @@
+package signer
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/fletaio/common"
+	"github.com/fletaio/common/hash"
+	"github.com/fletaio/core/block"
+	"github.com/fletaio/core/key"
+	"github.com/fletaio/core/observer"
+)
+
+// maxRequestBodySize is the maximum size of the body of a sign request
+const maxRequestBodySize = 4096
+
+// Server serves the sign requests of the remote keys
+type Server struct {
+	key       *key.MemoryKey
+	guard     *Guard
+	audit     *AuditLog
+	allowHash bool
+	server    *http.Server
+}
+
+// NewServer returns a Server of the key
+// The hash requests without the context are refused when allowHash is false
+func NewServer(k *key.MemoryKey, guard *Guard, audit *AuditLog, allowHash bool) *Server {
+	s := &Server{
+		key:       k,
+		guard:     guard,
+		audit:     audit,
+		allowHash: allowHash,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(PublicKeyPath, s.handlePublicKey)
+	mux.HandleFunc(SignPath, s.handleSign)
+	s.server = &http.Server{Handler: mux}
+	return s
+}
+
+// Run listens the address and serves the requests
+// The address is unix:///path/of/socket or host:port
+func (s *Server) Run(Listen string) error {
+	var ln net.Listener
+	if strings.HasPrefix(Listen, "unix://") {
+		SocketPath := strings.TrimPrefix(Listen, "unix://")
+		if err := os.Remove(SocketPath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		l, err := net.Listen("unix", SocketPath)
+		if err != nil {
+			return err
+		}
+		if err := os.Chmod(SocketPath, 0600); err != nil {
+			l.Close()
+			return err
+		}
+		ln = l
+	} else {
+		l, err := net.Listen("tcp", Listen)
+		if err != nil {
+			return err
+		}
+		ln = l
+	}
+	return s.server.Serve(ln)
+}
+
+// Close stops the server and closes the audit log
+func (s *Server) Close() {
+	s.server.Close()
+	s.audit.Close()
+}
+
+func (s *Server) handlePublicKey(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, ErrNotSupported)
+		return
+	}
+	pubkey := s.key.PublicKey()
+	writeJSON(w, http.StatusOK, &PublicKeyResponse{
+		PublicKey:  pubkey.String(),
+		PublicHash: common.NewPublicHash(pubkey).String(),
+	})
+}
+
+func (s *Server) handleSign(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, ErrNotSupported)
+		return
+	}
+	var req SignRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBodySize)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, ErrInvalidRequestBody)
+		return
+	}
+	rec := &AuditRecord{
+		Remote: r.RemoteAddr,
+		Kind:   req.Kind,
+		Hash:   req.Hash,
+	}
+	// the hash, the height and the round are made from the request by sign, so the record is filled by it
+	sig, status, err := s.sign(&req, rec)
+	if err != nil {
+		rec.Result = AuditRejected
+		rec.Error = err.Error()
+		if aerr := s.audit.Write(rec); aerr != nil {
+			writeError(w, http.StatusInternalServerError, aerr)
+			return
+		}
+		writeError(w, status, err)
+		return
+	}
+	rec.Result = AuditSigned
+	// the signature is not returned when the audit log cannot be written
+	if err := s.audit.Write(rec); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, &SignResponse{
+		Signature: sig.String(),
+	})
+}
+
+func (s *Server) sign(req *SignRequest, rec *AuditRecord) (common.Signature, int, error) {
+	var h hash.Hash256
+	GuardKind := req.Kind
+	switch req.Kind {
+	case KindHash:
+		if !s.allowHash {
+			return common.Signature{}, http.StatusForbidden, fmt.Errorf("%v: %s is not allowed", ErrInvalidKind, KindHash)
+		}
+		ph, err := hash.ParseHash(req.Hash)
+		if err != nil {
+			return common.Signature{}, http.StatusBadRequest, err
+		}
+		h = ph
+	case KindHandshake:
+		Challenge, err := hex.DecodeString(req.Challenge)
+		if err != nil {
+			return common.Signature{}, http.StatusBadRequest, fmt.Errorf("%v: %v", ErrInvalidChallenge, err)
+		}
+		if err := CheckChallenge(Challenge); err != nil {
+			return common.Signature{}, http.StatusBadRequest, err
+		}
+		h = hash.Hash(Challenge)
+	case KindBlock:
+		bh := &block.Header{}
+		if err := decodeMessage(req.Data, bh); err != nil {
+			return common.Signature{}, http.StatusBadRequest, err
+		}
+		h = bh.Hash()
+		rec.Height, rec.Round, rec.Subject = bh.Height(), bh.TimeoutCount, h.String()
+	case KindRoundVote:
+		vt := newRoundVote()
+		if err := decodeMessage(req.Data, vt); err != nil {
+			return common.Signature{}, http.StatusBadRequest, err
+		}
+		h = vt.Hash()
+		rec.Height, rec.Round, rec.Subject = vt.VoteTargetHeight, vt.TimeoutCount, roundVoteSubject(vt).String()
+	case KindRoundVoteAck:
+		vt := &observer.RoundVoteAck{}
+		if err := decodeMessage(req.Data, vt); err != nil {
+			return common.Signature{}, http.StatusBadRequest, err
+		}
+		h = vt.Hash()
+		rec.Height, rec.Round, rec.Subject = vt.VoteTargetHeight, vt.TimeoutCount, roundVoteAckSubject(vt).String()
+	case KindBlockVote, KindBlockVoteMessage:
+		vt := newBlockVote()
+		if err := decodeMessage(req.Data, vt); err != nil {
+			return common.Signature{}, http.StatusBadRequest, err
+		}
+		bh := vt.Header.(*block.Header)
+		VoteHash := blockVoteHash(vt)
+		rec.Height, rec.Round, rec.Subject = bh.Height(), bh.TimeoutCount, VoteHash.String()
+		if req.Kind == KindBlockVote {
+			h = VoteHash
+		} else {
+			// the message is signed only when it has the vote of the signer, and the vote is guarded by the kind of the vote
+			pubkey, err := common.RecoverPubkey(VoteHash, vt.ObserverSignature)
+			if err != nil || !pubkey.Equal(s.key.PublicKey()) {
+				return common.Signature{}, http.StatusBadRequest, fmt.Errorf("%v: the observer signature is not signed by the signer", ErrInvalidSignature)
+			}
+			h = vt.Hash()
+			GuardKind = KindBlockVote
+		}
+	default:
+		return common.Signature{}, http.StatusBadRequest, fmt.Errorf("%v %q", ErrInvalidKind, req.Kind)
+	}
+	rec.Hash = h.String()
+	if len(rec.Subject) > 0 {
+		if err := s.guard.Check(GuardKind, rec.Height, rec.Round, rec.Subject); err != nil {
+			return common.Signature{}, http.StatusConflict, err
+		}
+	}
+	sig, err := s.key.Sign(h)
+	if err != nil {
+		return common.Signature{}, http.StatusInternalServerError, err
+	}
+	return sig, http.StatusOK, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, &ErrorResponse{Error: err.Error()})
+}
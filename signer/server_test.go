@@ -0,0 +1,209 @@
+package signer
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fletaio/common"
+	"github.com/fletaio/common/hash"
+	"github.com/fletaio/core/block"
+	"github.com/fletaio/core/key"
+	"github.com/fletaio/core/observer"
+	fchain "github.com/fletaio/framework/chain"
+)
+
+func newTestRemoteKey(t *testing.T, allowHash bool) (*RemoteKey, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "signer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	k, err := key.NewMemoryKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	guard, err := NewGuard(filepath.Join(dir, "guard.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	audit, err := NewAuditLog(filepath.Join(dir, "audit.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(k, guard, audit, allowHash)
+	hs := httptest.NewServer(s.server.Handler)
+	rk, err := NewRemoteKey(hs.URL, time.Second)
+	if err != nil {
+		hs.Close()
+		t.Fatal(err)
+	}
+	return rk, func() {
+		hs.Close()
+		audit.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func newTestHeader(Height uint32, TimeoutCount uint32, ContextHash hash.Hash256) *block.Header {
+	return &block.Header{
+		Base: fchain.Base{
+			Version_:   1,
+			Height_:    Height,
+			Timestamp_: uint64(Height),
+		},
+		ContextHash:  ContextHash,
+		TimeoutCount: TimeoutCount,
+	}
+}
+
+func TestServerSignBlock(t *testing.T) {
+	rk, closeFn := newTestRemoteKey(t, false)
+	defer closeFn()
+
+	h1 := newTestHeader(100, 0, hash.Hash([]byte("block1")))
+	h2 := newTestHeader(100, 0, hash.Hash([]byte("block2")))
+	sig, err := rk.SignBlock(h1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pubkey, err := common.RecoverPubkey(h1.Hash(), sig); err != nil || !pubkey.Equal(rk.PublicKey()) {
+		t.Fatal("the block is not signed by the hash of the header")
+	}
+	if _, err := rk.SignBlock(h1); err != nil {
+		t.Fatalf("the same block is refused: %v", err)
+	}
+	if _, err := rk.SignBlock(h2); err == nil || !strings.Contains(err.Error(), ErrDoubleSign.Error()) {
+		t.Fatalf("the second block of the same height is not refused: %v", err)
+	}
+	if _, err := rk.SignBlock(newTestHeader(100, 1, hash.Hash([]byte("block2")))); err != nil {
+		t.Fatalf("the block of the next round is refused: %v", err)
+	}
+
+	// the hash of a different message is not signed with the height and the round of the request
+	if _, err := rk.sign(&SignRequest{Kind: KindBlock, Hash: h2.Hash().String()}, h2.Hash()); err == nil {
+		t.Fatal("the block is signed without the header")
+	}
+	Data, err := encodeMessage(h1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rk.sign(&SignRequest{Kind: KindBlock, Data: Data + "00"}, h1.Hash()); err == nil {
+		t.Fatal("the header of the trailing bytes is signed")
+	}
+}
+
+func TestServerSignVote(t *testing.T) {
+	rk, closeFn := newTestRemoteKey(t, false)
+	defer closeFn()
+
+	acg := &common.Coordinate{Height: 1, Index: 2}
+	f1 := common.NewAddress(acg, 0)
+	f2 := common.NewAddress(acg, 1)
+	vote := &observer.RoundVote{
+		ChainCoord:       acg,
+		VoteTargetHeight: 100,
+		Formulator:       f1,
+		Timestamp:        1,
+	}
+	if _, err := rk.SignRoundVote(vote); err != nil {
+		t.Fatal(err)
+	}
+	reply := *vote
+	reply.IsReply = true
+	if _, err := rk.SignRoundVote(&reply); err != nil {
+		t.Fatalf("the reply of the vote is refused: %v", err)
+	}
+	other := *vote
+	other.Formulator = f2
+	if _, err := rk.SignRoundVote(&other); err == nil {
+		t.Fatal("the vote of the second formulator of the same height is signed")
+	}
+	other = *vote
+	other.LastHash = hash.Hash([]byte("last"))
+	if _, err := rk.SignRoundVote(&other); err == nil {
+		t.Fatal("the vote of the different last hash of the same height is signed")
+	}
+	ack := &observer.RoundVoteAck{
+		VoteTargetHeight: 100,
+		Formulator:       f2,
+	}
+	if _, err := rk.SignRoundVoteAck(ack); err != nil {
+		t.Fatalf("the ack is refused by the vote: %v", err)
+	}
+
+	vt := &observer.BlockVote{
+		VoteTargetHeight: 100,
+		Header:           newTestHeader(100, 0, hash.Hash([]byte("header1"))),
+	}
+	sig, err := rk.SignBlockVote(vt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rk.SignBlockVoteMessage(vt); err == nil {
+		t.Fatal("the message without the vote of the signer is signed")
+	}
+	vt.ObserverSignature = sig
+	if _, err := rk.SignBlockVoteMessage(vt); err != nil {
+		t.Fatalf("the message of the vote is refused: %v", err)
+	}
+	vt.IsReply = true
+	vt.VoteTargetHeight = 0
+	if _, err := rk.SignBlockVoteMessage(vt); err != nil {
+		t.Fatalf("the reply of the vote is refused: %v", err)
+	}
+	if _, err := rk.SignBlockVote(&observer.BlockVote{
+		VoteTargetHeight: 100,
+		Header:           newTestHeader(100, 0, hash.Hash([]byte("header2"))),
+	}); err == nil {
+		t.Fatal("the vote of the second header of the same height is signed")
+	}
+}
+
+func TestServerSignHandshake(t *testing.T) {
+	rk, closeFn := newTestRemoteKey(t, false)
+	defer closeFn()
+
+	Challenge := make([]byte, 40)
+	binary.LittleEndian.PutUint64(Challenge[32:], uint64(time.Now().UnixNano()))
+	sig, err := rk.SignHandshake(Challenge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubkey, err := common.RecoverPubkey(hash.Hash(Challenge), sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pubkey.Equal(rk.PublicKey()) {
+		t.Fatal("the handshake is not signed by the key")
+	}
+
+	binary.LittleEndian.PutUint64(Challenge[32:], uint64(time.Now().Add(-time.Minute).UnixNano()))
+	if _, err := rk.SignHandshake(Challenge); err == nil {
+		t.Fatal("the old challenge is signed")
+	}
+	if _, err := rk.SignHandshake(make([]byte, 32)); err == nil {
+		t.Fatal("the challenge of the invalid length is signed")
+	}
+}
+
+func TestServerSignHash(t *testing.T) {
+	rk, closeFn := newTestRemoteKey(t, false)
+	defer closeFn()
+
+	if _, err := rk.Sign(hash.Hash([]byte("hash"))); err == nil {
+		t.Fatal("the hash is signed without AllowHashSign")
+	}
+
+	rkHash, closeHash := newTestRemoteKey(t, true)
+	defer closeHash()
+
+	if _, err := rkHash.Sign(hash.Hash([]byte("hash"))); err != nil {
+		t.Fatalf("the hash is refused with AllowHashSign: %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package signer
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/fletaio/common"
+	"github.com/fletaio/common/hash"
+	"github.com/fletaio/core/block"
+	"github.com/fletaio/core/key"
+	"github.com/fletaio/core/observer"
+)
+
+// HandshakeTimeout is the maximum difference between the timestamp of the handshake challenge and the current time
+const HandshakeTimeout = 30 * time.Second
+
+// Signer signs the consensus messages of the formulator and the observer
+// The messages are given instead of the hashes, so the remote signer makes the hash, the height and the round from them and refuses the double signing
+type Signer interface {
+	PublicKey() common.PublicKey
+	SignBlock(Header *block.Header) (common.Signature, error)
+	SignRoundVote(vt *observer.RoundVote) (common.Signature, error)
+	SignRoundVoteAck(vt *observer.RoundVoteAck) (common.Signature, error)
+	SignBlockVote(vt *observer.BlockVote) (common.Signature, error)
+	SignBlockVoteMessage(vt *observer.BlockVote) (common.Signature, error)
+	SignHandshake(Challenge []byte) (common.Signature, error)
+}
+
+// LocalSigner is the Signer of the key that is held by the daemon
+type LocalSigner struct {
+	key key.Key
+}
+
+// NewLocalSigner returns a LocalSigner of the key
+func NewLocalSigner(k key.Key) *LocalSigner {
+	return &LocalSigner{
+		key: k,
+	}
+}
+
+// PublicKey returns the public key of the key
+func (ls *LocalSigner) PublicKey() common.PublicKey {
+	return ls.key.PublicKey()
+}
+
+// SignBlock signs the hash of the block header
+func (ls *LocalSigner) SignBlock(Header *block.Header) (common.Signature, error) {
+	return ls.key.Sign(Header.Hash())
+}
+
+// SignRoundVote signs the hash of the round vote
+func (ls *LocalSigner) SignRoundVote(vt *observer.RoundVote) (common.Signature, error) {
+	return ls.key.Sign(vt.Hash())
+}
+
+// SignRoundVoteAck signs the hash of the round vote ack
+func (ls *LocalSigner) SignRoundVoteAck(vt *observer.RoundVoteAck) (common.Signature, error) {
+	return ls.key.Sign(vt.Hash())
+}
+
+// SignBlockVote signs the hash of the header hash and the generator signature of the block vote
+func (ls *LocalSigner) SignBlockVote(vt *observer.BlockVote) (common.Signature, error) {
+	return ls.key.Sign(blockVoteHash(vt))
+}
+
+// SignBlockVoteMessage signs the hash of the block vote message
+func (ls *LocalSigner) SignBlockVoteMessage(vt *observer.BlockVote) (common.Signature, error) {
+	return ls.key.Sign(vt.Hash())
+}
+
+// SignHandshake signs the hash of the handshake challenge
+func (ls *LocalSigner) SignHandshake(Challenge []byte) (common.Signature, error) {
+	if err := CheckChallenge(Challenge); err != nil {
+		return common.Signature{}, err
+	}
+	return ls.key.Sign(hash.Hash(Challenge))
+}
+
+// CheckChallenge checks that the handshake challenge is the random bytes followed by the optional formulator address and the recent timestamp
+func CheckChallenge(Challenge []byte) error {
+	if len(Challenge) != 40 && len(Challenge) != 60 {
+		return fmt.Errorf("%v: invalid length %d", ErrInvalidChallenge, len(Challenge))
+	}
+	timestamp := binary.LittleEndian.Uint64(Challenge[len(Challenge)-8:])
+	diff := time.Duration(uint64(time.Now().UnixNano()) - timestamp)
+	if diff < 0 {
+		diff = -diff
+	}
+	if diff > HandshakeTimeout {
+		return fmt.Errorf("%v: timestamp is out of %v", ErrInvalidChallenge, HandshakeTimeout)
+	}
+	return nil
+}
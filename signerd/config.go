@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/fletaio/cmd/command"
+	"github.com/fletaio/cmd/signer"
+)
+
+// Config is a configuration for the cmd
+type Config struct {
+	KeyHex            string
+	KeyFile           string `config:"path"`
+	KeyPassphraseFile string `config:"path"`
+	Listen            string `config:"socket"`
+	GuardFile         string `config:"path"`
+	AuditLog          string `config:"path"`
+	AllowHashSign     bool
+	command.LogConfig
+}
+
+// Validate checks every field of the config and reports all problems together
+func (cfg *Config) Validate() error {
+	var es command.ConfigErrors
+	es.CheckKey(cfg.KeyHex, cfg.KeyFile)
+	if strings.HasPrefix(cfg.Listen, "unix://") {
+		if len(strings.TrimPrefix(cfg.Listen, "unix://")) == 0 {
+			es.Addf("Listen", "socket path is not given")
+		}
+	} else if err := signer.CheckLocalAddress(cfg.Listen); err != nil {
+		es.Add("Listen", err)
+	}
+	if len(cfg.GuardFile) == 0 {
+		es.Addf("GuardFile", "guard file is not given")
+	}
+	if len(cfg.AuditLog) == 0 {
+		es.Addf("AuditLog", "audit log is not given")
+	}
+	es.CheckLogging(cfg.LoggingConfig())
+	return es.Err()
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/fletaio/cmd/command"
+	"github.com/fletaio/cmd/logging"
+	"github.com/fletaio/cmd/signer"
+	"github.com/fletaio/common"
+	"github.com/fletaio/framework/closer"
+)
+
+func main() {
+	cfg := Config{
+		Listen:    "unix://./signer.sock",
+		GuardFile: "./signer_guard.json",
+		AuditLog:  "./signer_audit.log",
+	}
+	if command.IsCommand(os.Args[1:]) {
+		if err := command.Run(os.Args[1:], &cfg); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+	if err := command.LoadConfig(os.Args[1:], &cfg); err != nil {
+		if err == flag.ErrHelp {
+			return
+		}
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if err := logging.Setup(cfg.LoggingConfig()); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	k, err := command.LoadKey(cfg.KeyHex, cfg.KeyFile, cfg.KeyPassphraseFile)
+	if err != nil {
+		logging.Get(logging.Main).Fatal("failed to load the key", "error", err)
+	}
+	guard, err := signer.NewGuard(cfg.GuardFile)
+	if err != nil {
+		logging.Get(logging.Main).Fatal("failed to load the guard", "file", cfg.GuardFile, "error", err)
+	}
+	audit, err := signer.NewAuditLog(cfg.AuditLog)
+	if err != nil {
+		logging.Get(logging.Main).Fatal("failed to open the audit log", "file", cfg.AuditLog, "error", err)
+	}
+	s := signer.NewServer(k, guard, audit, cfg.AllowHashSign)
+
+	cm := closer.NewManager()
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc,
+		syscall.SIGHUP,
+		syscall.SIGINT,
+		syscall.SIGTERM,
+		syscall.SIGQUIT)
+	go func() {
+		<-sigc
+		cm.CloseAll()
+	}()
+	defer cm.CloseAll()
+	cm.Add("signer.Server", s)
+
+	logging.Get(logging.Main).Info("signer listens", "pubhash", common.NewPublicHash(k.PublicKey()).String(), "listen", cfg.Listen, "allow_hash_sign", cfg.AllowHashSign)
+	go func() {
+		if err := s.Run(cfg.Listen); err != nil {
+			if http.ErrServerClosed != err {
+				cm.CloseAll()
+				logging.Get(logging.Main).Fatal("failed to serve the signer", "listen", cfg.Listen, "error", err)
+			}
+		}
+	}()
+
+	cm.Wait()
+}
@@ -0,0 +1,141 @@
+package tlsutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// file names of the ca in the ca directory
+const (
+	CAName     = "ca"
+	CACertFile = CAName + ".crt"
+	CAKeyFile  = CAName + ".key"
+)
+
+// GenerateCA returns the self-signed ca certificate and the private key of it in pem
+func GenerateCA(Name string, Days int) ([]byte, []byte, error) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	tmpl, err := newTemplate(Name, Days)
+	if err != nil {
+		return nil, nil, err
+	}
+	tmpl.IsCA = true
+	tmpl.BasicConstraintsValid = true
+	tmpl.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &priv.PublicKey, priv)
+	if err != nil {
+		return nil, nil, err
+	}
+	return encodePair(der, priv)
+}
+
+// IssueCert returns the certificate of the hosts signed by the ca and the private key of it in pem
+// The certificate is used for both the server and the client to be used by the mutual tls
+func IssueCert(CACert []byte, CAKey []byte, Name string, Hosts []string, Days int) ([]byte, []byte, error) {
+	ca, caPriv, err := parseCA(CACert, CAKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	tmpl, err := newTemplate(Name, Days)
+	if err != nil {
+		return nil, nil, err
+	}
+	tmpl.KeyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment
+	tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+	for _, h := range Hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+		} else {
+			tmpl.DNSNames = append(tmpl.DNSNames, h)
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca, &priv.PublicKey, caPriv)
+	if err != nil {
+		return nil, nil, err
+	}
+	return encodePair(der, priv)
+}
+
+// WritePair writes the certificate and the private key to Name.crt and Name.key of the directory
+// The private key is written as readable only by the owner
+func WritePair(Dir string, Name string, Cert []byte, Key []byte) (string, string, error) {
+	if err := os.MkdirAll(Dir, 0700); err != nil {
+		return "", "", err
+	}
+	CertPath := filepath.Join(Dir, Name+".crt")
+	KeyPath := filepath.Join(Dir, Name+".key")
+	if err := ioutil.WriteFile(CertPath, Cert, 0644); err != nil {
+		return "", "", err
+	}
+	if err := ioutil.WriteFile(KeyPath, Key, 0600); err != nil {
+		return "", "", err
+	}
+	return CertPath, KeyPath, nil
+}
+
+func newTemplate(Name string, Days int) (*x509.Certificate, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return &x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			Organization: []string{"FLETA"},
+			CommonName:   Name,
+		},
+		NotBefore: now.Add(-time.Hour),
+		NotAfter:  now.AddDate(0, 0, Days),
+	}, nil
+}
+
+func parseCA(CACert []byte, CAKey []byte) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	cb, _ := pem.Decode(CACert)
+	if cb == nil {
+		return nil, nil, ErrInvalidCA
+	}
+	ca, err := x509.ParseCertificate(cb.Bytes)
+	if err != nil {
+		return nil, nil, ErrInvalidCA
+	}
+	if !ca.IsCA {
+		return nil, nil, ErrNotCA
+	}
+	kb, _ := pem.Decode(CAKey)
+	if kb == nil {
+		return nil, nil, ErrInvalidPrivateKey
+	}
+	priv, err := x509.ParseECPrivateKey(kb.Bytes)
+	if err != nil {
+		return nil, nil, ErrInvalidPrivateKey
+	}
+	return ca, priv, nil
+}
+
+func encodePair(der []byte, priv *ecdsa.PrivateKey) ([]byte, []byte, error) {
+	kb, err := x509.MarshalECPrivateKey(priv)
+	if err != nil {
+		return nil, nil, err
+	}
+	Cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	Key := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: kb})
+	return Cert, Key, nil
+}
@@ -0,0 +1,13 @@
+package tlsutil
+
+import (
+	"errors"
+)
+
+// tlsutil errors
+var (
+	ErrInvalidCertificate = errors.New("invalid certificate")
+	ErrInvalidPrivateKey  = errors.New("invalid private key")
+	ErrInvalidCA          = errors.New("invalid ca certificate")
+	ErrNotCA              = errors.New("not ca certificate")
+)
@@ -0,0 +1,133 @@
+package tlsutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+// reloadInterval is the minimum interval to check the change of the files
+const reloadInterval = time.Second
+
+// KeyPair is the certificate and the private key that are reloaded when the files are changed
+// The previous pair is kept when the changed files cannot be loaded
+type KeyPair struct {
+	sync.Mutex
+	certFile  string
+	keyFile   string
+	cert      *tls.Certificate
+	modTime   time.Time
+	lastCheck time.Time
+}
+
+// LoadKeyPair returns the KeyPair of the files
+func LoadKeyPair(CertFile string, KeyFile string) (*KeyPair, error) {
+	kp := &KeyPair{
+		certFile: CertFile,
+		keyFile:  KeyFile,
+	}
+	modTime, err := kp.lastModified()
+	if err != nil {
+		return nil, err
+	}
+	cert, err := tls.LoadX509KeyPair(CertFile, KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	kp.cert = &cert
+	kp.modTime = modTime
+	kp.lastCheck = time.Now()
+	return kp, nil
+}
+
+// Certificate returns the current certificate
+func (kp *KeyPair) Certificate() *tls.Certificate {
+	kp.Lock()
+	defer kp.Unlock()
+
+	now := time.Now()
+	if now.Sub(kp.lastCheck) < reloadInterval {
+		return kp.cert
+	}
+	kp.lastCheck = now
+	modTime, err := kp.lastModified()
+	if err != nil || !modTime.After(kp.modTime) {
+		return kp.cert
+	}
+	cert, err := tls.LoadX509KeyPair(kp.certFile, kp.keyFile)
+	if err != nil {
+		return kp.cert
+	}
+	kp.cert = &cert
+	kp.modTime = modTime
+	return kp.cert
+}
+
+// GetCertificate is used by tls.Config of the server
+func (kp *KeyPair) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return kp.Certificate(), nil
+}
+
+// GetClientCertificate is used by tls.Config of the client
+func (kp *KeyPair) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return kp.Certificate(), nil
+}
+
+// lastModified returns the last modified time of the files
+func (kp *KeyPair) lastModified() (time.Time, error) {
+	var modTime time.Time
+	for _, path := range []string{kp.certFile, kp.keyFile} {
+		fi, err := os.Stat(path)
+		if err != nil {
+			return modTime, err
+		}
+		if fi.ModTime().After(modTime) {
+			modTime = fi.ModTime()
+		}
+	}
+	return modTime, nil
+}
+
+// LoadCAPool returns the pool of the ca certificates of the pem file
+func LoadCAPool(CAFile string) (*x509.CertPool, error) {
+	bs, err := ioutil.ReadFile(CAFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(bs) {
+		return nil, ErrInvalidCA
+	}
+	return pool, nil
+}
+
+// ServerConfig returns the config of the server that does not verify the client
+func ServerConfig(kp *KeyPair) *tls.Config {
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: kp.GetCertificate,
+	}
+}
+
+// PeerServerConfig returns the config of the server that requires the client certificate signed by the ca
+func PeerServerConfig(kp *KeyPair, pool *x509.CertPool) *tls.Config {
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: kp.GetCertificate,
+		ClientAuth:     tls.RequireAndVerifyClientCert,
+		ClientCAs:      pool,
+	}
+}
+
+// PeerClientConfig returns the config of the client that gives the certificate and verifies the server by the ca
+func PeerClientConfig(kp *KeyPair, pool *x509.CertPool, ServerName string) *tls.Config {
+	return &tls.Config{
+		MinVersion:           tls.VersionTLS12,
+		GetClientCertificate: kp.GetClientCertificate,
+		RootCAs:              pool,
+		ServerName:           ServerName,
+	}
+}
@@ -0,0 +1,44 @@
+package tlsutil
+
+import (
+	"crypto/x509"
+	"net"
+)
+
+// Peer is the mutual tls of the p2p links
+// Both sides give the certificate signed by the ca and verify the certificate of the other side
+type Peer struct {
+	kp   *KeyPair
+	pool *x509.CertPool
+}
+
+// LoadPeer returns the Peer of the files
+func LoadPeer(CertFile string, KeyFile string, CAFile string) (*Peer, error) {
+	kp, err := LoadKeyPair(CertFile, KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := LoadCAPool(CAFile)
+	if err != nil {
+		return nil, err
+	}
+	return &Peer{
+		kp:   kp,
+		pool: pool,
+	}, nil
+}
+
+// Serve listens the mutual tls of the bind address and forwards the connections to the plaintext target
+func (p *Peer) Serve(Bind string, Target string) (*Tunnel, error) {
+	return ServeTunnel(Bind, Target, PeerServerConfig(p.kp, p.pool))
+}
+
+// Open returns the tunnel of a loopback address to the remote
+// The host of the remote should be in the certificate of the remote
+func (p *Peer) Open(Remote string) (*Tunnel, error) {
+	host, _, err := net.SplitHostPort(Remote)
+	if err != nil {
+		return nil, err
+	}
+	return OpenTunnel(Remote, PeerClientConfig(p.kp, p.pool, host))
+}
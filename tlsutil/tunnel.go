@@ -0,0 +1,159 @@
+package tlsutil
+
+import (
+	"crypto/tls"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/fletaio/cmd/logging"
+)
+
+var tunnelLog = logging.Get(logging.Tunnel)
+
+// dialTimeout is the timeout to connect the other side of the tunnel
+const dialTimeout = 10 * time.Second
+
+// Tunnel forwards the connections of the listener to the address
+// It is used to add tls to the plaintext listeners and dialers of the p2p links that cannot be changed
+type Tunnel struct {
+	sync.Mutex
+	lstn    net.Listener
+	dial    func() (net.Conn, error)
+	conns   map[net.Conn]bool
+	isClose bool
+}
+
+// Tunnels are the tunnels of the daemon that are closed together
+type Tunnels []*Tunnel
+
+// Close stops every tunnel
+func (ts Tunnels) Close() {
+	for _, tn := range ts {
+		tn.Close()
+	}
+}
+
+// ServeTunnel listens the tls connections of the bind address and forwards them to the plaintext target
+func ServeTunnel(Bind string, Target string, cfg *tls.Config) (*Tunnel, error) {
+	lstn, err := tls.Listen("tcp", Bind, cfg)
+	if err != nil {
+		return nil, err
+	}
+	tn := &Tunnel{
+		lstn: lstn,
+		dial: func() (net.Conn, error) {
+			return net.DialTimeout("tcp", Target, dialTimeout)
+		},
+		conns: map[net.Conn]bool{},
+	}
+	go tn.run()
+	return tn, nil
+}
+
+// OpenTunnel listens the plaintext connections of a loopback address and forwards them to the tls remote
+// The dialer connects the loopback address instead of the remote
+func OpenTunnel(Remote string, cfg *tls.Config) (*Tunnel, error) {
+	lstn, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	tn := &Tunnel{
+		lstn: lstn,
+		dial: func() (net.Conn, error) {
+			return tls.DialWithDialer(&net.Dialer{Timeout: dialTimeout}, "tcp", Remote, cfg)
+		},
+		conns: map[net.Conn]bool{},
+	}
+	go tn.run()
+	return tn, nil
+}
+
+// Addr returns the listening address of the tunnel
+func (tn *Tunnel) Addr() string {
+	return tn.lstn.Addr().String()
+}
+
+// Close stops the tunnel and closes the forwarding connections
+func (tn *Tunnel) Close() {
+	tn.Lock()
+	defer tn.Unlock()
+
+	if tn.isClose {
+		return
+	}
+	tn.isClose = true
+	tn.lstn.Close()
+	for conn := range tn.conns {
+		conn.Close()
+	}
+}
+
+func (tn *Tunnel) run() {
+	for {
+		conn, err := tn.lstn.Accept()
+		if err != nil {
+			tn.Lock()
+			isClose := tn.isClose
+			tn.Unlock()
+			if !isClose {
+				tunnelLog.Error("failed to accept", "addr", tn.Addr(), "error", err)
+			}
+			return
+		}
+		go tn.forward(conn)
+	}
+}
+
+func (tn *Tunnel) forward(conn net.Conn) {
+	defer conn.Close()
+
+	// the handshake is done before dialing to close the connection of the invalid certificate
+	if tc, is := conn.(*tls.Conn); is {
+		if err := tc.Handshake(); err != nil {
+			tunnelLog.Warn("failed to handshake", "remote", conn.RemoteAddr(), "error", err)
+			return
+		}
+	}
+	peer, err := tn.dial()
+	if err != nil {
+		tunnelLog.Warn("failed to dial", "error", err)
+		return
+	}
+	defer peer.Close()
+
+	tn.Lock()
+	if tn.isClose {
+		tn.Unlock()
+		return
+	}
+	tn.conns[conn] = true
+	tn.Unlock()
+	defer func() {
+		tn.Lock()
+		delete(tn.conns, conn)
+		tn.Unlock()
+	}()
+
+	done := make(chan struct{}, 2)
+	go func() {
+		io.Copy(peer, conn)
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(conn, peer)
+		done <- struct{}{}
+	}()
+	<-done
+}
+
+// LoopbackAddr returns a free address of the loopback
+func LoopbackAddr() (string, error) {
+	lstn, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	defer lstn.Close()
+	return lstn.Addr().String(), nil
+}